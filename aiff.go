@@ -11,44 +11,63 @@ var (
 	COMTID = [4]byte{'C', 'O', 'M', 'T'}
 	SSNDID = [4]byte{'S', 'S', 'N', 'D'}
 
+	// text chunks
+	nameID      = [4]byte{'N', 'A', 'M', 'E'}
+	authID      = [4]byte{'A', 'U', 'T', 'H'}
+	copyrightID = [4]byte{'(', 'c', ')', ' '}
+	annoID      = [4]byte{'A', 'N', 'N', 'O'}
+	id3ID       = [4]byte{'I', 'D', '3', ' '}
+
 	// Apple stuff
 	chanID = [4]byte{'C', 'H', 'A', 'N'}
 	bascID = [4]byte{'b', 'a', 's', 'c'}
 	trnsID = [4]byte{'t', 'r', 'n', 's'}
 	cateID = [4]byte{'c', 'a', 't', 'e'}
 
-	// AIFC encodings
-	encNotSet = [4]byte{}
-	encNone   = [4]byte{'N', 'O', 'N', 'E'}
+	// AIFC encodings, see Encoding.
+	EncNotSet = Encoding{}
+	EncNone   = Encoding{'N', 'O', 'N', 'E'}
 	// inverted byte order LE instead of BE (not really compression)
-	encSowt = [4]byte{'s', 'o', 'w', 't'}
+	EncSowt = Encoding{'s', 'o', 'w', 't'}
 	// inverted byte order LE instead of BE (not really compression)
-	encTwos = [4]byte{'t', 'w', 'o', 's'}
-	encRaw  = [4]byte{'r', 'a', 'w', ' '}
-	encIn24 = [4]byte{'i', 'n', '2', '4'}
-	enc42n1 = [4]byte{'4', '2', 'n', '1'}
-	encIn32 = [4]byte{'i', 'n', '3', '2'}
-	enc23ni = [4]byte{'2', '3', 'n', 'i'}
+	EncTwos = Encoding{'t', 'w', 'o', 's'}
+	EncRaw  = Encoding{'r', 'a', 'w', ' '}
+	EncIn24 = Encoding{'i', 'n', '2', '4'}
+	Enc42n1 = Encoding{'4', '2', 'n', '1'}
+	EncIn32 = Encoding{'i', 'n', '3', '2'}
+	Enc23ni = Encoding{'2', '3', 'n', 'i'}
 
-	encFl32 = [4]byte{'f', 'l', '3', '2'}
-	encFL32 = [4]byte{'F', 'L', '3', '2'}
-	encFl64 = [4]byte{'f', 'l', '6', '4'}
-	encFL64 = [4]byte{'F', 'L', '6', '4'}
+	EncFl32 = Encoding{'f', 'l', '3', '2'}
+	EncFL32 = Encoding{'F', 'L', '3', '2'}
+	EncFl64 = Encoding{'f', 'l', '6', '4'}
+	EncFL64 = Encoding{'F', 'L', '6', '4'}
 
-	envUlaw = [4]byte{'u', 'l', 'a', 'w'}
-	encULAW = [4]byte{'U', 'L', 'A', 'W'}
-	encAlaw = [4]byte{'a', 'l', 'a', 'w'}
-	encALAW = [4]byte{'A', 'L', 'A', 'W'}
+	EncUlaw = Encoding{'u', 'l', 'a', 'w'}
+	EncULAW = Encoding{'U', 'L', 'A', 'W'}
+	EncAlaw = Encoding{'a', 'l', 'a', 'w'}
+	EncALAW = Encoding{'A', 'L', 'A', 'W'}
 
-	encDwvw = [4]byte{'D', 'W', 'V', 'W'}
-	encGsm  = [4]byte{'G', 'S', 'M', ' '}
-	encIma4 = [4]byte{'i', 'm', 'a', '4'}
+	EncDwvw = Encoding{'D', 'W', 'V', 'W'}
+	EncGsm  = Encoding{'G', 'S', 'M', ' '}
+	EncIma4 = Encoding{'i', 'm', 'a', '4'}
 
-	// encAble
-	encAble = [4]byte{'a', 'b', 'l', 'e'}
+	// EncAble is a non standard encoding written by some applications.
+	EncAble = Encoding{'a', 'b', 'l', 'e'}
 
 	// ErrFmtNotSupported is a generic error reporting an unknown format.
 	ErrFmtNotSupported = errors.New("format not supported")
+	// ErrTooLargeForAIFF is returned by the encoder when the data to write
+	// doesn't fit the 32-bit size fields of the format (4GB).
+	ErrTooLargeForAIFF = errors.New("too large for an AIFF file")
+	// ErrTooManySamples is returned by FullPCMBuffer when the file holds more
+	// samples than the allowed limit.
+	ErrTooManySamples = errors.New("too many samples to load in memory")
+	// ErrFrameMismatch is returned when closing an encoder if the written
+	// buffers didn't hold whole frames or didn't share the same format.
+	ErrFrameMismatch = errors.New("written samples don't match the frame format")
+	// ErrChecksumMismatch is returned when the sound data doesn't match the
+	// checksum stored in the file.
+	ErrChecksumMismatch = errors.New("sound data doesn't match the checksum")
 	// ErrUnexpectedData is a generic error reporting that the parser encountered unexpected data.
 	ErrUnexpectedData = errors.New("unexpected data content")
 
@@ -0,0 +1,310 @@
+// Package aifftest provides helpers to test code handling AIFF files without
+// committing binary fixtures: files are generated from a format and a
+// deterministic pattern, and their structure can be dumped as text to be
+// compared against golden files.
+package aifftest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-audio/aiff"
+	"github.com/go-audio/audio"
+)
+
+// Update makes Golden write the golden files instead of comparing them.
+// It's set when the AIFFTEST_UPDATE environment variable isn't empty and
+// can also be set from a flag of the test binary:
+//
+//	var update = flag.Bool("update", false, "update the golden files")
+//
+//	func TestMain(m *testing.M) {
+//		flag.Parse()
+//		aifftest.Update = *update
+//		os.Exit(m.Run())
+//	}
+var Update = os.Getenv("AIFFTEST_UPDATE") != ""
+
+// Format describes a file to generate or the format expected by AssertFormat.
+type Format struct {
+	SampleRate int
+	BitDepth   int
+	NumChans   int
+	NumFrames  int
+	// Encoding is the AIFC encoding, an AIFF file is generated if not set.
+	Encoding aiff.Encoding
+}
+
+func (f Format) String() string {
+	s := fmt.Sprintf("%d Hz, %d bit, %d channel(s), %d frames", f.SampleRate, f.BitDepth, f.NumChans, f.NumFrames)
+	if f.Encoding != aiff.EncNotSet {
+		s += fmt.Sprintf(", %q", f.Encoding)
+	}
+	return s
+}
+
+// Pattern is the content of the sound data of a generated file. All the
+// patterns are deterministic, the same file is generated on every run.
+type Pattern int
+
+const (
+	// Silence is digital silence.
+	Silence Pattern = iota
+	// Ramp is a sawtooth going through the 256 values of the most
+	// significant byte, each channel being offset by 16 steps. It makes
+	// byte order and channel interleaving issues easy to spot.
+	Ramp
+	// Sine is a 440 Hz sine wave at -6 dBFS on all the channels.
+	Sine
+	// Noise is white noise from a fixed seed, different on each channel.
+	Noise
+)
+
+func (p Pattern) String() string {
+	switch p {
+	case Silence:
+		return "silence"
+	case Ramp:
+		return "ramp"
+	case Sine:
+		return "sine"
+	case Noise:
+		return "noise"
+	}
+	return fmt.Sprintf("pattern(%d)", int(p))
+}
+
+// Samples returns the interleaved samples of the pattern for the format.
+func (p Pattern) Samples(format Format) ([]int, error) {
+	if format.NumChans < 1 || format.SampleRate < 1 || format.NumFrames < 0 {
+		return nil, fmt.Errorf("invalid format: %s", format)
+	}
+	switch format.BitDepth {
+	case 8, 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("%v bit depth not supported", format.BitDepth)
+	}
+	shift := uint(format.BitDepth - 8)
+	max := float64(int(1)<<uint(format.BitDepth-1) - 1)
+	seed := uint32(2463534242)
+
+	data := make([]int, format.NumFrames*format.NumChans)
+	for i := 0; i < format.NumFrames; i++ {
+		for j := 0; j < format.NumChans; j++ {
+			var v int
+			switch p {
+			case Silence:
+			case Ramp:
+				v = ((i+j*16)%256 - 128) << shift
+			case Sine:
+				v = int(math.Round(math.Sin(2*math.Pi*440*float64(i)/float64(format.SampleRate)) * max / 2))
+			case Noise:
+				// xorshift32
+				seed ^= seed << 13
+				seed ^= seed >> 17
+				seed ^= seed << 5
+				v = int(int32(seed)) >> uint(32-format.BitDepth)
+			default:
+				return nil, fmt.Errorf("unknown pattern: %s", p)
+			}
+			data[i*format.NumChans+j] = v
+		}
+	}
+	return data, nil
+}
+
+// Generate returns the content of an AIFF (or AIFC if an encoding is set)
+// file using the passed format and pattern.
+func Generate(format Format, pattern Pattern) ([]byte, error) {
+	data, err := pattern.Samples(format)
+	if err != nil {
+		return nil, err
+	}
+	w := &memFile{}
+	e := aiff.NewEncoder(w, format.SampleRate, format.BitDepth, format.NumChans)
+	e.Encoding = format.Encoding
+	buf := &audio.IntBuffer{
+		Format:         &audio.Format{NumChannels: format.NumChans, SampleRate: format.SampleRate},
+		SourceBitDepth: format.BitDepth,
+		Data:           data,
+	}
+	if err := e.Write(buf); err != nil {
+		return nil, err
+	}
+	if err := e.Close(); err != nil {
+		return nil, err
+	}
+	return w.buf, nil
+}
+
+// Fixture generates a file like Generate and returns a reader over its
+// content. The test fails if the file can't be generated.
+func Fixture(t testing.TB, format Format, pattern Pattern) *bytes.Reader {
+	t.Helper()
+	b, err := Generate(format, pattern)
+	if err != nil {
+		t.Fatalf("failed to generate a %s fixture (%s) - %v", pattern, format, err)
+	}
+	return bytes.NewReader(b)
+}
+
+// ReadFormat reads the format of the passed file.
+func ReadFormat(r io.ReadSeeker) (Format, error) {
+	d := aiff.NewDecoder(r)
+	d.ReadInfo()
+	if err := d.Err(); err != nil {
+		return Format{}, err
+	}
+	return Format{
+		SampleRate: d.SampleRate,
+		BitDepth:   int(d.BitDepth),
+		NumChans:   int(d.NumChans),
+		NumFrames:  int(d.NumSampleFrames),
+		Encoding:   d.Encoding,
+	}, nil
+}
+
+// AssertFormat fails the test if the format of the passed file doesn't match
+// the expected one. The reader is rewound.
+func AssertFormat(t testing.TB, r io.ReadSeeker, want Format) {
+	t.Helper()
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadFormat(r)
+	if err != nil {
+		t.Fatalf("failed to read the format - %v", err)
+	}
+	// AIFF and AIFC files without compression are equivalent
+	if want.Encoding == aiff.EncNone && got.Encoding == aiff.EncNotSet {
+		got.Encoding = aiff.EncNone
+	}
+	if got != want {
+		t.Errorf("expected %s but got %s", want, got)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// DumpChunks returns the chunk tree of the passed file as text, one chunk
+// per line with its offset and size. The format of the COMM chunk is
+// included. The output is stable and meant to be stored in golden files:
+//
+//	FORM AIFF @0 size=1024
+//	  COMM @12 size=18 channels=1 frames=500 bit_depth=16 sample_rate=22050
+//	  SSND @38 size=1008
+func DumpChunks(r io.ReadSeeker) (string, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	format, err := ReadFormat(r)
+	if err != nil {
+		return "", err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return "", fmt.Errorf("failed to read the FORM header - %v", err)
+	}
+	if string(header[:4]) != "FORM" {
+		return "", errors.New("FORM header not found")
+	}
+	out := &strings.Builder{}
+	fmt.Fprintf(out, "FORM %s @0 size=%d\n", header[8:12], binary.BigEndian.Uint32(header[4:8]))
+
+	offset := int64(len(header))
+	var chunkHeader [8]byte
+	for {
+		if _, err := io.ReadFull(r, chunkHeader[:]); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return out.String(), fmt.Errorf("failed to read the chunk header at %d - %v", offset, err)
+		}
+		id := chunkHeader[:4]
+		size := int64(binary.BigEndian.Uint32(chunkHeader[4:]))
+		fmt.Fprintf(out, "  %s @%d size=%d", id, offset, size)
+		if string(id) == "COMM" {
+			fmt.Fprintf(out, " channels=%d frames=%d bit_depth=%d sample_rate=%d", format.NumChans, format.NumFrames, format.BitDepth, format.SampleRate)
+			if format.Encoding != aiff.EncNotSet {
+				fmt.Fprintf(out, " encoding=%q", format.Encoding)
+			}
+		}
+		out.WriteString("\n")
+		offset += 8 + size + size%2
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return out.String(), err
+		}
+	}
+	return out.String(), nil
+}
+
+// Golden compares got to the content of the golden file at path and fails
+// the test if they differ. The golden file is written instead when Update is
+// set.
+func Golden(t testing.TB, path string, got []byte) {
+	t.Helper()
+	if Update {
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("failed to update the golden file - %v", err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read the golden file - %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s doesn't match, got:\n%s\nexpected:\n%s", path, got, want)
+	}
+}
+
+// memFile is an in memory io.WriteSeeker.
+type memFile struct {
+	buf []byte
+	pos int
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if end := f.pos + len(p); end > len(f.buf) {
+		f.buf = append(f.buf, make([]byte, end-len(f.buf))...)
+	}
+	n := copy(f.buf[f.pos:], p)
+	f.pos += n
+	return n, nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = int64(f.pos) + offset
+	case io.SeekEnd:
+		pos = int64(len(f.buf)) + offset
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if pos < 0 {
+		return 0, errors.New("negative position")
+	}
+	f.pos = int(pos)
+	return pos, nil
+}
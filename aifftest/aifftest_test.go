@@ -0,0 +1,76 @@
+package aifftest
+
+import (
+	"testing"
+
+	"github.com/go-audio/aiff"
+)
+
+func TestGenerate(t *testing.T) {
+	testCases := []struct {
+		format  Format
+		pattern Pattern
+	}{
+		{Format{SampleRate: 22050, BitDepth: 16, NumChans: 1, NumFrames: 500}, Silence},
+		{Format{SampleRate: 44100, BitDepth: 24, NumChans: 2, NumFrames: 1000}, Ramp},
+		{Format{SampleRate: 48000, BitDepth: 32, NumChans: 2, NumFrames: 1000}, Sine},
+		{Format{SampleRate: 48000, BitDepth: 16, NumChans: 2, NumFrames: 1000, Encoding: aiff.EncSowt}, Noise},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.format.String(), func(t *testing.T) {
+			r := Fixture(t, tc.format, tc.pattern)
+			AssertFormat(t, r, tc.format)
+
+			expected, err := tc.pattern.Samples(tc.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf, err := aiff.NewDecoder(r).FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(buf.Data) != len(expected) {
+				t.Fatalf("expected %d samples but got %d", len(expected), len(buf.Data))
+			}
+			for i, v := range expected {
+				if buf.Data[i] != v {
+					t.Fatalf("sample %d: expected %d, got %d", i, v, buf.Data[i])
+				}
+			}
+
+			// the same file is generated every time
+			r2 := Fixture(t, tc.format, tc.pattern)
+			if _, err := r.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			if ok, diffs := aiff.Equal(r, r2); !ok {
+				t.Fatalf("expected the generation to be deterministic but got %v", diffs)
+			}
+		})
+	}
+}
+
+func TestGenerate_invalid(t *testing.T) {
+	if _, err := Generate(Format{SampleRate: 44100, BitDepth: 12, NumChans: 1}, Sine); err == nil {
+		t.Fatal("expected an error with an unsupported bit depth")
+	}
+	if _, err := Generate(Format{SampleRate: 44100, BitDepth: 16}, Sine); err == nil {
+		t.Fatal("expected an error without channels")
+	}
+}
+
+func TestDumpChunks(t *testing.T) {
+	r := Fixture(t, Format{SampleRate: 22050, BitDepth: 16, NumChans: 1, NumFrames: 500}, Ramp)
+	out, err := DumpChunks(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Golden(t, "testdata/ramp_mono16.golden", []byte(out))
+
+	r = Fixture(t, Format{SampleRate: 44100, BitDepth: 24, NumChans: 2, NumFrames: 11, Encoding: aiff.EncSowt}, Sine)
+	out, err = DumpChunks(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Golden(t, "testdata/sine_stereo24_sowt.golden", []byte(out))
+}
@@ -1,5 +1,17 @@
 package aiff
 
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bascSize is the size of the basc chunk written by Apple applications, the
+// fields are followed by reserved bytes.
+const bascSize = 82
+
 // AppleMetadata is a list of custom fields sometimes set by Apple specific
 // progams such as Logic.
 type AppleMetadata struct {
@@ -33,34 +45,118 @@ func AppleScaleToString(scale uint16) string {
 	}
 }
 
-// AppleNoteToPitch returns the pitch for the stored note.
+// pitchClasses are the names of the 12 notes of an octave, using sharps.
+var pitchClasses = [12]string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+
+// maxMIDINote is the highest note of the MIDI range.
+const maxMIDINote = 127
+
+// AppleNoteToPitch returns the pitch class (without octave) of the stored
+// note, such as "C" for 48 or "F#" for 66. An empty string is returned for
+// notes outside of the MIDI range. See AppleNoteToName to get the octave.
 func AppleNoteToPitch(note uint16) string {
-	switch note {
-	case 48:
-		return "C"
-	case 49:
-		return "C#"
-	case 50:
-		return "D"
-	case 51:
-		return "D#"
-	case 52:
-		return "E"
-	case 53:
-		return "F"
-	case 54:
-		return "F#"
-	case 55:
-		return "G"
-	case 56:
-		return "G#"
-	case 57:
-		return "A"
-	case 58:
-		return "A#"
-	case 59:
-		return "B"
-	default:
+	if note > maxMIDINote {
+		return ""
+	}
+	return pitchClasses[note%12]
+}
+
+// AppleNoteToName returns the name of the stored note with its octave
+// number, using the convention of Apple applications where the middle C
+// (60) is C3: 0 is "C-2", 48 is "C2" and 127 is "G8". An empty string is
+// returned for notes outside of the MIDI range.
+func AppleNoteToName(note uint16) string {
+	if note > maxMIDINote {
 		return ""
 	}
+	return fmt.Sprintf("%s%d", pitchClasses[note%12], int(note)/12-2)
+}
+
+// PitchToAppleNote returns the note matching the passed name, the reverse
+// of AppleNoteToName. Sharps (#) and flats (b) are supported and the octave
+// is optional: pitch classes such as "D#" map to the 48 to 59 range used by
+// Apple Loops for the root key.
+func PitchToAppleNote(name string) (uint16, error) {
+	s := strings.TrimSpace(name)
+	if s == "" {
+		return 0, fmt.Errorf("invalid note name %q", name)
+	}
+	class := strings.IndexByte("C D EF G A B", strings.ToUpper(s[:1])[0])
+	if class < 0 {
+		return 0, fmt.Errorf("invalid note name %q", name)
+	}
+	s = s[1:]
+	for len(s) > 0 && (s[0] == '#' || s[0] == 'b') {
+		if s[0] == '#' {
+			class++
+		} else {
+			class--
+		}
+		s = s[1:]
+	}
+	octave := 2
+	if s != "" {
+		var err error
+		if octave, err = strconv.Atoi(s); err != nil {
+			return 0, fmt.Errorf("invalid octave in note name %q", name)
+		}
+	}
+	note := (octave+2)*12 + class
+	if note < 0 || note > maxMIDINote {
+		return 0, fmt.Errorf("note %q out of the MIDI range", name)
+	}
+	return uint16(note), nil
+}
+
+// TransposeAppleNote shifts the note by the passed number of semitones
+// (negative to transpose down). An error is returned if the result is out
+// of the MIDI range.
+func TransposeAppleNote(note uint16, semitones int) (uint16, error) {
+	transposed := int(note) + semitones
+	if note > maxMIDINote || transposed < 0 || transposed > maxMIDINote {
+		return 0, fmt.Errorf("can't transpose note %d by %d semitones, out of the MIDI range", note, semitones)
+	}
+	return uint16(transposed), nil
+}
+
+// BeatsFromDuration returns the number of beats, rounded to the nearest
+// integer, played in the passed duration at the passed tempo (in BPM).
+func BeatsFromDuration(dur time.Duration, bpm float64) uint32 {
+	if dur <= 0 || !(bpm > 0) {
+		return 0
+	}
+	return uint32(math.Round(dur.Minutes() * bpm))
+}
+
+// writeAppleInfo writes the basc chunk describing the loop. When a tempo is
+// set, the number of beats is updated when the encoder is closed.
+func (e *Encoder) writeAppleInfo() error {
+	info := AppleMetadata{Numerator: 4, Denominator: 4}
+	if e.AppleInfo != nil {
+		info = *e.AppleInfo
+	}
+	if err := e.AddBE(bascID); err != nil {
+		return fmt.Errorf("%v when writing basc chunk ID header", err)
+	}
+	if err := e.AddBE(uint32(bascSize)); err != nil {
+		return fmt.Errorf("%v when writing basc chunk size header", err)
+	}
+	// version
+	if err := e.AddBE(uint32(1)); err != nil {
+		return fmt.Errorf("%v when writing basc version", err)
+	}
+	e.beatsPos = e.WrittenBytes
+	loopFlag := uint16(2)
+	if info.IsLooping {
+		loopFlag = 1
+	}
+	for _, v := range []interface{}{info.Beats, info.Note, info.Scale, info.Numerator, info.Denominator, loopFlag} {
+		if err := e.AddBE(v); err != nil {
+			return fmt.Errorf("%v when writing basc data", err)
+		}
+	}
+	if err := e.AddBE(make([]byte, bascSize-18)); err != nil {
+		return fmt.Errorf("%v when writing basc reserved bytes", err)
+	}
+	return nil
 }
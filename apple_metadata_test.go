@@ -0,0 +1,72 @@
+package aiff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBeatsFromDuration(t *testing.T) {
+	testCases := []struct {
+		dur   time.Duration
+		bpm   float64
+		beats uint32
+	}{
+		{2 * time.Second, 120, 4},
+		{8 * time.Second, 90, 12},
+		{1990 * time.Millisecond, 120, 4},
+		{time.Second, 0, 0},
+		{0, 120, 0},
+	}
+	for _, tc := range testCases {
+		if beats := BeatsFromDuration(tc.dur, tc.bpm); beats != tc.beats {
+			t.Errorf("expected %v @ %v BPM to be %d beats but got %d", tc.dur, tc.bpm, tc.beats, beats)
+		}
+	}
+}
+
+func TestAppleNotes(t *testing.T) {
+	testCases := []struct {
+		note  uint16
+		pitch string
+		name  string
+	}{
+		{0, "C", "C-2"},
+		{48, "C", "C2"},
+		{59, "B", "B2"},
+		{60, "C", "C3"},
+		{66, "F#", "F#3"},
+		{127, "G", "G8"},
+		{128, "", ""},
+	}
+	for _, tc := range testCases {
+		if pitch := AppleNoteToPitch(tc.note); pitch != tc.pitch {
+			t.Errorf("expected note %d to be %q but got %q", tc.note, tc.pitch, pitch)
+		}
+		if name := AppleNoteToName(tc.note); name != tc.name {
+			t.Errorf("expected note %d to be named %q but got %q", tc.note, tc.name, name)
+		}
+		if tc.name == "" {
+			continue
+		}
+		if note, err := PitchToAppleNote(tc.name); err != nil || note != tc.note {
+			t.Errorf("expected %q to be note %d but got %d, %v", tc.name, tc.note, note, err)
+		}
+	}
+	for name, expected := range map[string]uint16{"D#": 51, "Eb": 51, "eb3": 63, "Gb3": 66, "B#2": 60, "Cb3": 59} {
+		if note, err := PitchToAppleNote(name); err != nil || note != expected {
+			t.Errorf("expected %q to be note %d but got %d, %v", name, expected, note, err)
+		}
+	}
+	for _, name := range []string{"", "H2", "C#x", "G9", "C-3"} {
+		if _, err := PitchToAppleNote(name); err == nil {
+			t.Errorf("expected an error parsing %q", name)
+		}
+	}
+
+	if note, err := TransposeAppleNote(60, -12); err != nil || note != 48 {
+		t.Fatalf("expected 48 but got %d, %v", note, err)
+	}
+	if _, err := TransposeAppleNote(120, 8); err == nil {
+		t.Fatal("expected an error transposing out of the MIDI range")
+	}
+}
@@ -0,0 +1,145 @@
+package aiff
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-audio/audio"
+)
+
+// benchmarkDuration is the duration of the synthetic stereo files decoded by
+// the benchmarks.
+const benchmarkDuration = 30 * time.Second
+
+var (
+	benchmarkFilesMu sync.Mutex
+	benchmarkFiles   = map[int][]byte{}
+)
+
+// benchmarkFile returns the content of a synthetic 44.1kHz stereo file
+// holding a sine wave at the passed bit depth, the files are generated once.
+func benchmarkFile(b *testing.B, bitDepth int) []byte {
+	b.Helper()
+	benchmarkFilesMu.Lock()
+	defer benchmarkFilesMu.Unlock()
+	if data, ok := benchmarkFiles[bitDepth]; ok {
+		return data
+	}
+	path := filepath.Join(b.TempDir(), fmt.Sprintf("sine%d.aif", bitDepth))
+	f, err := os.Create(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	if err := WriteSine(f, 440, benchmarkDuration, &audio.Format{NumChannels: 2, SampleRate: 44100}, bitDepth); err != nil {
+		b.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkFiles[bitDepth] = data
+	return data
+}
+
+// The benchmarks are named Benchmark<Type>_<Method>/bits=<depth> so their
+// results can be compared with benchstat.
+
+func BenchmarkDecoder_ReadInfo(b *testing.B) {
+	data := benchmarkFile(b, 16)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d := NewDecoder(bytes.NewReader(data))
+		if err := d.ReadInfo(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecoder_PCMBuffer(b *testing.B) {
+	for _, bitDepth := range []int{8, 16, 24, 32} {
+		b.Run(fmt.Sprintf("bits=%d", bitDepth), func(b *testing.B) {
+			data := benchmarkFile(b, bitDepth)
+			buf := &audio.IntBuffer{Data: make([]int, 4096)}
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				d := NewDecoder(bytes.NewReader(data))
+				for {
+					n, err := d.PCMBuffer(buf)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if n == 0 {
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecoder_FullPCMBuffer(b *testing.B) {
+	for _, bitDepth := range []int{16, 24} {
+		b.Run(fmt.Sprintf("bits=%d", bitDepth), func(b *testing.B) {
+			data := benchmarkFile(b, bitDepth)
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := NewDecoder(bytes.NewReader(data)).FullPCMBuffer(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecoder_Drain(b *testing.B) {
+	data := benchmarkFile(b, 16)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := NewDecoder(bytes.NewReader(data)).Drain(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncoder_Write(b *testing.B) {
+	for _, bitDepth := range []int{16, 24} {
+		b.Run(fmt.Sprintf("bits=%d", bitDepth), func(b *testing.B) {
+			path := filepath.Join(b.TempDir(), "out.aif")
+			buf := &audio.IntBuffer{Data: make([]int, 44100*2*10), Format: &audio.Format{NumChannels: 2, SampleRate: 44100}}
+			for i := range buf.Data {
+				buf.Data[i] = i % 32768
+			}
+			b.SetBytes(int64(len(buf.Data) * bytesPerSample(bitDepth)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				f, err := os.Create(path)
+				if err != nil {
+					b.Fatal(err)
+				}
+				e := NewEncoder(f, 44100, bitDepth, 2)
+				if err := e.Write(buf); err != nil {
+					b.Fatal(err)
+				}
+				if err := e.Close(); err != nil {
+					b.Fatal(err)
+				}
+				f.Close()
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+var (
+	applID = [4]byte{'A', 'P', 'P', 'L'}
+	// application signature of the APPL chunks holding a BWF bext chunk
+	bextSignature = [4]byte{'b', 'e', 'x', 't'}
+)
+
+// bextSize is the size of the fixed part of a bext chunk.
+const bextSize = 602
+
+// BroadcastInfo holds the fields of the bext chunk of a Broadcast Wave file
+// (EBU Tech 3285). AIFF doesn't define an equivalent, the data is stored
+// untouched in an APPL chunk with the 'bext' signature so files can go back
+// and forth between AIFF and BWF without losing information.
+type BroadcastInfo struct {
+	Description         string
+	Originator          string
+	OriginatorReference string
+	// OriginationDate is formatted as yyyy-mm-dd
+	OriginationDate string
+	// OriginationTime is formatted as hh:mm:ss
+	OriginationTime string
+	// TimeReference is the position of the first sample in samples since
+	// midnight.
+	TimeReference uint64
+	Version       uint16
+	UMID          [64]byte
+	// loudness values (version 2) multiplied by 100
+	LoudnessValue        int16
+	LoudnessRange        int16
+	MaxTruePeakLevel     int16
+	MaxMomentaryLoudness int16
+	MaxShortTermLoudness int16
+	CodingHistory        string
+}
+
+// ParseBroadcastInfo parses the content of a bext chunk as found in a
+// Broadcast Wave file (without the chunk ID and size).
+func ParseBroadcastInfo(b []byte) (*BroadcastInfo, error) {
+	if len(b) < bextSize {
+		return nil, fmt.Errorf("bext chunk too short: %d bytes", len(b))
+	}
+	text := func(start, size int) string {
+		return nullTermStr(b[start : start+size])
+	}
+	info := &BroadcastInfo{
+		Description:          text(0, 256),
+		Originator:           text(256, 32),
+		OriginatorReference:  text(288, 32),
+		OriginationDate:      text(320, 10),
+		OriginationTime:      text(330, 8),
+		TimeReference:        binary.LittleEndian.Uint64(b[338:]),
+		Version:              binary.LittleEndian.Uint16(b[346:]),
+		LoudnessValue:        int16(binary.LittleEndian.Uint16(b[412:])),
+		LoudnessRange:        int16(binary.LittleEndian.Uint16(b[414:])),
+		MaxTruePeakLevel:     int16(binary.LittleEndian.Uint16(b[416:])),
+		MaxMomentaryLoudness: int16(binary.LittleEndian.Uint16(b[418:])),
+		MaxShortTermLoudness: int16(binary.LittleEndian.Uint16(b[420:])),
+		CodingHistory:        nullTermStr(b[bextSize:]),
+	}
+	copy(info.UMID[:], b[348:412])
+	return info, nil
+}
+
+// Bytes returns the content of the bext chunk describing the info, ready to
+// be written in a Broadcast Wave file. Text fields are truncated to the
+// size allowed by the spec.
+func (info *BroadcastInfo) Bytes() []byte {
+	b := make([]byte, bextSize, bextSize+len(info.CodingHistory))
+	copy(b[0:256], info.Description)
+	copy(b[256:288], info.Originator)
+	copy(b[288:320], info.OriginatorReference)
+	copy(b[320:330], info.OriginationDate)
+	copy(b[330:338], info.OriginationTime)
+	binary.LittleEndian.PutUint64(b[338:], info.TimeReference)
+	binary.LittleEndian.PutUint16(b[346:], info.Version)
+	copy(b[348:412], info.UMID[:])
+	binary.LittleEndian.PutUint16(b[412:], uint16(info.LoudnessValue))
+	binary.LittleEndian.PutUint16(b[414:], uint16(info.LoudnessRange))
+	binary.LittleEndian.PutUint16(b[416:], uint16(info.MaxTruePeakLevel))
+	binary.LittleEndian.PutUint16(b[418:], uint16(info.MaxMomentaryLoudness))
+	binary.LittleEndian.PutUint16(b[420:], uint16(info.MaxShortTermLoudness))
+	return append(b, info.CodingHistory...)
+}
+
+// parseApplChunk processes the application specific chunks.
+func (d *Decoder) parseApplChunk(chunk *Chunk) error {
+	b, err := ioutil.ReadAll(chunk)
+	if err != nil {
+		return err
+	}
+	if len(b) < 4 {
+		return errors.New("APPL chunk too short")
+	}
+	if !bytes.Equal(b[:4], bextSignature[:]) {
+		return nil
+	}
+	d.BroadcastInfo, err = ParseBroadcastInfo(b[4:])
+	return err
+}
+
+// writeBroadcastInfo writes the broadcast info in an APPL chunk.
+func (e *Encoder) writeBroadcastInfo() error {
+	data := e.BroadcastInfo.Bytes()
+	size := 4 + len(data)
+	if err := e.AddBE(applID); err != nil {
+		return fmt.Errorf("%v when writing APPL chunk ID header", err)
+	}
+	if err := e.AddBE(uint32(size)); err != nil {
+		return fmt.Errorf("%v when writing APPL chunk size header", err)
+	}
+	if err := e.AddBE(bextSignature); err != nil {
+		return fmt.Errorf("%v when writing APPL signature", err)
+	}
+	if size%2 != 0 {
+		data = append(data, 0)
+	}
+	if err := e.AddBE(data); err != nil {
+		return fmt.Errorf("%v when writing the bext data", err)
+	}
+	return nil
+}
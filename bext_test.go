@@ -0,0 +1,90 @@
+package aiff
+
+import (
+	"os"
+	"testing"
+)
+
+func TestBroadcastInfoRoundTrip(t *testing.T) {
+	info := &BroadcastInfo{
+		Description:          "Scene 12 take 3",
+		Originator:           "Recorder",
+		OriginatorReference:  "REF0001",
+		OriginationDate:      "2020-01-31",
+		OriginationTime:      "12:34:56",
+		TimeReference:        44100 * 3600 * 10,
+		Version:              2,
+		LoudnessValue:        -2300,
+		MaxTruePeakLevel:     -100,
+		MaxShortTermLoudness: -1800,
+		CodingHistory:        "A=PCM,F=44100,W=16,M=stereo,T=original\r\n",
+	}
+	info.UMID[0] = 0x06
+
+	parsed, err := ParseBroadcastInfo(info.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *parsed != *info {
+		t.Fatalf("expected %+v but got %+v", info, parsed)
+	}
+	if _, err := ParseBroadcastInfo(make([]byte, 10)); err == nil {
+		t.Fatal("expected an error parsing a truncated bext chunk")
+	}
+
+	in, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	buf, err := NewDecoder(in).FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/bext.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	e := NewEncoder(out, 22050, 16, 1)
+	e.BroadcastInfo = info
+	if err := e.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if issues := Lint(out); len(issues) > 0 {
+		t.Fatalf("expected the encoded file to be spec compliant but got %v", issues)
+	}
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(out)
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if d.BroadcastInfo == nil {
+		t.Fatal("expected the broadcast info to be decoded")
+	}
+	if *d.BroadcastInfo != *info {
+		t.Fatalf("expected %+v but got %+v", info, d.BroadcastInfo)
+	}
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	buf2, err := NewDecoder(out).FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf2.Data) != len(buf.Data) {
+		t.Fatalf("expected %d samples but got %d", len(buf.Data), len(buf2.Data))
+	}
+}
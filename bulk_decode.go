@@ -0,0 +1,114 @@
+package aiff
+
+import "encoding/binary"
+
+// bulkDecodeFunc returns a function decoding all the samples of src into dst
+// at once and returning the number of decoded samples, dst must be large
+// enough to hold them. These loops are a lot faster than the generic sample
+// decoding functions for the common uncompressed formats, including the
+// little endian (sowt) exports of Logic and other macOS applications.
+// nil is returned if there isn't any bulk decoder for the format.
+func bulkDecodeFunc(bitDepth int, byteOrder binary.ByteOrder) func(dst []int, src []byte) int {
+	littleEndian := byteOrder == binary.LittleEndian
+	switch bitDepth {
+	case 16:
+		if littleEndian {
+			return decode16LE
+		}
+		return decode16BE
+	case 24:
+		if littleEndian {
+			return decode24LE
+		}
+		return decode24BE
+	case 32:
+		if littleEndian {
+			return decode32LE
+		}
+		return decode32BE
+	}
+	return nil
+}
+
+// bulkDecodeFunc returns the bulk decoder of the sound data if any.
+func (d *Decoder) bulkDecodeFunc() func(dst []int, src []byte) int {
+	if d.byteOrder == nil {
+		return nil
+	}
+	return bulkDecodeFunc(int(d.BitDepth), d.byteOrder)
+}
+
+func decode16BE(dst []int, src []byte) int {
+	n := len(src) / 2
+	dst = dst[:n]
+	i := 0
+	// 4 samples at a time
+	for ; i+4 <= n; i += 4 {
+		s := src[i*2 : i*2+8]
+		dst[i] = int(int16(binary.BigEndian.Uint16(s[0:2])))
+		dst[i+1] = int(int16(binary.BigEndian.Uint16(s[2:4])))
+		dst[i+2] = int(int16(binary.BigEndian.Uint16(s[4:6])))
+		dst[i+3] = int(int16(binary.BigEndian.Uint16(s[6:8])))
+	}
+	for ; i < n; i++ {
+		dst[i] = int(int16(binary.BigEndian.Uint16(src[i*2:])))
+	}
+	return n
+}
+
+func decode16LE(dst []int, src []byte) int {
+	n := len(src) / 2
+	dst = dst[:n]
+	i := 0
+	// 4 samples at a time
+	for ; i+4 <= n; i += 4 {
+		s := src[i*2 : i*2+8]
+		dst[i] = int(int16(binary.LittleEndian.Uint16(s[0:2])))
+		dst[i+1] = int(int16(binary.LittleEndian.Uint16(s[2:4])))
+		dst[i+2] = int(int16(binary.LittleEndian.Uint16(s[4:6])))
+		dst[i+3] = int(int16(binary.LittleEndian.Uint16(s[6:8])))
+	}
+	for ; i < n; i++ {
+		dst[i] = int(int16(binary.LittleEndian.Uint16(src[i*2:])))
+	}
+	return n
+}
+
+func decode24BE(dst []int, src []byte) int {
+	n := len(src) / 3
+	dst = dst[:n]
+	for i := range dst {
+		s := src[i*3 : i*3+3]
+		// the sign is extended by the arithmetic shift
+		dst[i] = int(int32(uint32(s[0])<<24|uint32(s[1])<<16|uint32(s[2])<<8) >> 8)
+	}
+	return n
+}
+
+func decode24LE(dst []int, src []byte) int {
+	n := len(src) / 3
+	dst = dst[:n]
+	for i := range dst {
+		s := src[i*3 : i*3+3]
+		dst[i] = int(int32(uint32(s[2])<<24|uint32(s[1])<<16|uint32(s[0])<<8) >> 8)
+	}
+	return n
+}
+
+func decode32BE(dst []int, src []byte) int {
+	n := len(src) / 4
+	dst = dst[:n]
+	for i := range dst {
+		dst[i] = int(int32(binary.BigEndian.Uint32(src[i*4:])))
+	}
+	return n
+}
+
+func decode32LE(dst []int, src []byte) int {
+	n := len(src) / 4
+	dst = dst[:n]
+	for i := range dst {
+		dst[i] = int(int32(binary.LittleEndian.Uint32(src[i*4:])))
+	}
+	return n
+}
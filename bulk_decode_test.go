@@ -0,0 +1,50 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestBulkDecodeFunc(t *testing.T) {
+	// the number of samples isn't a multiple of 4 to cover the tail of the
+	// unrolled loops
+	src := make([]byte, 4*3*7)
+	rand.New(rand.NewSource(42)).Read(src)
+	for _, bitDepth := range []int{16, 24, 32} {
+		for _, byteOrder := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+			t.Run(fmt.Sprintf("%d-bit %v", bitDepth, byteOrder), func(t *testing.T) {
+				decodeF, err := sampleDecodeFunc(bitDepth, byteOrder)
+				if err != nil {
+					t.Fatal(err)
+				}
+				bPerSample := bytesPerSample(bitDepth)
+				expected := make([]int, len(src)/bPerSample)
+				r := bytes.NewReader(src)
+				for i := range expected {
+					if expected[i], err = decodeF(r, make([]byte, bPerSample)); err != nil {
+						t.Fatal(err)
+					}
+				}
+				bulkDecode := bulkDecodeFunc(bitDepth, byteOrder)
+				if bulkDecode == nil {
+					t.Fatal("expected a bulk decoder")
+				}
+				dst := make([]int, len(src))
+				n := bulkDecode(dst, src)
+				if n != len(expected) {
+					t.Fatalf("expected %d samples but got %d", len(expected), n)
+				}
+				if !reflect.DeepEqual(dst[:n], expected) {
+					t.Fatalf("expected %v but got %v", expected, dst[:n])
+				}
+			})
+		}
+	}
+	if bulkDecodeFunc(8, binary.BigEndian) != nil {
+		t.Fatal("expected no bulk decoder for 8-bit samples")
+	}
+}
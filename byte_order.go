@@ -0,0 +1,116 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// pcmByteOrder returns the byte order of the samples for the encodings
+// storing uncompressed PCM data. False is returned for the other encodings.
+func pcmByteOrder(encoding Encoding) (binary.ByteOrder, bool) {
+	switch encoding {
+	case EncNotSet, EncNone, EncTwos, EncIn24, EncIn32:
+		return binary.BigEndian, true
+	case EncSowt, Enc23ni, Enc42n1:
+		return binary.LittleEndian, true
+	}
+	return nil, false
+}
+
+// pcmBitDepth returns the bit depth implied by the passed encoding, 0 if the
+// encoding supports any bit depth.
+func pcmBitDepth(encoding Encoding) int {
+	switch encoding {
+	case EncIn24, Enc23ni:
+		return 24
+	case EncIn32, Enc42n1:
+		return 32
+	}
+	return 0
+}
+
+// ForceByteOrder overrides the byte order declared by the file (big endian
+// unless the AIFC encoding is 'sowt', '23ni' or '42n1'). This is useful to rescue files written
+// by broken exporters declaring the wrong encoding. See GuessByteOrder.
+// Passing nil restores the declared byte order.
+func (d *Decoder) ForceByteOrder(byteOrder binary.ByteOrder) {
+	if d == nil {
+		return
+	}
+	d.forcedByteOrder = byteOrder
+	if byteOrder != nil {
+		d.byteOrder = byteOrder
+		return
+	}
+	d.byteOrder = binary.BigEndian
+	if declared, ok := pcmByteOrder(d.Encoding); ok {
+		d.byteOrder = declared
+	}
+}
+
+// ByteOrder returns the byte order used to decode the samples.
+func (d *Decoder) ByteOrder() binary.ByteOrder {
+	if d == nil {
+		return nil
+	}
+	return d.byteOrder
+}
+
+// GuessByteOrder inspects the beginning of the PCM data and returns the byte
+// order that makes the signal the smoothest, audio content being usually
+// much smoother than its byte swapped version. The current byte order is
+// returned for 8 bit files and when not enough data is available.
+// The decoder is forwarded to the PCM data if needed but the data isn't
+// consumed.
+func (d *Decoder) GuessByteOrder() (binary.ByteOrder, error) {
+	if d == nil {
+		return nil, errors.New("can't guess the byte order of a nil decoder")
+	}
+	start, length, err := d.PCMOffset()
+	if err != nil {
+		return nil, err
+	}
+	bPerSample := bytesPerSample(int(d.BitDepth))
+	if bPerSample < 1 || bPerSample > 4 {
+		return nil, fmt.Errorf("%v bit depth not supported", d.BitDepth)
+	}
+	numChans := int(d.NumChans)
+	frameSize := bPerSample * numChans
+	if bPerSample == 1 || frameSize < 1 {
+		return d.byteOrder, nil
+	}
+
+	maxSize := int64(65536 - 65536%frameSize)
+	if length > maxSize {
+		length = maxSize
+	}
+	data := make([]byte, length)
+	n, _ := d.ra.ReadAt(data, start)
+	data = data[:n-n%frameSize]
+	if len(data) < 2*frameSize {
+		return d.byteOrder, nil
+	}
+
+	roughness := func(byteOrder binary.ByteOrder) float64 {
+		var sum float64
+		prev := make([]int, numChans)
+		for i := 0; i < len(data); i += bPerSample {
+			v := decodeSample(data[i:i+bPerSample], byteOrder)
+			ch := (i / bPerSample) % numChans
+			if i >= frameSize {
+				diff := float64(v - prev[ch])
+				if diff < 0 {
+					diff = -diff
+				}
+				sum += diff
+			}
+			prev[ch] = v
+		}
+		return sum
+	}
+	if roughness(binary.LittleEndian) < roughness(binary.BigEndian) {
+		return binary.LittleEndian, nil
+	}
+	return binary.BigEndian, nil
+}
@@ -0,0 +1,77 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+)
+
+func TestDecoder_GuessByteOrder(t *testing.T) {
+	testCases := []struct {
+		input     string
+		byteOrder binary.ByteOrder
+	}{
+		{"fixtures/kick.aif", binary.BigEndian},
+		{"fixtures/zipper24b.aiff", binary.BigEndian},
+		{"fixtures/sowt.aif", binary.LittleEndian},
+		{"fixtures/sowt2.aif", binary.LittleEndian},
+	}
+
+	for _, tc := range testCases {
+		f, err := os.Open(tc.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		d := NewDecoder(f)
+		// declare the wrong byte order, the guess shouldn't be influenced.
+		if tc.byteOrder == binary.BigEndian {
+			d.ForceByteOrder(binary.LittleEndian)
+		} else {
+			d.ForceByteOrder(binary.BigEndian)
+		}
+		byteOrder, err := d.GuessByteOrder()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if byteOrder != tc.byteOrder {
+			t.Fatalf("expected the byte order of %s to be guessed as %v but got %v", tc.input, tc.byteOrder, byteOrder)
+		}
+	}
+}
+
+func TestDecoder_ForceByteOrder(t *testing.T) {
+	f, err := os.Open("fixtures/sowt.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	expected, err := NewDecoder(f).FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(f)
+	d.ForceByteOrder(binary.BigEndian)
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.ByteOrder() != binary.BigEndian {
+		t.Fatalf("expected the forced byte order to be used, got %v", d.ByteOrder())
+	}
+	for i, v := range expected.Data {
+		swapped := int(int16(uint16(v)<<8 | uint16(v)>>8&0xff))
+		if buf.Data[i] != swapped {
+			t.Fatalf("expected sample %d to be byte swapped (%d) but got %d", i, swapped, buf.Data[i])
+		}
+	}
+
+	d.ForceByteOrder(nil)
+	if d.ByteOrder() != binary.LittleEndian {
+		t.Fatal("expected the declared byte order to be restored")
+	}
+}
@@ -0,0 +1,279 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+// ChannelLabel identifies the speaker a channel is meant for. The values
+// are the ones of the CoreAudio AudioChannelLabel used in CHAN chunks.
+type ChannelLabel uint32
+
+// Common channel labels.
+const (
+	ChannelUnused              ChannelLabel = 0
+	ChannelLeft                ChannelLabel = 1
+	ChannelRight               ChannelLabel = 2
+	ChannelCenter              ChannelLabel = 3
+	ChannelLFE                 ChannelLabel = 4
+	ChannelLeftSurround        ChannelLabel = 5
+	ChannelRightSurround       ChannelLabel = 6
+	ChannelLeftCenter          ChannelLabel = 7
+	ChannelRightCenter         ChannelLabel = 8
+	ChannelCenterSurround      ChannelLabel = 9
+	ChannelLeftSurroundDirect  ChannelLabel = 10
+	ChannelRightSurroundDirect ChannelLabel = 11
+	ChannelTopCenterSurround   ChannelLabel = 12
+	ChannelRearSurroundLeft    ChannelLabel = 33
+	ChannelRearSurroundRight   ChannelLabel = 34
+	ChannelMono                ChannelLabel = 42
+	ChannelUnknown             ChannelLabel = 0xFFFFFFFF
+)
+
+var channelLabelNames = map[ChannelLabel]string{
+	ChannelUnused:              "unused",
+	ChannelLeft:                "L",
+	ChannelRight:               "R",
+	ChannelCenter:              "C",
+	ChannelLFE:                 "LFE",
+	ChannelLeftSurround:        "Ls",
+	ChannelRightSurround:       "Rs",
+	ChannelLeftCenter:          "Lc",
+	ChannelRightCenter:         "Rc",
+	ChannelCenterSurround:      "Cs",
+	ChannelLeftSurroundDirect:  "Lsd",
+	ChannelRightSurroundDirect: "Rsd",
+	ChannelTopCenterSurround:   "Ts",
+	ChannelRearSurroundLeft:    "Rls",
+	ChannelRearSurroundRight:   "Rrs",
+	ChannelMono:                "M",
+	ChannelUnknown:             "unknown",
+}
+
+func (l ChannelLabel) String() string {
+	if name, ok := channelLabelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("label(%d)", uint32(l))
+}
+
+// Layout tags of the CHAN chunk, the number of channels is stored in the
+// lower 16 bits.
+const (
+	// LayoutUseChannelDescriptions means the channels are described one by
+	// one, see ChannelLayout.Descriptions.
+	LayoutUseChannelDescriptions uint32 = 0
+	// LayoutUseChannelBitmap means the channels are described by
+	// ChannelLayout.Bitmap.
+	LayoutUseChannelBitmap uint32 = 1 << 16
+
+	LayoutMono         uint32 = 100<<16 | 1
+	LayoutStereo       uint32 = 101<<16 | 2
+	LayoutQuadraphonic uint32 = 108<<16 | 4
+	LayoutMPEG30A      uint32 = 113<<16 | 3
+	LayoutMPEG40A      uint32 = 115<<16 | 4
+	LayoutMPEG50A      uint32 = 117<<16 | 5
+	LayoutMPEG51A      uint32 = 121<<16 | 6
+	LayoutMPEG51C      uint32 = 123<<16 | 6
+	LayoutMPEG61A      uint32 = 125<<16 | 7
+	LayoutMPEG71A      uint32 = 126<<16 | 8
+	LayoutMPEG71C      uint32 = 128<<16 | 8
+)
+
+// layoutLabels are the channel labels of the supported layout tags, in
+// interleaving order.
+var layoutLabels = map[uint32][]ChannelLabel{
+	LayoutMono:         {ChannelMono},
+	LayoutStereo:       {ChannelLeft, ChannelRight},
+	LayoutQuadraphonic: {ChannelLeft, ChannelRight, ChannelLeftSurround, ChannelRightSurround},
+	LayoutMPEG30A:      {ChannelLeft, ChannelRight, ChannelCenter},
+	LayoutMPEG40A:      {ChannelLeft, ChannelRight, ChannelCenter, ChannelCenterSurround},
+	LayoutMPEG50A:      {ChannelLeft, ChannelRight, ChannelCenter, ChannelLeftSurround, ChannelRightSurround},
+	LayoutMPEG51A:      {ChannelLeft, ChannelRight, ChannelCenter, ChannelLFE, ChannelLeftSurround, ChannelRightSurround},
+	LayoutMPEG51C:      {ChannelLeft, ChannelCenter, ChannelRight, ChannelLeftSurround, ChannelRightSurround, ChannelLFE},
+	LayoutMPEG61A:      {ChannelLeft, ChannelRight, ChannelCenter, ChannelLFE, ChannelLeftSurround, ChannelRightSurround, ChannelCenterSurround},
+	LayoutMPEG71A: {ChannelLeft, ChannelRight, ChannelCenter, ChannelLFE, ChannelLeftSurround, ChannelRightSurround,
+		ChannelLeftCenter, ChannelRightCenter},
+	LayoutMPEG71C: {ChannelLeft, ChannelRight, ChannelCenter, ChannelLFE, ChannelLeftSurround, ChannelRightSurround,
+		ChannelRearSurroundLeft, ChannelRearSurroundRight},
+}
+
+// specLabels are the channel orders defined by the AIFF specification,
+// used when a file doesn't have a CHAN chunk.
+var specLabels = map[int][]ChannelLabel{
+	1: {ChannelMono},
+	2: {ChannelLeft, ChannelRight},
+	3: {ChannelLeft, ChannelRight, ChannelCenter},
+	// quadraphonic
+	4: {ChannelLeft, ChannelRight, ChannelLeftSurround, ChannelRightSurround},
+	6: {ChannelLeft, ChannelLeftCenter, ChannelCenter, ChannelRight, ChannelRightCenter, ChannelCenterSurround},
+}
+
+// ChannelDescription describes a channel of a CHAN chunk.
+type ChannelDescription struct {
+	Label ChannelLabel
+	Flags uint32
+	// Coordinates of the speaker, see Flags for their meaning.
+	Coordinates [3]float32
+}
+
+// ChannelLayout is the content of the Apple specific CHAN chunk (a CoreAudio
+// AudioChannelLayout) describing the speaker of each channel.
+type ChannelLayout struct {
+	// Tag is one of the Layout* constants, or another CoreAudio layout tag.
+	Tag          uint32
+	Bitmap       uint32
+	Descriptions []ChannelDescription
+}
+
+// Labels returns the label of each of the numChans channels, in interleaving
+// order. ChannelUnknown is returned for the channels the layout doesn't
+// describe.
+func (l *ChannelLayout) Labels(numChans int) []ChannelLabel {
+	if numChans < 1 {
+		return nil
+	}
+	var labels []ChannelLabel
+	switch {
+	case l == nil:
+		return fillLabels(nil, numChans)
+	case l.Tag == LayoutUseChannelDescriptions:
+		for _, desc := range l.Descriptions {
+			labels = append(labels, desc.Label)
+		}
+	case l.Tag == LayoutUseChannelBitmap:
+		// bit n is set for the label n+1, the channels are ordered by label
+		for bit := 0; bit < 32; bit++ {
+			if l.Bitmap&(1<<uint(bit)) != 0 {
+				labels = append(labels, ChannelLabel(bit+1))
+			}
+		}
+	default:
+		labels = layoutLabels[l.Tag]
+	}
+	return fillLabels(labels, numChans)
+}
+
+// fillLabels returns numChans labels, the channels missing from the passed
+// labels are set as ChannelUnknown.
+func fillLabels(labels []ChannelLabel, numChans int) []ChannelLabel {
+	out := make([]ChannelLabel, numChans)
+	for i := range out {
+		out[i] = ChannelUnknown
+		if i < len(labels) {
+			out[i] = labels[i]
+		}
+	}
+	return out
+}
+
+// ChannelLabels returns the label of each channel of the file using the
+// CHAN chunk when present, otherwise the channel order defined by the AIFF
+// specification for 2, 3, 4 and 6 channels. ChannelUnknown is returned for
+// the channels that can't be labeled.
+// The file information and the CHAN chunk are parsed if needed without
+// moving the underlying reader.
+func (d *Decoder) ChannelLabels() []ChannelLabel {
+	if d == nil {
+		return nil
+	}
+	d.ReadInfo()
+	numChans := int(d.NumChans)
+	if d.ChannelLayout == nil && !d.SkipMetadata && !d.filtersOut(chanID) {
+		d.readChannelLayout()
+	}
+	if d.ChannelLayout != nil {
+		return d.ChannelLayout.Labels(numChans)
+	}
+	if numChans < 1 {
+		return nil
+	}
+	return fillLabels(specLabels[numChans], numChans)
+}
+
+// readChannelLayout looks for a CHAN chunk and parses it.
+func (d *Decoder) readChannelLayout() {
+	offset := int64(12)
+	for {
+		id, size, err := d.iDnSizeAt(offset)
+		if err != nil {
+			return
+		}
+		if id == chanID {
+			chunk := &Chunk{
+				ID:     id,
+				Size:   int(size),
+				R:      io.NewSectionReader(d.ra, offset+8, int64(size)),
+				offset: offset,
+			}
+			if err := d.parseChanChunk(chunk); err != nil {
+				d.logf("failed to read the CHAN chunk (ignored) - %v", err)
+			}
+			return
+		}
+		offset += 8 + int64(size) + int64(size%2)
+	}
+}
+
+// parseChanChunk processes the CHAN chunk.
+// See https://github.com/nu774/qaac/blob/ce73aac9bfba459c525eec5350da6346ebf547cf/chanmap.cpp
+// for format information
+func (d *Decoder) parseChanChunk(chunk *Chunk) error {
+	b, err := ioutil.ReadAll(chunk)
+	if err != nil {
+		return err
+	}
+	if len(b) < 12 {
+		return errors.New("CHAN chunk too short")
+	}
+	layout := &ChannelLayout{
+		Tag:    binary.BigEndian.Uint32(b[0:]),
+		Bitmap: binary.BigEndian.Uint32(b[4:]),
+	}
+	n := int(binary.BigEndian.Uint32(b[8:]))
+	b = b[12:]
+	if n > len(b)/20 {
+		return fmt.Errorf("CHAN chunk too short for %d channel descriptions", n)
+	}
+	for i := 0; i < n; i++ {
+		desc := ChannelDescription{
+			Label: ChannelLabel(binary.BigEndian.Uint32(b[0:])),
+			Flags: binary.BigEndian.Uint32(b[4:]),
+		}
+		for j := range desc.Coordinates {
+			desc.Coordinates[j] = math.Float32frombits(binary.BigEndian.Uint32(b[8+4*j:]))
+		}
+		layout.Descriptions = append(layout.Descriptions, desc)
+		b = b[20:]
+	}
+	d.ChannelLayout = layout
+	return nil
+}
+
+// writeChannelLayout writes the channel layout in a CHAN chunk.
+func (e *Encoder) writeChannelLayout() error {
+	l := e.ChannelLayout
+	size := 12 + 20*len(l.Descriptions)
+	if err := e.AddBE(chanID); err != nil {
+		return fmt.Errorf("%v when writing CHAN chunk ID header", err)
+	}
+	if err := e.AddBE(uint32(size)); err != nil {
+		return fmt.Errorf("%v when writing CHAN chunk size header", err)
+	}
+	if err := e.AddBE([]uint32{l.Tag, l.Bitmap, uint32(len(l.Descriptions))}); err != nil {
+		return fmt.Errorf("%v when writing the channel layout", err)
+	}
+	for _, desc := range l.Descriptions {
+		if err := e.AddBE([]uint32{uint32(desc.Label), desc.Flags}); err != nil {
+			return fmt.Errorf("%v when writing a channel description", err)
+		}
+		if err := e.AddBE(desc.Coordinates); err != nil {
+			return fmt.Errorf("%v when writing the channel coordinates", err)
+		}
+	}
+	return nil
+}
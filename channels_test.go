@@ -0,0 +1,148 @@
+package aiff
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestMultichannelRoundTrip(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	testCases := []struct {
+		numChans int
+		layout   *ChannelLayout
+		labels   []ChannelLabel
+	}{
+		{4, nil, []ChannelLabel{ChannelLeft, ChannelRight, ChannelLeftSurround, ChannelRightSurround}},
+		{6, nil, []ChannelLabel{ChannelLeft, ChannelLeftCenter, ChannelCenter, ChannelRight, ChannelRightCenter, ChannelCenterSurround}},
+		{8, nil, []ChannelLabel{ChannelUnknown, ChannelUnknown, ChannelUnknown, ChannelUnknown,
+			ChannelUnknown, ChannelUnknown, ChannelUnknown, ChannelUnknown}},
+		{6, &ChannelLayout{Tag: LayoutMPEG51A},
+			[]ChannelLabel{ChannelLeft, ChannelRight, ChannelCenter, ChannelLFE, ChannelLeftSurround, ChannelRightSurround}},
+		{8, &ChannelLayout{Tag: LayoutMPEG71C},
+			[]ChannelLabel{ChannelLeft, ChannelRight, ChannelCenter, ChannelLFE, ChannelLeftSurround, ChannelRightSurround,
+				ChannelRearSurroundLeft, ChannelRearSurroundRight}},
+		{4, &ChannelLayout{Tag: LayoutUseChannelBitmap, Bitmap: 1<<0 | 1<<1 | 1<<2 | 1<<3},
+			[]ChannelLabel{ChannelLeft, ChannelRight, ChannelCenter, ChannelLFE}},
+		{4, &ChannelLayout{Tag: LayoutUseChannelDescriptions, Descriptions: []ChannelDescription{
+			{Label: ChannelCenter}, {Label: ChannelLeft}, {Label: ChannelRight, Coordinates: [3]float32{1, 0.5, -1}}}},
+			[]ChannelLabel{ChannelCenter, ChannelLeft, ChannelRight, ChannelUnknown}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d channels %v", tc.numChans, tc.labels), func(t *testing.T) {
+			out, err := os.Create("testOutput/multichannel.aif")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(out.Name())
+			defer out.Close()
+
+			numFrames := 100
+			// each sample identifies its frame and channel to check the
+			// interleaving order
+			buf := &audio.IntBuffer{
+				Format:         &audio.Format{NumChannels: tc.numChans, SampleRate: 48000},
+				SourceBitDepth: 16,
+				Data:           make([]int, numFrames*tc.numChans),
+			}
+			for i := range buf.Data {
+				buf.Data[i] = (i/tc.numChans)*10 + i%tc.numChans
+			}
+			e := NewEncoder(out, 48000, 16, tc.numChans)
+			e.ChannelLayout = tc.layout
+			if err := e.Write(buf); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			d := NewDecoder(out)
+			if !d.IsValidFile() {
+				t.Fatalf("expected a valid file - %v", d.Err())
+			}
+			if got := d.ChannelLabels(); !reflect.DeepEqual(got, tc.labels) {
+				t.Fatalf("expected the channels to be labeled %v but got %v", tc.labels, got)
+			}
+			if d.Format().NumChannels != tc.numChans {
+				t.Fatalf("expected the format to have %d channels but got %d", tc.numChans, d.Format().NumChannels)
+			}
+			decoded, err := d.FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded.Format.NumChannels != tc.numChans {
+				t.Fatalf("expected the buffer to have %d channels but got %d", tc.numChans, decoded.Format.NumChannels)
+			}
+			if !reflect.DeepEqual(decoded.Data, buf.Data) {
+				t.Fatal("the decoded samples don't match the encoded ones")
+			}
+			if err := d.Drain(); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(d.ChannelLayout, tc.layout) {
+				t.Fatalf("expected the channel layout to be %+v but got %+v", tc.layout, d.ChannelLayout)
+			}
+
+			// frame by frame
+			if err := d.Rewind(); err != nil {
+				t.Fatal(err)
+			}
+			small := &audio.IntBuffer{Data: make([]int, 3*tc.numChans)}
+			n, err := d.PCMBuffer(small)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(small.Data) || small.Format.NumChannels != tc.numChans {
+				t.Fatalf("expected %d samples of %d channels but got %d of %d", len(small.Data), tc.numChans, n, small.Format.NumChannels)
+			}
+			if !reflect.DeepEqual(small.Data, buf.Data[:n]) {
+				t.Fatal("the partially decoded samples don't match the encoded ones")
+			}
+		})
+	}
+}
+
+func TestEncoderChannelMismatch(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/channel_mismatch.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	e := NewEncoder(out, 44100, 16, 4)
+	if err := e.Write(&audio.IntBuffer{Data: []int{0, 0}, Format: &audio.Format{NumChannels: 2, SampleRate: 44100}}); err == nil {
+		t.Fatal("expected an error writing a stereo buffer to a 4 channel file")
+	}
+	if err := NewEncoder(out, 44100, 16, 0).Write(&audio.IntBuffer{Format: &audio.Format{}}); err == nil {
+		t.Fatal("expected an error writing a file without channels")
+	}
+}
+
+func TestDecoder_ChannelLabels(t *testing.T) {
+	testCases := []struct {
+		input  string
+		labels []ChannelLabel
+	}{
+		{"fixtures/kick.aif", []ChannelLabel{ChannelMono}},
+		{"fixtures/ring.aif", []ChannelLabel{ChannelLeft, ChannelRight}},
+	}
+	for _, tc := range testCases {
+		f, err := os.Open(tc.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if got := NewDecoder(f).ChannelLabels(); !reflect.DeepEqual(got, tc.labels) {
+			t.Fatalf("expected the channels of %s to be labeled %v but got %v", tc.input, tc.labels, got)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package aiff
+
+import (
+	"crypto/md5"
+	"errors"
+	"fmt"
+
+	"github.com/go-audio/audio"
+)
+
+// AudioMD5 computes a MD5 hash of the decoded PCM data only (à la FLAC).
+// Chunks other than the sound data are ignored, meaning that two files
+// with the same audio content but different metadata will have the same hash.
+// Samples are hashed as signed little endian values using the bit depth of
+// the source so big endian and sowt encoded files can also be compared.
+// Note that the PCM data is consumed by this call.
+func (d *Decoder) AudioMD5() ([]byte, error) {
+	if d == nil {
+		return nil, errors.New("can't compute the hash of a nil pointer")
+	}
+	if !d.WasPCMAccessed() {
+		if err := d.FwdToPCM(); err != nil {
+			return nil, fmt.Errorf("failed to forward to PCM: %v", err)
+		}
+	}
+	if d.PCMChunk == nil {
+		return nil, fmt.Errorf("PCM chunk not found - %v", d.Err())
+	}
+
+	bPerSample := bytesPerSample(int(d.BitDepth))
+	if bPerSample < 1 {
+		return nil, fmt.Errorf("%v bit depth not supported", d.BitDepth)
+	}
+	h := md5.New()
+	buf := &audio.IntBuffer{Data: make([]int, 4096)}
+	out := make([]byte, len(buf.Data)*bPerSample)
+	for {
+		n, err := d.PCMBuffer(buf)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			break
+		}
+		for i, v := range buf.Data[:n] {
+			for j := 0; j < bPerSample; j++ {
+				out[i*bPerSample+j] = byte(v >> uint(8*j))
+			}
+		}
+		h.Write(out[:n*bPerSample])
+	}
+
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,59 @@
+package aiff
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestDecoder_AudioMD5(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	sum := func(path string) []byte {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		h, err := NewDecoder(f).AudioMD5()
+		if err != nil {
+			t.Fatalf("failed to hash %s - %v", path, err)
+		}
+		return h
+	}
+
+	kick := sum("fixtures/kick.aif")
+	if len(kick) != 16 {
+		t.Fatalf("expected a 16 byte hash but got %d bytes", len(kick))
+	}
+	if bytes.Equal(kick, sum("fixtures/bloop.aif")) {
+		t.Fatal("expected the audio hashes of kick.aif and bloop.aif to differ")
+	}
+
+	// re-encoding the file drops the extra chunks but keeps the audio intact.
+	in, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	d := NewDecoder(in)
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.Create("testOutput/kick_md5.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	e := NewEncoder(out, d.SampleRate, int(d.BitDepth), int(d.NumChans))
+	if err := e.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	out.Close()
+	if !bytes.Equal(kick, sum(out.Name())) {
+		t.Fatal("expected the audio hash of the re-encoded file to match the original")
+	}
+}
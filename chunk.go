@@ -3,6 +3,7 @@ package aiff
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 )
@@ -20,29 +21,124 @@ type Chunk struct {
 	Size int
 	R    io.Reader
 	Pos  int
+
+	// offset of the chunk header in the underlying reader
+	offset int64
+	// pad is 1 when the data has an odd size and is followed by a pad byte
+	// (not part of the data) to keep the next chunk aligned, it's reset once
+	// the pad byte is consumed.
+	pad int
+}
+
+// Offset returns the position of the chunk header (ID) in the underlying
+// reader. The chunk data starts 8 bytes later.
+func (ch *Chunk) Offset() int64 {
+	if ch == nil {
+		return 0
+	}
+	return ch.offset
+}
+
+// DataOffset returns the position of the chunk data in the underlying
+// reader.
+func (ch *Chunk) DataOffset() int64 {
+	if ch == nil {
+		return 0
+	}
+	return ch.offset + 8
+}
+
+// AbsPos returns the position in the underlying reader of the next byte of
+// data to be read, that is DataOffset + Pos.
+func (ch *Chunk) AbsPos() int64 {
+	if ch == nil {
+		return 0
+	}
+	return ch.DataOffset() + int64(ch.Pos)
 }
 
 // Done makes sure the entire chunk was read.
 func (ch *Chunk) Done() {
-	if !ch.IsFullyRead() {
-		ch.drain()
+	ch.Drain()
+}
+
+// Remaining returns the number of bytes of data left to read, the pad byte
+// of odd sized chunks isn't included.
+func (ch *Chunk) Remaining() int {
+	if ch == nil || ch.R == nil || ch.Pos >= ch.Size {
+		return 0
 	}
+	return ch.Size - ch.Pos
 }
 
-func (ch *Chunk) drain() error {
-	bytesAhead := ch.Size - ch.Pos
-	if bytesAhead > 0 {
-		_, err := io.CopyN(ioutil.Discard, ch.R, int64(bytesAhead))
+// Skip discards the next n bytes of data. An error is returned without
+// skipping anything if fewer than n bytes remain.
+func (ch *Chunk) Skip(n int) error {
+	if n < 0 {
+		return fmt.Errorf("can't skip a negative number of bytes: %d", n)
+	}
+	if ch == nil {
+		if n > 0 {
+			return errors.New("nil chunk/reader pointer")
+		}
+		return nil
+	}
+	if remaining := ch.Remaining(); n > remaining {
+		return fmt.Errorf("can't skip %d bytes, only %d remaining in the %q chunk", n, remaining, ch.ID[:])
+	}
+	if n == 0 {
+		return nil
+	}
+	written, err := io.CopyN(ioutil.Discard, ch.R, int64(n))
+	ch.Pos += int(written)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Drain discards the rest of the data and the pad byte following odd sized
+// chunks so the underlying reader is positioned on the next chunk.
+func (ch *Chunk) Drain() error {
+	if err := ch.Skip(ch.Remaining()); err != nil {
+		return err
+	}
+	if ch == nil || ch.pad == 0 {
+		return nil
+	}
+	ch.pad = 0
+	// the pad byte is sometimes missing at the end of files
+	if _, err := io.CopyN(ioutil.Discard, ch.R, 1); err != nil && err != io.EOF {
 		return err
 	}
 	return nil
 }
 
-// Read implements the reader interface
+// Bytes reads and returns the rest of the data, the pad byte following odd
+// sized chunks is consumed but not returned.
+func (ch *Chunk) Bytes() ([]byte, error) {
+	if ch == nil || ch.R == nil {
+		return nil, errors.New("nil chunk/reader pointer")
+	}
+	b := make([]byte, ch.Remaining())
+	if _, err := io.ReadFull(ch, b); err != nil {
+		return nil, err
+	}
+	return b, ch.Drain()
+}
+
+// Read implements the reader interface, reading stops at the end of the
+// data.
 func (ch *Chunk) Read(p []byte) (n int, err error) {
 	if ch == nil || ch.R == nil {
 		return 0, errors.New("nil chunk/reader pointer")
 	}
+	if ch.Pos >= ch.Size {
+		return 0, io.EOF
+	}
+	if remaining := ch.Size - ch.Pos; len(p) > remaining {
+		p = p[:remaining]
+	}
 	n, err = ch.R.Read(p)
 	ch.Pos += n
 	return n, err
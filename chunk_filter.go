@@ -0,0 +1,57 @@
+package aiff
+
+import "io"
+
+// WithChunks restricts the chunks parsed by the decoder to the passed IDs,
+// the other chunks are skipped by seeking over them which keeps the I/O to
+// a minimum when probing files. The COMM and SSND chunks are always parsed
+// since they are required to decode the audio, and the handlers registered
+// via OnChunk are still called.
+// Calling WithChunks again adds IDs to the list.
+func (d *Decoder) WithChunks(ids ...[4]byte) *Decoder {
+	if d.allowedChunks == nil {
+		d.allowedChunks = map[[4]byte]bool{}
+	}
+	for _, id := range ids {
+		d.allowedChunks[id] = true
+	}
+	return d
+}
+
+// WithoutChunks prevents the decoder from parsing the chunks matching the
+// passed IDs, they are skipped by seeking over them. The COMM and SSND chunks
+// can't be excluded and the handlers registered via OnChunk are still
+// called.
+// Calling WithoutChunks again adds IDs to the list.
+func (d *Decoder) WithoutChunks(ids ...[4]byte) *Decoder {
+	if d.deniedChunks == nil {
+		d.deniedChunks = map[[4]byte]bool{}
+	}
+	for _, id := range ids {
+		d.deniedChunks[id] = true
+	}
+	return d
+}
+
+// filtersOut reports whether the chunk is excluded from parsing by
+// WithChunks or WithoutChunks.
+func (d *Decoder) filtersOut(id [4]byte) bool {
+	if id == COMMID || id == SSNDID {
+		return false
+	}
+	if d.deniedChunks[id] {
+		return true
+	}
+	return d.allowedChunks != nil && !d.allowedChunks[id]
+}
+
+// skipChunk moves the underlying reader past the chunk and its pad byte
+// without reading them.
+func (d *Decoder) skipChunk(chunk *Chunk) error {
+	if _, err := d.r.Seek(int64(chunk.Size-chunk.Pos+chunk.pad), io.SeekCurrent); err != nil {
+		return err
+	}
+	chunk.Pos = chunk.Size
+	chunk.pad = 0
+	return nil
+}
@@ -0,0 +1,49 @@
+package aiff
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDecoder_WithChunks(t *testing.T) {
+	f, err := os.Open("fixtures/ring.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	d := NewDecoder(f).WithChunks(COMMID, SSNDID, markID)
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.NumFrames() != 88064 {
+		t.Fatalf("expected 88064 frames but got %d", buf.NumFrames())
+	}
+	if len(d.Markers) != 2 {
+		t.Fatalf("expected the markers to be parsed but got %v", d.Markers)
+	}
+	if len(d.Comments) > 0 || d.HasAppleInfo {
+		t.Fatalf("expected the other chunks to be skipped, got %v, %v", d.Comments, d.HasAppleInfo)
+	}
+
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d = NewDecoder(f).WithoutChunks(markID, SSNDID)
+	if _, err := d.FullPCMBuffer(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Markers) > 0 {
+		t.Fatalf("expected the markers to be skipped but got %v", d.Markers)
+	}
+	if len(d.Comments) != 1 || !d.HasAppleInfo {
+		t.Fatalf("expected the other chunks to be parsed, got %v, %v", d.Comments, d.HasAppleInfo)
+	}
+}
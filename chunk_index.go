@@ -0,0 +1,127 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ChunkInfo locates a chunk in the underlying reader.
+type ChunkInfo struct {
+	ID [4]byte
+	// Offset is the position of the chunk header.
+	Offset int64
+	// Size is the size of the chunk data, without the pad byte.
+	Size uint32
+}
+
+// scanChunks builds the table of the chunks following the FORM header
+// without moving the underlying reader, the table is cached until the
+// decoder is reset. The scan stops at the end of the file or when an
+// invalid chunk header is found (and can't be skipped, see ResyncWindow), in
+// which case the chunks found so far are returned along with the error.
+func (d *Decoder) scanChunks() ([]ChunkInfo, error) {
+	if d.chunkTable != nil || d.chunkTableErr != nil {
+		return d.chunkTable, d.chunkTableErr
+	}
+	table := []ChunkInfo{}
+	var err error
+	// the chunks start right after the FORM header
+	for offset := int64(12); ; {
+		var (
+			id   [4]byte
+			size uint32
+		)
+		id, size, err = d.iDnSizeAt(offset)
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			} else {
+				err = fmt.Errorf("error reading chunk header at %d - %v", offset, err)
+			}
+			break
+		}
+		if !isChunkID(id) {
+			if next, ok := d.resync(offset); ok {
+				offset = next
+				continue
+			}
+			err = fmt.Errorf("invalid chunk ID %q at %d", id[:], offset)
+			break
+		}
+		table = append(table, ChunkInfo{ID: id, Offset: offset, Size: size})
+		// chunks are always aligned on even offsets
+		offset += 8 + int64(size) + int64(size%2)
+	}
+	d.chunkTable, d.chunkTableErr = table, err
+	return table, err
+}
+
+// ChunkIndex is the table of the chunks of a file, see Decoder.Index.
+// The chunks can be opened in any order, at any time, without moving the
+// reader of the decoder.
+type ChunkIndex struct {
+	// Chunks are the chunks of the file in the order they are stored.
+	Chunks []ChunkInfo
+
+	d *Decoder
+}
+
+// Index builds the table of all the chunks of the file in one pass, reading
+// only their headers. If an invalid chunk header is found, the chunks found
+// before it are indexed and the error is returned along with the index.
+func (d *Decoder) Index() (*ChunkIndex, error) {
+	if d == nil || d.ra == nil {
+		return nil, errors.New("can't index a nil decoder or reader")
+	}
+	if err := d.readHeaders(); err != nil {
+		return nil, fmt.Errorf("failed to read header - %v", err)
+	}
+	table, err := d.scanChunks()
+	return &ChunkIndex{Chunks: append([]ChunkInfo(nil), table...), d: d}, err
+}
+
+// Len returns the number of indexed chunks.
+func (idx *ChunkIndex) Len() int {
+	if idx == nil {
+		return 0
+	}
+	return len(idx.Chunks)
+}
+
+// Find returns the position in the index of the first chunk matching the
+// ID, or -1 if there isn't any.
+func (idx *ChunkIndex) Find(id [4]byte) int {
+	for i := 0; i < idx.Len(); i++ {
+		if idx.Chunks[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// OpenChunk returns the first chunk matching the ID, see OpenChunkAt.
+func (idx *ChunkIndex) OpenChunk(id [4]byte) (*Chunk, error) {
+	i := idx.Find(id)
+	if i < 0 {
+		return nil, fmt.Errorf("%q chunk not found", id[:])
+	}
+	return idx.OpenChunkAt(i)
+}
+
+// OpenChunkAt returns the i-th chunk of the index. The data is read lazily
+// from its own section of the file, chunks can be opened and read
+// concurrently as long as the underlying reader supports concurrent
+// ReadAt calls.
+func (idx *ChunkIndex) OpenChunkAt(i int) (*Chunk, error) {
+	if i < 0 || i >= idx.Len() {
+		return nil, fmt.Errorf("chunk %d out of range [0, %d)", i, idx.Len())
+	}
+	info := idx.Chunks[i]
+	return &Chunk{
+		ID:     info.ID,
+		Size:   int(info.Size),
+		R:      io.NewSectionReader(idx.d.ra, info.Offset+8, int64(info.Size)),
+		offset: info.Offset,
+	}, nil
+}
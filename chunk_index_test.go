@@ -0,0 +1,93 @@
+package aiff
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDecoder_Index(t *testing.T) {
+	f, err := os.Open("fixtures/sowt2.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	idx, err := d.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ChunkInfo{
+		{COMMID, 12, 24}, {copyrightID, 44, 25}, {COMTID, 78, 35},
+		{[4]byte{'A', 'F', 'm', 'd'}, 122, 381}, {SSNDID, 512, 666716}, {[4]byte{'A', 'F', 'A', 'n'}, 667236, 16183},
+	}
+	if idx.Len() != len(expected) {
+		t.Fatalf("expected %d chunks but got %d: %v", len(expected), idx.Len(), idx.Chunks)
+	}
+	for i, info := range expected {
+		if idx.Chunks[i] != info {
+			t.Fatalf("expected chunk %d to be %v but got %v", i, info, idx.Chunks[i])
+		}
+	}
+
+	// chunks can be read in any order
+	c, err := idx.OpenChunk(copyrightID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "(c) 2009 mutekki-media.de" {
+		t.Fatalf("unexpected copyright %q", b)
+	}
+	c, err = idx.OpenChunkAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ID != COMMID || c.Offset() != 12 || c.Remaining() != 24 {
+		t.Fatalf("unexpected chunk %q at %d (%d bytes)", c.ID, c.Offset(), c.Remaining())
+	}
+	if _, err := idx.OpenChunk([4]byte{'N', 'O', 'N', 'E'}); err == nil {
+		t.Fatal("expected an error opening a missing chunk")
+	}
+	if _, err := idx.OpenChunkAt(idx.Len()); err == nil {
+		t.Fatal("expected an error opening a chunk out of range")
+	}
+
+	// the decoder can still be used
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.NumFrames() != 166677 {
+		t.Fatalf("expected 166677 frames but got %d", buf.NumFrames())
+	}
+	c, err = idx.OpenChunk(COMTID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := c.Bytes(); len(b) != 35 {
+		t.Fatalf("expected 35 bytes of comments but got %d", len(b))
+	}
+}
+
+func TestDecoder_IndexInvalidChunk(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, 0, 1, 2, 3, 0, 0, 0, 0)
+	idx, err := NewDecoder(bytes.NewReader(data)).Index()
+	if err == nil {
+		t.Fatal("expected an error indexing an invalid chunk")
+	}
+	if idx.Len() != 3 || idx.Find(SSNDID) != 1 {
+		t.Fatalf("expected the valid chunks to be indexed but got %v", idx.Chunks)
+	}
+	if _, err := NewDecoder(bytes.NewReader([]byte("RIFF0000WAVE"))).Index(); err == nil {
+		t.Fatal("expected an error indexing a file which isn't an AIFF file")
+	}
+}
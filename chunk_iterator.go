@@ -0,0 +1,69 @@
+package aiff
+
+import (
+	"errors"
+	"io"
+)
+
+// ChunkIterator iterates over the chunks of a container. Unlike NextChunk,
+// the iterator doesn't require the previous chunk to be fully consumed:
+// the reader is moved to the next chunk no matter what was read.
+//
+//	it := d.Chunks()
+//	for it.Next() {
+//		c := it.Chunk()
+//	}
+//	if err := it.Err(); err != nil {
+//		// handle the error
+//	}
+type ChunkIterator struct {
+	d     *Decoder
+	chunk *Chunk
+	err   error
+}
+
+// Chunks returns an iterator over the chunks following the current position
+// of the decoder.
+func (d *Decoder) Chunks() *ChunkIterator {
+	return &ChunkIterator{d: d}
+}
+
+// Next moves the iterator to the next chunk and reports whether a chunk
+// is available.
+func (it *ChunkIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.d == nil {
+		it.err = errors.New("can't iterate over the chunks of a nil decoder")
+		return false
+	}
+	if it.chunk != nil {
+		// skip what wasn't consumed from the previous chunk
+		end := it.chunk.Offset() + 8 + int64(it.chunk.Size) + int64(it.chunk.Size%2)
+		if _, err := it.d.r.Seek(end, io.SeekStart); err != nil {
+			it.err = err
+			return false
+		}
+		it.chunk = nil
+	}
+	chunk, err := it.d.NextChunk()
+	if err != nil {
+		if err != io.EOF {
+			it.err = err
+		}
+		return false
+	}
+	it.chunk = chunk
+	return true
+}
+
+// Chunk returns the current chunk.
+func (it *ChunkIterator) Chunk() *Chunk {
+	return it.chunk
+}
+
+// Err returns the first non-EOF error encountered while iterating.
+func (it *ChunkIterator) Err() error {
+	return it.err
+}
@@ -1,11 +1,8 @@
 package aiff
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
-	"io"
-	"io/ioutil"
 )
 
 // parseChunk processes a chunk and stores the valuable information
@@ -15,40 +12,84 @@ func (d *Decoder) parseChunk(chunk *Chunk) error {
 	if chunk == nil {
 		return nil
 	}
+	d.chunkParsed(chunk)
+
+	if fn, ok := d.chunkHandlers[chunk.ID]; ok {
+		err := fn(chunk)
+		chunk.Done()
+		return err
+	}
+
+	if d.SkipMetadata && isMetadataChunk(chunk.ID) {
+		chunk.Done()
+		return nil
+	}
+	if d.filtersOut(chunk.ID) {
+		return d.skipChunk(chunk)
+	}
 
 	switch chunk.ID {
 	// common chunk parsing
 	case COMMID:
-		if d.commSize > 0 {
-			chunk.Done()
-		}
-		if err := d.parseCommChunk(uint32(chunk.Size)); err != nil {
+		// the COMM chunk is usually parsed when reading the file information
+		if err := d.parseCommChunk(chunk, uint32(chunk.Size)); err != nil {
 			return err
 		}
-		// if we found the sound data before the COMM,
-		// we need to rewind the reader so we can properly
-		// set the clip reader.
-		if d.rewindBytes > 0 {
-			d.r.Seek(-d.rewindBytes, 1)
-			d.rewindBytes = 0
-		}
+		chunk.Done()
 	// audio content, should be read a different way
 	case SSNDID:
 		chunk.Done()
+	case fverID:
+		var timestamp uint32
+		if err := chunk.ReadBE(&timestamp); err != nil {
+			d.logf("failed to read the FVER chunk (ignored) - %v", err)
+		} else {
+			d.FormatVersion = aiffTime(timestamp)
+		}
+		chunk.Done()
 	// Comments Chunk
 	case COMTID:
+		if d.parsedChunks[chunk.offset] {
+			chunk.Done()
+			break
+		}
 		if err := d.parseCommentsChunk(chunk); err != nil {
-			fmt.Println("failed to read comments", err)
+			d.logf("failed to read the COMT chunk (ignored) - %v", err)
+		}
+	case markID:
+		if err := d.parseMarkChunk(chunk); err != nil {
+			d.logf("failed to read the MARK chunk (ignored) - %v", err)
 		}
+		chunk.Done()
+	case instID:
+		if err := d.parseInstChunk(chunk); err != nil {
+			d.logf("failed to read the INST chunk (ignored) - %v", err)
+		}
+		chunk.Done()
+	case nameID, authID, copyrightID, annoID:
+		if err := d.parseTextChunk(chunk); err != nil {
+			return err
+		}
+	case applID:
+		if err := d.parseApplChunk(chunk); err != nil {
+			d.logf("failed to read the APPL chunk (ignored) - %v", err)
+		}
+		chunk.Done()
+	case id3ID:
+		if err := d.parseID3Chunk(chunk); err != nil {
+			d.logf("failed to read the ID3 chunk (ignored) - %v", err)
+		}
+		chunk.Done()
 	// Apple/Logic specific chunk
 	case bascID:
 		if err := d.parseBascChunk(chunk); err != nil {
-			fmt.Println("failed to read BASC chunk", err)
+			d.logf("failed to read the basc chunk (ignored) - %v", err)
 		}
 	// Apple specific: packed struct AudioChannelLayout of CoreAudio
 	case chanID:
-		// See https://github.com/nu774/qaac/blob/ce73aac9bfba459c525eec5350da6346ebf547cf/chanmap.cpp
-		// for format information
+		if err := d.parseChanChunk(chunk); err != nil {
+			d.logf("failed to read the CHAN chunk (ignored) - %v", err)
+		}
 		chunk.Done()
 	// Apple specific transient data
 	case trnsID:
@@ -69,55 +110,50 @@ func (d *Decoder) parseChunk(chunk *Chunk) error {
 				var sampleBegin uint32
 			}
 		*/
-		chunk.Done()
+		d.collectUnknownChunk(chunk)
 	// Apple specific categorization
 	case cateID:
 		if err := d.parseCateChunk(chunk); err != nil {
-			fmt.Println("failed to read CATE chunk", err)
+			d.logf("failed to read the cate chunk (ignored) - %v", err)
 		}
 		chunk.Done()
 	default:
 		if Debug {
-			fmt.Printf("skipping unknown chunk %#v\n", chunk.ID[:])
-		}
-		// if we read SSN but didn't read the COMM, we need to track location
-		if d.SampleRate == 0 {
-			d.rewindBytes += int64(chunk.Size)
+			d.logf("skipping unknown chunk %q", chunk.ID[:])
 		}
-		chunk.Done()
+		d.collectUnknownChunk(chunk)
 	}
+	// skip what the parsers didn't read and the pad byte of odd sized chunks
+	chunk.Done()
 	return nil
 }
 
-// parseCommentsChunk processes the comments chunk and adds comments as strings
-// to the decoder and drains the chunk.
-func (d *Decoder) parseCommentsChunk(chunk *Chunk) error {
-	if chunk.ID != COMTID {
-		return fmt.Errorf("unexpected comments chunk ID: %q", chunk.ID)
+// collectUnknownChunk stores the content of a chunk the decoder can't parse
+// in UnknownChunks when CollectUnknownChunks is set.
+func (d *Decoder) collectUnknownChunk(chunk *Chunk) {
+	if !d.CollectUnknownChunks {
+		return
 	}
-
-	br := bytes.NewBuffer(make([]byte, 0, chunk.Size))
-	var n int64
-	n, d.err = io.CopyN(br, d.r, int64(chunk.Size))
-	if d.err != nil {
-		return d.err
+	data, err := chunk.Bytes()
+	if err != nil {
+		d.logf("failed to read the %q chunk (ignored) - %v", chunk.ID[:], err)
+		return
 	}
-	if n < int64(chunk.Size) {
-		br.Truncate(int(n))
+	if d.UnknownChunks == nil {
+		d.UnknownChunks = map[[4]byte][][]byte{}
 	}
+	d.UnknownChunks[chunk.ID] = append(d.UnknownChunks[chunk.ID], data)
+}
 
-	var nbrComments uint16
-	binary.Read(br, binary.BigEndian, &nbrComments)
-	for i := 0; i < int(nbrComments); i++ {
-		// TODO extract marker id and timestamp
-		io.CopyN(ioutil.Discard, br, 8) // equivalent of br.Seek(8, io.SeekCurrent) but bytes buffer doesn't implement seek
-		b, _ := br.ReadByte()
-		textB := make([]byte, int(b))
-		br.Read(textB)
-		d.Comments = append(d.Comments, string(bytes.TrimRight(textB, "\x00")))
+// isMetadataChunk reports whether the chunk only holds metadata, not
+// required to decode the audio.
+func isMetadataChunk(id [4]byte) bool {
+	switch id {
+	case COMTID, markID, nameID, authID, copyrightID, annoID, id3ID, applID,
+		bascID, cateID, trnsID, chanID, instID:
+		return true
 	}
-
-	return nil
+	return false
 }
 
 // parseBascChunk processes the Apple specific BASC chunk
@@ -128,15 +164,14 @@ func (d *Decoder) parseBascChunk(chunk *Chunk) error {
 	d.HasAppleInfo = true
 
 	var version uint32
-	binary.Read(chunk.R, binary.BigEndian, &version)
-	binary.Read(chunk.R, binary.BigEndian, &d.AppleInfo.Beats)
-	binary.Read(chunk.R, binary.BigEndian, &d.AppleInfo.Note)
-	binary.Read(chunk.R, binary.BigEndian, &d.AppleInfo.Scale)
-	binary.Read(chunk.R, binary.BigEndian, &d.AppleInfo.Numerator)
-	binary.Read(chunk.R, binary.BigEndian, &d.AppleInfo.Denominator)
-	chunk.ReadByte()
+	binary.Read(chunk, binary.BigEndian, &version)
+	binary.Read(chunk, binary.BigEndian, &d.AppleInfo.Beats)
+	binary.Read(chunk, binary.BigEndian, &d.AppleInfo.Note)
+	binary.Read(chunk, binary.BigEndian, &d.AppleInfo.Scale)
+	binary.Read(chunk, binary.BigEndian, &d.AppleInfo.Numerator)
+	binary.Read(chunk, binary.BigEndian, &d.AppleInfo.Denominator)
 	var loopFlag uint16
-	binary.Read(chunk.R, binary.BigEndian, &loopFlag)
+	binary.Read(chunk, binary.BigEndian, &loopFlag)
 	// 1  = loop; 2 = one shot
 	if loopFlag == 1 {
 		d.AppleInfo.IsLooping = true
@@ -154,36 +189,36 @@ func (d *Decoder) parseCateChunk(chunk *Chunk) error {
 
 	// skip 4
 	tmp := make([]byte, 4)
-	if _, err = chunk.R.Read(tmp); err != nil {
+	if _, err = chunk.Read(tmp); err != nil {
 		return err
 	}
 
 	tmp = make([]byte, 50)
 	// 4 main categories: instrument, instrument category, style, substyle
 	for i := 0; i < 4; i++ {
-		if _, err = chunk.R.Read(tmp); err != nil {
+		if _, err = chunk.Read(tmp); err != nil {
 			return err
 		}
 		if tmp[0] > 0 {
-			d.AppleInfo.Tags = append(d.AppleInfo.Tags, nullTermStr(tmp))
+			d.AppleInfo.Tags = append(d.AppleInfo.Tags, d.decodeText(tmp[:clen(tmp)]))
 		}
 	}
 
 	// skip 16
 	tmp = make([]byte, 16)
-	if _, err = chunk.R.Read(tmp); err != nil {
+	if _, err = chunk.Read(tmp); err != nil {
 		return err
 	}
 
 	var numDescriptors int16
-	binary.Read(chunk.R, binary.BigEndian, &numDescriptors)
+	binary.Read(chunk, binary.BigEndian, &numDescriptors)
 	tmp = make([]byte, 50)
 	for i := 0; i < int(numDescriptors); i++ {
-		if _, err = chunk.R.Read(tmp); err != nil {
+		if _, err = chunk.Read(tmp); err != nil {
 			return err
 		}
 		if tmp[0] > 0 {
-			d.AppleInfo.Tags = append(d.AppleInfo.Tags, nullTermStr(tmp))
+			d.AppleInfo.Tags = append(d.AppleInfo.Tags, d.decodeText(tmp[:clen(tmp)]))
 		}
 	}
 
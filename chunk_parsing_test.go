@@ -86,3 +86,149 @@ func TestAppleInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestChunkIterator(t *testing.T) {
+	f, err := os.Open("fixtures/ring.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+
+	expected := []struct {
+		id     string
+		offset int64
+	}{
+		{"COMT", 12}, {"COMM", 430}, {"CHAN", 456}, {"SSND", 496},
+		{"MARK", 352768}, {"basc", 352824}, {"trns", 352914},
+		{"cate", 353334}, {"LGWV", 353614},
+	}
+	it := d.Chunks()
+	var i int
+	for it.Next() {
+		c := it.Chunk()
+		if i >= len(expected) {
+			t.Fatalf("unexpected chunk %q", c.ID)
+		}
+		if string(c.ID[:]) != expected[i].id || c.Offset() != expected[i].offset {
+			t.Fatalf("expected chunk %d to be %s at %d but got %s at %d", i, expected[i].id, expected[i].offset, c.ID, c.Offset())
+		}
+		// partially consume some chunks
+		if i%2 == 0 {
+			c.ReadByte()
+		}
+		i++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != len(expected) {
+		t.Fatalf("expected %d chunks but got %d", len(expected), i)
+	}
+}
+
+func TestDecoder_OnChunk(t *testing.T) {
+	f, err := os.Open("fixtures/sowt.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+
+	var instSize, markSize int
+	var numMarkers uint16
+	d.OnChunk([4]byte{'I', 'N', 'S', 'T'}, func(c *Chunk) error {
+		instSize = c.Size
+		return nil
+	})
+	d.OnChunk([4]byte{'M', 'A', 'R', 'K'}, func(c *Chunk) error {
+		markSize = c.Size
+		// partially read the chunk, the decoder takes care of the rest
+		return c.ReadBE(&numMarkers)
+	})
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if instSize != 20 {
+		t.Fatalf("expected the INST handler to be called with a 20 byte chunk, got %d", instSize)
+	}
+	if markSize != 18 || numMarkers != 2 {
+		t.Fatalf("expected the MARK handler to read 2 markers in a 18 byte chunk, got %d in %d bytes", numMarkers, markSize)
+	}
+}
+
+func TestDecoder_SkipMetadata(t *testing.T) {
+	f, err := os.Open("fixtures/ring.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	d.SkipMetadata = true
+	if !d.IsValidFile() {
+		t.Fatal("expected the file to be valid")
+	}
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Comments) > 0 || len(d.Markers) > 0 || d.HasAppleInfo {
+		t.Fatalf("expected the metadata to be skipped, got %v, %v, %v", d.Comments, d.Markers, d.HasAppleInfo)
+	}
+	if d.NumSampleFrames != 88064 || d.SampleRate != 44100 {
+		t.Fatalf("expected the format to be parsed, got %d frames @ %d", d.NumSampleFrames, d.SampleRate)
+	}
+
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d = NewDecoder(f)
+	d.SkipMetadata = true
+	var called bool
+	d.OnChunk(bascID, func(c *Chunk) error {
+		called = true
+		return nil
+	})
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Fatal("expected the custom chunk handler to be called")
+	}
+}
+
+func TestDecoder_CollectUnknownChunks(t *testing.T) {
+	f, err := os.Open("fixtures/ring.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if d.UnknownChunks != nil {
+		t.Fatalf("expected the unknown chunks not to be collected by default, got %d", len(d.UnknownChunks))
+	}
+
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d = NewDecoder(f)
+	d.CollectUnknownChunks = true
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[[4]byte]int{trnsID: 412, {'L', 'G', 'W', 'V'}: 696}
+	if len(d.UnknownChunks) != len(expected) {
+		t.Fatalf("expected %d unknown chunks but got %d", len(expected), len(d.UnknownChunks))
+	}
+	for id, size := range expected {
+		chunks := d.UnknownChunks[id]
+		if len(chunks) != 1 || len(chunks[0]) != size {
+			t.Fatalf("expected a single %d byte %q chunk but got %d chunks", size, id[:], len(chunks))
+		}
+	}
+	if !d.HasAppleInfo || d.AppleInfo.Beats == 0 {
+		t.Fatal("expected the known Apple chunks to still be parsed")
+	}
+}
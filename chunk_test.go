@@ -0,0 +1,170 @@
+package aiff
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestChunk_Toolkit(t *testing.T) {
+	// odd sized chunk followed by its pad byte and the next chunk
+	newChunk := func() (*Chunk, *bytes.Reader) {
+		r := bytes.NewReader([]byte("hello\x00NEXT"))
+		return &Chunk{ID: [4]byte{'T', 'E', 'S', 'T'}, Size: 5, R: r, pad: 1}, r
+	}
+	next := func(t *testing.T, r *bytes.Reader) {
+		t.Helper()
+		rest, _ := ioutil.ReadAll(r)
+		if string(rest) != "NEXT" {
+			t.Fatalf("expected the reader to be on the next chunk but got %q", rest)
+		}
+	}
+
+	t.Run("Remaining and Skip", func(t *testing.T) {
+		ch, r := newChunk()
+		if ch.Remaining() != 5 {
+			t.Fatalf("expected 5 remaining bytes but got %d", ch.Remaining())
+		}
+		if err := ch.Skip(2); err != nil {
+			t.Fatal(err)
+		}
+		if ch.Remaining() != 3 {
+			t.Fatalf("expected 3 remaining bytes but got %d", ch.Remaining())
+		}
+		if err := ch.Skip(4); err == nil {
+			t.Fatal("expected an error skipping past the end of the data")
+		}
+		if err := ch.Skip(-1); err == nil {
+			t.Fatal("expected an error skipping a negative number of bytes")
+		}
+		b, err := ch.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "llo" {
+			t.Fatalf("expected the rest of the data to be %q but got %q", "llo", b)
+		}
+		next(t, r)
+	})
+
+	t.Run("Read stops at the pad byte", func(t *testing.T) {
+		ch, r := newChunk()
+		b, err := ioutil.ReadAll(ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "hello" {
+			t.Fatalf("expected %q but got %q", "hello", b)
+		}
+		if !ch.IsFullyRead() || ch.Remaining() != 0 {
+			t.Fatal("expected the chunk to be fully read")
+		}
+		if err := ch.Drain(); err != nil {
+			t.Fatal(err)
+		}
+		next(t, r)
+	})
+
+	t.Run("Drain", func(t *testing.T) {
+		ch, r := newChunk()
+		if err := ch.Drain(); err != nil {
+			t.Fatal(err)
+		}
+		// draining twice doesn't consume the next chunk
+		if err := ch.Drain(); err != nil {
+			t.Fatal(err)
+		}
+		next(t, r)
+	})
+
+	t.Run("missing pad byte at the end of the file", func(t *testing.T) {
+		ch := &Chunk{Size: 5, R: bytes.NewReader([]byte("hello")), pad: 1}
+		if err := ch.Drain(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("nil chunk", func(t *testing.T) {
+		var ch *Chunk
+		if ch.Remaining() != 0 {
+			t.Fatal("expected a nil chunk to be empty")
+		}
+		if _, err := ch.Bytes(); err == nil {
+			t.Fatal("expected an error reading a nil chunk")
+		}
+		if err := ch.Skip(1); err == nil {
+			t.Fatal("expected an error skipping bytes of a nil chunk")
+		}
+	})
+}
+
+func TestDecoder_NextChunkPadding(t *testing.T) {
+	f, err := os.Open("fixtures/sowt2.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	if err := d.readHeaders(); err != nil {
+		t.Fatal(err)
+	}
+
+	// most chunks of this file have an odd size and are followed by a pad byte
+	expected := []struct {
+		id   string
+		size int
+	}{
+		{"COMM", 24}, {"(c) ", 25}, {"COMT", 35}, {"AFmd", 381}, {"SSND", 666716}, {"AFAn", 16183},
+	}
+	for i, exp := range expected {
+		c, err := d.NextChunk()
+		if err != nil {
+			t.Fatalf("chunk %d - %v", i, err)
+		}
+		if string(c.ID[:]) != exp.id || c.Size != exp.size {
+			t.Fatalf("expected chunk %d to be %s (%d bytes) but got %s (%d bytes)", i, exp.id, exp.size, c.ID, c.Size)
+		}
+		b, err := c.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b) != exp.size {
+			t.Fatalf("expected to read %d bytes from %s but got %d", exp.size, exp.id, len(b))
+		}
+	}
+	if _, err := d.NextChunk(); err != io.EOF {
+		t.Fatalf("expected EOF after the last chunk but got %v", err)
+	}
+}
+
+func TestChunk_Positions(t *testing.T) {
+	f, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	expected := []struct {
+		id     string
+		offset int64
+	}{{"COMM", 12}, {"SSND", 38}, {"AFAn", 9022}}
+	for _, exp := range expected {
+		ch, err := d.NextChunk()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(ch.ID[:]) != exp.id || ch.Offset() != exp.offset || ch.DataOffset() != exp.offset+8 {
+			t.Fatalf("expected the %s chunk @%d but got %s @%d (data @%d)", exp.id, exp.offset, ch.ID[:], ch.Offset(), ch.DataOffset())
+		}
+		var v uint16
+		if err := ch.ReadBE(&v); err != nil {
+			t.Fatal(err)
+		}
+		if ch.AbsPos() != exp.offset+10 {
+			t.Fatalf("expected the %s chunk to be read up to %d but got %d", exp.id, exp.offset+10, ch.AbsPos())
+		}
+		ch.Done()
+	}
+}
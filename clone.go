@@ -0,0 +1,88 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// CloneAt returns a new decoder sharing the parsed information of d but
+// reading independently from the underlying io.ReaderAt, starting at the
+// given frame. Clones don't share any read state so they can be used
+// concurrently, for instance to serve overlapping range requests of the same
+// file. The underlying reader passed to NewDecoder must implement io.ReaderAt
+// (os.File and bytes.Reader do). Compressed data (see RegisterCodec) can only
+// be cloned at frame 0.
+// d is forwarded to the PCM data if needed, CloneAt itself isn't safe to call
+// concurrently with other calls on d.
+func (d *Decoder) CloneAt(offsetFrame int64) (*Decoder, error) {
+	if d == nil {
+		return nil, errors.New("can't clone a nil decoder")
+	}
+	ra, ok := d.r.(io.ReaderAt)
+	if !ok {
+		return nil, errors.New("the underlying reader doesn't implement io.ReaderAt")
+	}
+	start, length, err := d.PCMOffset()
+	if err != nil {
+		return nil, err
+	}
+	// the position of a frame in compressed data can't be computed
+	if _, ok := lookupCodec(d.Encoding); ok && offsetFrame != 0 {
+		return nil, fmt.Errorf("%w - can't clone %q encoded data at frame %d", ErrFmtNotSupported, d.Encoding, offsetFrame)
+	}
+	frameSize := int64(bytesPerSample(int(d.BitDepth)) * int(d.NumChans))
+	if frameSize < 1 {
+		return nil, fmt.Errorf("%s - %d channels @ %d bits", ErrFmtNotSupported, d.NumChans, d.BitDepth)
+	}
+	offset := offsetFrame * frameSize
+	if offsetFrame < 0 || offset > length {
+		return nil, fmt.Errorf("frame %d out of range [0, %d]", offsetFrame, length/frameSize)
+	}
+
+	c := *d
+	sr := io.NewSectionReader(ra, 0, math.MaxInt64)
+	c.r, c.ra = sr, sr
+	if _, err := c.r.Seek(start+offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	c.PCMChunk = &Chunk{
+		ID:   SSNDID,
+		Size: int(length - offset),
+		R:    io.LimitReader(c.r, length-offset),
+	}
+	c.pcmEnd = int(length - offset)
+	c.pendingSamples = nil
+	// the codec decoder of d reads the PCM chunk of d, the clone creates its
+	// own
+	c.sampleDecoder = nil
+	c.meterPCM(c.PCMChunk)
+	c.parsedChunks = map[int64]bool{}
+	for offset := range d.parsedChunks {
+		c.parsedChunks[offset] = true
+	}
+	c.Comments = append([]string(nil), d.Comments...)
+	c.CommentEntries = append([]Comment(nil), d.CommentEntries...)
+	c.Markers = append([]Marker(nil), d.Markers...)
+	if d.Instrument != nil {
+		inst := *d.Instrument
+		c.Instrument = &inst
+	}
+	c.Annotations = append([]string(nil), d.Annotations...)
+	c.Resyncs = append([]Resync(nil), d.Resyncs...)
+	if d.ID3 != nil {
+		c.ID3 = make(map[string]string, len(d.ID3))
+		for id, v := range d.ID3 {
+			c.ID3[id] = v
+		}
+	}
+	c.AppleInfo.Tags = append([]string(nil), d.AppleInfo.Tags...)
+	if d.UnknownChunks != nil {
+		c.UnknownChunks = make(map[[4]byte][][]byte, len(d.UnknownChunks))
+		for id, chunks := range d.UnknownChunks {
+			c.UnknownChunks[id] = append([][]byte(nil), chunks...)
+		}
+	}
+	return &c, nil
+}
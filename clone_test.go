@@ -0,0 +1,106 @@
+package aiff
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestDecoder_CloneAt(t *testing.T) {
+	f, err := os.Open("fixtures/bloop.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	expected, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	numChans := expected.Format.NumChannels
+
+	offsets := []int64{0, 10, 1000, int64(expected.NumFrames()) - 1, int64(expected.NumFrames())}
+	var wg sync.WaitGroup
+	errs := make(chan error, len(offsets))
+	for _, offset := range offsets {
+		c, err := d.CloneAt(offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func(offset int64, c *Decoder) {
+			defer wg.Done()
+			buf, err := c.FullPCMBuffer()
+			if err != nil {
+				errs <- err
+				return
+			}
+			exp := expected.Data[int(offset)*numChans:]
+			if len(buf.Data) != len(exp) {
+				t.Errorf("clone at %d: expected %d samples but got %d", offset, len(exp), len(buf.Data))
+				return
+			}
+			for i, v := range exp {
+				if buf.Data[i] != v {
+					t.Errorf("clone at %d: sample %d didn't match, expected %d, got %d", offset, i, v, buf.Data[i])
+					return
+				}
+			}
+		}(offset, c)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	if _, err := d.CloneAt(int64(expected.NumFrames()) + 1); err == nil {
+		t.Fatal("expected an error when cloning past the end of the data")
+	}
+	c, err := d.CloneAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &audio.IntBuffer{Data: make([]int, 10)}
+	if _, err := c.PCMBuffer(buf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDecoder_CloneAt_codec(t *testing.T) {
+	f, err := os.Open(ulawTestFile(t, []int{1000, -1000, 8000, -8000}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	// the original reads its first 2 samples before being cloned
+	buf := &audio.IntBuffer{Data: make([]int, 2)}
+	if _, err := d.PCMBuffer(buf); err != nil {
+		t.Fatal(err)
+	}
+	c, err := d.CloneAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cloned, err := c.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []int{988, -988, 7932, -7932}
+	if !reflect.DeepEqual(cloned.Data, expected) {
+		t.Fatalf("expected the clone to decode %v but got %v", expected, cloned.Data)
+	}
+	// the original isn't affected by the clone
+	if n, err := d.PCMBuffer(buf); err != nil || !reflect.DeepEqual(buf.Data[:n], expected[2:]) {
+		t.Fatalf("expected the original to decode %v but got %v, %v", expected[2:], buf.Data[:n], err)
+	}
+
+	if _, err := d.CloneAt(1); !errors.Is(err, ErrFmtNotSupported) {
+		t.Fatalf("expected ErrFmtNotSupported cloning compressed data at frame 1 but got %v", err)
+	}
+}
@@ -11,9 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/go-audio/aiff"
-	"github.com/go-audio/audio"
-	"github.com/go-audio/wav"
+	"github.com/go-audio/aiff/transcode"
 )
 
 var (
@@ -45,12 +43,6 @@ func main() {
 	}
 	defer f.Close()
 
-	d := aiff.NewDecoder(f)
-	if !d.IsValidFile() {
-		fmt.Println("invalid AIFF file")
-		os.Exit(1)
-	}
-
 	outPath := sourcePath[:len(sourcePath)-len(filepath.Ext(sourcePath))] + ".wav"
 	of, err := os.Create(outPath)
 	if err != nil {
@@ -59,34 +51,9 @@ func main() {
 	}
 	defer of.Close()
 
-	e := wav.NewEncoder(of, int(d.SampleRate), int(d.BitDepth), int(d.NumChans), 1)
-
-	format := &audio.Format{
-		NumChannels: int(d.NumChans),
-		SampleRate:  int(d.SampleRate),
-	}
-
-	bufferSize := 1000000
-	buf := &audio.IntBuffer{Data: make([]int, bufferSize), Format: format}
-	var n int
-	for err == nil {
-		n, err = d.PCMBuffer(buf)
-		if err != nil {
-			break
-		}
-		if n == 0 {
-			break
-		}
-		if n != len(buf.Data) {
-			buf.Data = buf.Data[:n]
-		}
-		if err := e.Write(buf); err != nil {
-			panic(err)
-		}
-	}
-
-	if err := e.Close(); err != nil {
-		panic(err)
+	if err := transcode.TranscodeToWAV(f, of); err != nil {
+		fmt.Println("Failed to convert", *flagPath, err)
+		os.Exit(1)
 	}
 	fmt.Printf("Aiff file converted to %s\n", outPath)
 }
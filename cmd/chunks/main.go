@@ -0,0 +1,104 @@
+// This tool lists the chunks of an aiff file or extracts the payload of one
+// of them, for instance to debug interoperability problems:
+//
+//	chunks -path file.aif
+//	chunks -path file.aif -id ID3 -out tags.bin
+//	chunks -path file.aif -id SSND -pcm-only -out audio.pcm
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-audio/aiff"
+)
+
+var (
+	flagPath    = flag.String("path", "", "The path to the aiff file")
+	flagID      = flag.String("id", "", "The ID of the chunk to extract (padded with spaces), the chunks are listed if not set")
+	flagOut     = flag.String("out", "", "The path of the file to write the chunk payload to, stdout is used if not set")
+	flagPCMOnly = flag.Bool("pcm-only", false, "Only extract the sound data of the SSND chunk, without its offset and block size header")
+)
+
+func main() {
+	flag.Parse()
+	if *flagPath == "" {
+		fmt.Println("You must set the -path flag")
+		os.Exit(1)
+	}
+	if len(*flagID) > 4 {
+		fmt.Println("Invalid chunk ID", *flagID)
+		os.Exit(1)
+	}
+	f, err := os.Open(*flagPath)
+	if err != nil {
+		fmt.Println("Invalid path", *flagPath, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	idx, err := aiff.NewDecoder(f).Index()
+	if idx == nil {
+		fmt.Println("Failed to index the chunks", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Partial index:", err)
+	}
+
+	if *flagID == "" {
+		for _, info := range idx.Chunks {
+			fmt.Printf("%q\toffset: %d\tsize: %d\n", info.ID[:], info.Offset, info.Size)
+		}
+		return
+	}
+
+	id := [4]byte{' ', ' ', ' ', ' '}
+	copy(id[:], *flagID)
+	if *flagPCMOnly && id != aiff.SSNDID {
+		fmt.Println("-pcm-only requires the SSND chunk")
+		os.Exit(1)
+	}
+	chunk, err := idx.OpenChunk(id)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	var w io.Writer = os.Stdout
+	if *flagOut != "" {
+		of, err := os.Create(*flagOut)
+		if err != nil {
+			fmt.Println("Failed to create", *flagOut, err)
+			os.Exit(1)
+		}
+		defer of.Close()
+		w = of
+	}
+
+	if *flagPCMOnly {
+		var offset, blockSize uint32
+		if err := chunk.ReadBE(&offset); err != nil {
+			fmt.Println("Failed to read the SSND offset", err)
+			os.Exit(1)
+		}
+		if err := chunk.ReadBE(&blockSize); err != nil {
+			fmt.Println("Failed to read the SSND block size", err)
+			os.Exit(1)
+		}
+		if err := chunk.Skip(int(offset)); err != nil {
+			fmt.Println("Failed to skip the SSND offset", err)
+			os.Exit(1)
+		}
+	}
+	n, err := io.Copy(w, chunk)
+	if err != nil {
+		fmt.Println("Failed to extract the chunk", err)
+		os.Exit(1)
+	}
+	if *flagOut != "" {
+		fmt.Printf("%d bytes written to %s\n", n, *flagOut)
+	}
+}
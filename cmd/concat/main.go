@@ -0,0 +1,50 @@
+// This tool concatenates aiff files sharing the same format into a single
+// file without decoding the sound data:
+//
+//	concat -out all.aif intro.aif loop.aif outro.aif
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-audio/aiff"
+)
+
+var (
+	flagOut = flag.String("out", "", "The path of the concatenated file")
+)
+
+func main() {
+	flag.Parse()
+	if *flagOut == "" || flag.NArg() == 0 {
+		fmt.Println("Usage: concat -out <path> <input> [<input>...]")
+		os.Exit(1)
+	}
+
+	inputs := make([]io.ReadSeeker, 0, flag.NArg())
+	for _, path := range flag.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Println("Invalid path", path, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		inputs = append(inputs, f)
+	}
+
+	of, err := os.Create(*flagOut)
+	if err != nil {
+		fmt.Println("Failed to create", *flagOut, err)
+		os.Exit(1)
+	}
+	defer of.Close()
+
+	if err := aiff.Concat(of, inputs...); err != nil {
+		fmt.Println("Failed to concatenate the files", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%d files concatenated to %s\n", len(inputs), *flagOut)
+}
@@ -1,35 +1,101 @@
+// This tool prints information about an aiff file.
+//
+// With -validate nothing is printed and the exit code tells whether the file
+// can be decoded:
+//
+//	0 valid file
+//	1 usage error or unreadable file
+//	2 not an AIFF/AIFC file
+//	3 corrupt file
+//	4 unsupported encoding or bit depth
 package main
 
 import (
 	"flag"
 	"fmt"
 	"os"
+	"text/template"
 
 	"github.com/go-audio/aiff"
 )
 
+// exit codes of the -validate mode
+const (
+	exitInvalidUsage = 1
+	exitNotAIFF      = 2
+	exitCorrupt      = 3
+	exitUnsupported  = 4
+)
+
 var (
-	flagPath = flag.String("path", "", "The path to the file to analyze")
+	flagPath     = flag.String("path", "", "The path to the file to analyze")
+	flagValidate = flag.Bool("validate", false, "Only validate the file, nothing is printed and the exit code reports the result")
+	flagFormat   = flag.String("format", "", `Go template executed against the decoder instead of printing the report, e.g. "{{.Duration}} {{.SampleRate}} {{.NumSampleFrames}}"`)
 )
 
 func main() {
 	flag.Parse()
 	if *flagPath == "" {
 		fmt.Println("You must set the -path flag")
-		os.Exit(1)
+		os.Exit(exitInvalidUsage)
+	}
+	var tmpl *template.Template
+	if *flagFormat != "" {
+		var err error
+		if tmpl, err = template.New("format").Parse(*flagFormat); err != nil {
+			fmt.Println("Invalid format", err)
+			os.Exit(1)
+		}
 	}
 	f, err := os.Open(*flagPath)
 	if err != nil {
-		fmt.Println("Invalid path", *flagPath, err)
-		os.Exit(1)
+		if !*flagValidate {
+			fmt.Println("Invalid path", *flagPath, err)
+		}
+		os.Exit(exitInvalidUsage)
 	}
 	defer f.Close()
 
 	d := aiff.NewDecoder(f)
+	if *flagValidate {
+		os.Exit(validate(d))
+	}
 	if !d.IsValidFile() {
 		fmt.Println("invalid AIFF file")
 		os.Exit(1)
 	}
 	d.Drain()
-	fmt.Println(d)
+	if tmpl == nil {
+		fmt.Print(d.Report())
+		return
+	}
+	if err := tmpl.Execute(os.Stdout, d); err != nil {
+		fmt.Println("\nFailed to format", err)
+		os.Exit(1)
+	}
+	fmt.Println()
+}
+
+// validate returns the exit code matching the most serious issue found in
+// the file.
+func validate(d *aiff.Decoder) int {
+	code := 0
+	for _, issue := range d.Validate() {
+		if issue.Severity != aiff.SeverityError {
+			continue
+		}
+		switch issue.Check {
+		case "reader":
+			return exitInvalidUsage
+		case "header":
+			return exitNotAIFF
+		case "encoding", "bit-depth":
+			if code == 0 {
+				code = exitUnsupported
+			}
+		default:
+			code = exitCorrupt
+		}
+	}
+	return code
 }
@@ -0,0 +1,133 @@
+// This tool cuts an aiff file into segments without decoding the sound data.
+// The cut points are sample accurate and can be set as timestamps, frame
+// positions or a maximum segment duration:
+//
+//	split -path file.aif -at 00:01:23.456,00:02:00
+//	split -path file.aif -frames 44100,88200
+//	split -path file.aif -every 30s
+//
+// The segments are written next to the source as <name>-001.aif...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-audio/aiff"
+)
+
+var (
+	flagPath   = flag.String("path", "", "The path to the aiff file to split")
+	flagAt     = flag.String("at", "", "Comma separated list of cut points as [[hh:]mm:]ss[.fff] timestamps")
+	flagFrames = flag.String("frames", "", "Comma separated list of cut points as frame positions")
+	flagEvery  = flag.Duration("every", 0, "Maximum duration of the segments, e.g. 30s")
+)
+
+func main() {
+	flag.Parse()
+	if *flagPath == "" {
+		fmt.Println("You must set the -path flag")
+		os.Exit(1)
+	}
+	set := 0
+	for _, ok := range []bool{*flagAt != "", *flagFrames != "", *flagEvery > 0} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		fmt.Println("You must set one of the -at, -frames or -every flags")
+		os.Exit(1)
+	}
+
+	f, err := os.Open(*flagPath)
+	if err != nil {
+		fmt.Println("Invalid path", *flagPath, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	ext := filepath.Ext(*flagPath)
+	base := (*flagPath)[:len(*flagPath)-len(ext)]
+	var files []*os.File
+	emit := func(i int) io.WriteSeeker {
+		path := fmt.Sprintf("%s-%03d%s", base, i+1, ext)
+		of, err := os.Create(path)
+		if err != nil {
+			fmt.Println("Failed to create", path, err)
+			return nil
+		}
+		files = append(files, of)
+		return of
+	}
+	defer func() {
+		for _, of := range files {
+			of.Close()
+		}
+	}()
+
+	switch {
+	case *flagEvery > 0:
+		err = aiff.Split(f, *flagEvery, emit)
+	case *flagFrames != "":
+		var cuts []int64
+		for _, s := range strings.Split(*flagFrames, ",") {
+			cut, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+			if err != nil {
+				fmt.Println("Invalid frame position", s)
+				os.Exit(1)
+			}
+			cuts = append(cuts, cut)
+		}
+		err = aiff.SplitAt(f, cuts, emit)
+	default:
+		d := aiff.NewDecoder(f)
+		if err := d.ReadInfo(); err != nil || d.SampleRate == 0 {
+			fmt.Println("Failed to read the file information", err)
+			os.Exit(1)
+		}
+		var cuts []int64
+		for _, s := range strings.Split(*flagAt, ",") {
+			secs, err := parseTimestamp(strings.TrimSpace(s))
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			cuts = append(cuts, int64(math.Round(secs*d.ExactSampleRate())))
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			fmt.Println("Failed to rewind", *flagPath, err)
+			os.Exit(1)
+		}
+		err = aiff.SplitAt(f, cuts, emit)
+	}
+	if err != nil {
+		fmt.Println("Failed to split", *flagPath, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%d segments written\n", len(files))
+}
+
+// parseTimestamp converts a [[hh:]mm:]ss[.fff] timestamp to seconds.
+func parseTimestamp(s string) (float64, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	var secs float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(part, 64)
+		// only the seconds can have a fractional part
+		if err != nil || v < 0 || (i < len(parts)-1 && v != math.Trunc(v)) {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		secs = secs*60 + v
+	}
+	return secs, nil
+}
@@ -0,0 +1,65 @@
+// This tool copies an aiff file without its metadata (comments, markers,
+// Apple and ID3 tags...) so it can be published without leaking personal
+// information. Only the COMM, SSND and FVER chunks are kept by default.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-audio/aiff"
+)
+
+var (
+	flagPath = flag.String("path", "", "The path to the aiff file to strip")
+	flagOut  = flag.String("out", "", "The path of the stripped file, defaults to the source path with a -stripped suffix")
+	flagKeep = flag.String("keep", "", "Comma separated list of extra chunk IDs to keep, e.g. MARK,INST")
+)
+
+func main() {
+	flag.Parse()
+	if *flagPath == "" {
+		fmt.Println("You must set the -path flag")
+		os.Exit(1)
+	}
+	var keep [][4]byte
+	if *flagKeep != "" {
+		for _, s := range strings.Split(*flagKeep, ",") {
+			if len(s) == 0 || len(s) > 4 {
+				fmt.Println("Invalid chunk ID", s)
+				os.Exit(1)
+			}
+			id := [4]byte{' ', ' ', ' ', ' '}
+			copy(id[:], s)
+			keep = append(keep, id)
+		}
+	}
+
+	f, err := os.Open(*flagPath)
+	if err != nil {
+		fmt.Println("Invalid path", *flagPath, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	outPath := *flagOut
+	if outPath == "" {
+		ext := filepath.Ext(*flagPath)
+		outPath = (*flagPath)[:len(*flagPath)-len(ext)] + "-stripped" + ext
+	}
+	of, err := os.Create(outPath)
+	if err != nil {
+		fmt.Println("Failed to create", outPath, err)
+		os.Exit(1)
+	}
+	defer of.Close()
+
+	if err := aiff.StripMetadata(f, of, keep...); err != nil {
+		fmt.Println("Failed to strip", *flagPath, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Stripped file written to %s\n", outPath)
+}
@@ -0,0 +1,100 @@
+package aiff
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/go-audio/audio"
+)
+
+// Codec encodes and decodes the sound data of an AIFC encoding.
+// Codecs are registered using RegisterCodec and are used by the Decoder and
+// the Encoder when the encoding matches the ID they were registered with.
+type Codec interface {
+	// NewSampleDecoder returns a decoder reading the encoded sound data from r.
+	NewSampleDecoder(r io.Reader, numChans, bitDepth int) (SampleDecoder, error)
+	// NewSampleEncoder returns an encoder writing the encoded sound data to w.
+	NewSampleEncoder(w io.Writer, numChans, bitDepth int) (SampleEncoder, error)
+}
+
+// SampleDecoder decodes a stream of encoded samples.
+type SampleDecoder interface {
+	// DecodeSamples decodes up to len(buf) interleaved samples into buf and
+	// returns the number of decoded samples. io.EOF is returned when no more
+	// samples are available.
+	DecodeSamples(buf []int) (n int, err error)
+}
+
+// SampleEncoder encodes a stream of samples.
+type SampleEncoder interface {
+	// EncodeSamples encodes the passed interleaved samples.
+	EncodeSamples(samples []int) error
+	// Flush writes the data potentially buffered by the encoder.
+	Flush() error
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[Encoding]Codec{}
+)
+
+// RegisterCodec registers a codec for the passed AIFC encoding ID,
+// replacing any codec previously registered for it. Passing a nil codec
+// unregisters the encoding.
+func RegisterCodec(id Encoding, codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	if codec == nil {
+		delete(codecs, id)
+		return
+	}
+	codecs[id] = codec
+}
+
+// lookupCodec returns the codec registered for the passed encoding if any.
+func lookupCodec(id Encoding) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	c, ok := codecs[id]
+	return c, ok
+}
+
+// codecPCMBuffer populates the passed buffer using the codec of the encoding.
+func (d *Decoder) codecPCMBuffer(codec Codec, buf *audio.IntBuffer) (int, error) {
+	if d.sampleDecoder == nil {
+		var err error
+		d.sampleDecoder, err = codec.NewSampleDecoder(d.PCMChunk, int(d.NumChans), int(d.BitDepth))
+		if err != nil {
+			return 0, fmt.Errorf("failed to create the %q sample decoder - %v", d.Encoding, err)
+		}
+	}
+	buf.Format = d.Format()
+	buf.SourceBitDepth = int(d.BitDepth)
+	n, err := d.sampleDecoder.DecodeSamples(buf.Data)
+	if err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+// fullCodecPCMBuffer decodes all the sound data using the codec of the encoding.
+func (d *Decoder) fullCodecPCMBuffer(codec Codec, maxSamples int) (*audio.IntBuffer, error) {
+	out := &audio.IntBuffer{Format: d.Format(), SourceBitDepth: int(d.BitDepth)}
+	buf := &audio.IntBuffer{Data: make([]int, 4096)}
+	for {
+		n, err := d.codecPCMBuffer(codec, buf)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			break
+		}
+		// the number of frames declared by the COMM chunk can't be trusted
+		if maxSamples > 0 && len(out.Data)+n > maxSamples {
+			return nil, fmt.Errorf("%w - the limit is %d", ErrTooManySamples, maxSamples)
+		}
+		out.Data = append(out.Data, buf.Data[:n]...)
+	}
+	return out, nil
+}
@@ -0,0 +1,147 @@
+package aiff
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+// deltaCodec stores each sample as the int8 difference with the previous
+// sample of the same channel. It is lossy and only meant for testing.
+type deltaCodec struct{}
+
+func (deltaCodec) NewSampleDecoder(r io.Reader, numChans, bitDepth int) (SampleDecoder, error) {
+	return &deltaDecoder{r: bufio.NewReader(r), prev: make([]int, numChans)}, nil
+}
+
+func (deltaCodec) NewSampleEncoder(w io.Writer, numChans, bitDepth int) (SampleEncoder, error) {
+	return &deltaEncoder{w: bufio.NewWriter(w), prev: make([]int, numChans)}, nil
+}
+
+type deltaDecoder struct {
+	r    *bufio.Reader
+	prev []int
+	ch   int
+}
+
+func (d *deltaDecoder) DecodeSamples(buf []int) (int, error) {
+	for i := range buf {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return i, err
+		}
+		d.prev[d.ch] += int(int8(b))
+		buf[i] = d.prev[d.ch]
+		d.ch = (d.ch + 1) % len(d.prev)
+	}
+	return len(buf), nil
+}
+
+type deltaEncoder struct {
+	w    *bufio.Writer
+	prev []int
+	ch   int
+}
+
+func (e *deltaEncoder) EncodeSamples(samples []int) error {
+	for _, v := range samples {
+		delta := v - e.prev[e.ch]
+		if delta > 127 {
+			delta = 127
+		} else if delta < -128 {
+			delta = -128
+		}
+		e.prev[e.ch] += delta
+		if err := e.w.WriteByte(byte(int8(delta))); err != nil {
+			return err
+		}
+		e.ch = (e.ch + 1) % len(e.prev)
+	}
+	return nil
+}
+
+func (e *deltaEncoder) Flush() error {
+	return e.w.Flush()
+}
+
+func TestRegisterCodec(t *testing.T) {
+	id := [4]byte{'d', 'l', 't', 'a'}
+	RegisterCodec(id, deltaCodec{})
+	defer RegisterCodec(id, nil)
+
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/codec.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	// the codec stores a byte per sample, 7 samples make a 15 bytes SSND
+	// chunk (with the offset and block size fields) followed by a pad byte
+	samples := []int{0, 10, 20, -30, 100, 400, -5}
+	e := NewEncoder(out, 22050, 16, 1)
+	e.Encoding = id
+	if err := e.Write(&audio.IntBuffer{Data: samples, Format: &audio.Format{NumChannels: 1, SampleRate: 22050}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(out)
+	idx, err := d.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ssnd := idx.Chunks[idx.Find(SSNDID)]
+	info, err := out.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ssnd.Size != 15 || info.Size() != ssnd.Offset+8+16 {
+		t.Fatalf("expected a 15 bytes SSND chunk ending the file with a pad byte, got %d bytes in a %d bytes file",
+			ssnd.Size, info.Size())
+	}
+	if !d.IsValidFile() {
+		t.Fatal("expected the file encoded with a registered codec to be valid")
+	}
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d = NewDecoder(out)
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Encoding != id {
+		t.Fatalf("expected the %q encoding but got %q", id, d.Encoding)
+	}
+	if d.NumSampleFrames != uint32(len(samples)) {
+		t.Fatalf("expected %d frames but got %d", len(samples), d.NumSampleFrames)
+	}
+	// the deltas are clamped to 8 bits
+	expected := []int{0, 10, 20, -30, 97, 224, 96}
+	if len(buf.Data) != len(expected) {
+		t.Fatalf("expected %d samples but got %d", len(expected), len(buf.Data))
+	}
+	for i, v := range expected {
+		if buf.Data[i] != v {
+			t.Fatalf("expected sample %d to be %d but got %d", i, v, buf.Data[i])
+		}
+	}
+
+	RegisterCodec(id, nil)
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if NewDecoder(out).IsValidFile() {
+		t.Fatal("expected a file using an unregistered encoding to be invalid")
+	}
+}
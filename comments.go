@@ -0,0 +1,136 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// aiffEpoch is the origin of the AIFF timestamps, expressed in seconds.
+var aiffEpoch = time.Date(1904, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// aiffTime converts an AIFF timestamp to a time, the zero time is returned
+// for 0 which is used when the date isn't known.
+func aiffTime(ts uint32) time.Time {
+	if ts == 0 {
+		return time.Time{}
+	}
+	return aiffEpoch.Add(time.Duration(ts) * time.Second)
+}
+
+// aiffTimestamp converts a time to an AIFF timestamp, the times that can't
+// be represented (before 1904 or after 2040) are clamped.
+func aiffTimestamp(t time.Time) uint32 {
+	if t.IsZero() || t.Before(aiffEpoch) {
+		return 0
+	}
+	secs := t.Unix() - aiffEpoch.Unix()
+	if secs > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(secs)
+}
+
+// Comment is an entry of a COMT chunk.
+type Comment struct {
+	// Time is the creation date of the comment (stored in seconds since
+	// January 1, 1904 UTC), the zero time if it isn't known.
+	Time time.Time
+	// MarkerID is the ID of the marker the comment is attached to, 0 if
+	// none.
+	MarkerID int16
+	// Text of the comment, it isn't limited to 255 characters.
+	Text string
+}
+
+// parseCommentsChunk processes the comments chunk and adds comments as strings
+// to the decoder. The comments of all the COMT chunks are collected.
+func (d *Decoder) parseCommentsChunk(chunk *Chunk) error {
+	if chunk.ID != COMTID {
+		return fmt.Errorf("unexpected comments chunk ID: %q", chunk.ID)
+	}
+
+	br := bytes.NewBuffer(make([]byte, 0, chunk.Size))
+	var n int64
+	n, d.err = io.CopyN(br, chunk, int64(chunk.Size))
+	if d.err != nil {
+		return d.err
+	}
+	if n < int64(chunk.Size) {
+		br.Truncate(int(n))
+	}
+
+	var nbrComments uint16
+	binary.Read(br, binary.BigEndian, &nbrComments)
+	for i := 0; i < int(nbrComments); i++ {
+		// timestamp (4) + marker ID (2) + count (2) + text
+		header := br.Next(8)
+		if len(header) < 8 {
+			return fmt.Errorf("comment %d goes past the end of the COMT chunk", i)
+		}
+		count := int(binary.BigEndian.Uint16(header[6:]))
+		textB := br.Next(count)
+		// the text is padded to an even length
+		if count%2 != 0 {
+			br.Next(1)
+		}
+		comment := Comment{
+			Time:     aiffTime(binary.BigEndian.Uint32(header)),
+			MarkerID: int16(binary.BigEndian.Uint16(header[4:])),
+			Text:     d.decodeText(bytes.TrimRight(textB, "\x00")),
+		}
+		d.Comments = append(d.Comments, comment.Text)
+		d.CommentEntries = append(d.CommentEntries, comment)
+		if len(textB) < count {
+			return fmt.Errorf("comment %d goes past the end of the COMT chunk", i)
+		}
+	}
+
+	return nil
+}
+
+// writeComments writes the comments in a COMT chunk.
+func (e *Encoder) writeComments() error {
+	size := int64(2)
+	for i, c := range e.Comments {
+		if len(c.Text) > math.MaxUint16 {
+			return fmt.Errorf("comment %d is too long: %d bytes, the maximum is %d", i, len(c.Text), math.MaxUint16)
+		}
+		size += 8 + int64(len(c.Text)+len(c.Text)%2)
+	}
+	if size > math.MaxUint32 {
+		return fmt.Errorf("%w - the COMT chunk would be %d bytes", ErrTooLargeForAIFF, size)
+	}
+	if err := e.AddBE(COMTID); err != nil {
+		return fmt.Errorf("%v when writing COMT chunk ID header", err)
+	}
+	if err := e.AddBE(uint32(size)); err != nil {
+		return fmt.Errorf("%v when writing COMT chunk size header", err)
+	}
+	if err := e.AddBE(uint16(len(e.Comments))); err != nil {
+		return fmt.Errorf("%v when writing the number of comments", err)
+	}
+	for _, c := range e.Comments {
+		if err := e.AddBE(aiffTimestamp(c.Time)); err != nil {
+			return fmt.Errorf("%v when writing comment timestamp", err)
+		}
+		if err := e.AddBE(c.MarkerID); err != nil {
+			return fmt.Errorf("%v when writing comment marker ID", err)
+		}
+		if err := e.AddBE(uint16(len(c.Text))); err != nil {
+			return fmt.Errorf("%v when writing comment length", err)
+		}
+		text := []byte(c.Text)
+		// the text is padded to an even length
+		if len(text)%2 != 0 {
+			text = append(text, 0)
+		}
+		if err := e.AddBE(text); err != nil {
+			return fmt.Errorf("%v when writing comment text", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-audio/audio"
+)
+
+func TestDecoder_CommentEntries(t *testing.T) {
+	comment := func(timestamp uint32, marker int16, text string) []byte {
+		b := make([]byte, 8, 8+len(text)+1)
+		binary.BigEndian.PutUint32(b, timestamp)
+		binary.BigEndian.PutUint16(b[4:], uint16(marker))
+		binary.BigEndian.PutUint16(b[6:], uint16(len(text)))
+		b = append(b, text...)
+		if len(text)%2 != 0 {
+			b = append(b, 0)
+		}
+		return b
+	}
+	long := strings.Repeat("a long comment ", 40)
+	first := append([]byte{0, 2}, comment(3000000000, 0, long+"!")...)
+	first = append(first, comment(3000000001, 1, "odd")...)
+	second := append([]byte{0, 1}, comment(0, 0, "second chunk")...)
+	data := withChunks(t, "fixtures/kick.aif", testChunk("COMT", first), testChunk("COMT", second))
+
+	d := NewDecoder(bytes.NewReader(data))
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []Comment{
+		{Time: time.Date(1999, time.January, 24, 5, 20, 0, 0, time.UTC), Text: long + "!"},
+		{Time: time.Date(1999, time.January, 24, 5, 20, 1, 0, time.UTC), MarkerID: 1, Text: "odd"},
+		{Text: "second chunk"},
+	}
+	if !reflect.DeepEqual(d.CommentEntries, expected) {
+		t.Fatalf("expected %+v but got %+v", expected, d.CommentEntries)
+	}
+	if !reflect.DeepEqual(d.Comments, []string{long + "!", "odd", "second chunk"}) {
+		t.Fatalf("unexpected comments %q", d.Comments)
+	}
+}
+
+func TestEncoderComments(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/comments.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	comments := []Comment{
+		{Time: time.Date(2024, time.March, 1, 12, 30, 15, 0, time.UTC), MarkerID: 1, Text: "take 3"},
+		{Text: strings.Repeat("x", 300)},
+	}
+	e := NewEncoder(out, 44100, 16, 1)
+	e.FormType = FormAIFC
+	e.Markers = []Marker{{ID: 1, Position: 10, Name: "start"}}
+	e.Comments = comments
+	if err := e.Write(&audio.IntBuffer{Data: make([]int, 100), Format: &audio.Format{NumChannels: 1, SampleRate: 44100}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if issues := Lint(out); len(issues) > 0 {
+		t.Fatalf("expected the encoded file to be spec compliant but got %v", issues)
+	}
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(out)
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(d.CommentEntries, comments) {
+		t.Fatalf("expected %+v but got %+v", comments, d.CommentEntries)
+	}
+	if expected := time.Date(1990, time.May, 23, 14, 40, 0, 0, time.UTC); !d.FormatVersion.Equal(expected) {
+		t.Fatalf("expected the format version to be %v but got %v", expected, d.FormatVersion)
+	}
+}
+
+func TestAIFFTimestamp(t *testing.T) {
+	testCases := []struct {
+		t  time.Time
+		ts uint32
+	}{
+		{time.Time{}, 0},
+		{time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(1990, time.May, 23, 14, 40, 0, 0, time.UTC), aifcVersion1},
+		{time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC), math.MaxUint32},
+	}
+	for _, tc := range testCases {
+		if ts := aiffTimestamp(tc.t); ts != tc.ts {
+			t.Errorf("expected %v to be %d but got %d", tc.t, tc.ts, ts)
+		}
+	}
+	if tm := aiffTime(aifcVersion1); !tm.Equal(testCases[2].t) {
+		t.Fatalf("expected %v but got %v", testCases[2].t, tm)
+	}
+}
@@ -0,0 +1,256 @@
+package aiff
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/go-audio/audio"
+)
+
+// Difference describes something that differs between two files compared
+// with Equal.
+type Difference struct {
+	// Field is what differs: "file" (one of the files can't be decoded),
+	// "sample_rate", "channels", "bit_depth", "frames", "samples",
+	// "markers", "broadcast_info", "apple_info" or "metadata:<key>" using
+	// the keys returned by Decoder.Metadata.
+	Field string
+	// A and B are the values found in the 2 files.
+	A, B string
+	// Frame and Channel locate the first differing sample and Count is the
+	// total number of differing samples. Only set for "samples".
+	Frame   int64
+	Channel int
+	Count   int64
+}
+
+func (diff Difference) String() string {
+	if diff.Field == "samples" {
+		return fmt.Sprintf("%d samples differ, first at frame %d channel %d: %s != %s", diff.Count, diff.Frame, diff.Channel, diff.A, diff.B)
+	}
+	return fmt.Sprintf("%s: %s != %s", diff.Field, diff.A, diff.B)
+}
+
+// CompareOption changes the way files are compared by Equal.
+type CompareOption func(*compareConfig)
+
+type compareConfig struct {
+	ignoreMetadata bool
+	epsilon        int
+}
+
+// IgnoreMetadata only compares the format and the sound data, the metadata
+// chunks (text, markers, comments...) are skipped.
+func IgnoreMetadata() CompareOption {
+	return func(cfg *compareConfig) {
+		cfg.ignoreMetadata = true
+	}
+}
+
+// SampleTolerance considers 2 samples equal when they differ by at most
+// epsilon. The value is expressed at the highest bit depth of the 2 files.
+func SampleTolerance(epsilon int) CompareOption {
+	return func(cfg *compareConfig) {
+		if epsilon < 0 {
+			epsilon = -epsilon
+		}
+		cfg.epsilon = epsilon
+	}
+}
+
+// Equal compares 2 files at the audio sample level and returns the list of
+// differences found. The container details (AIFF vs AIFC, byte order,
+// chunk order and padding) are ignored so a sowt file and its big endian
+// version are equal. Files using different bit depths are reported as
+// different but their samples are still compared after being scaled to the
+// highest bit depth. Both readers are consumed.
+func Equal(a, b io.ReadSeeker, opts ...CompareOption) (bool, []Difference) {
+	cfg := &compareConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	da, db := NewDecoder(a), NewDecoder(b)
+	for i, d := range []*Decoder{da, db} {
+		d.ReadInfo()
+		if err := d.Err(); err != nil {
+			return false, []Difference{fileDifference(i, err.Error())}
+		}
+		if d.NumChans < 1 || d.BitDepth < 8 {
+			return false, []Difference{fileDifference(i, "no sound data format")}
+		}
+	}
+
+	var diffs []Difference
+	add := func(field string, va, vb interface{}) {
+		diffs = append(diffs, Difference{Field: field, A: fmt.Sprint(va), B: fmt.Sprint(vb)})
+	}
+	if da.SampleRate != db.SampleRate {
+		add("sample_rate", da.SampleRate, db.SampleRate)
+	}
+	if da.BitDepth != db.BitDepth {
+		add("bit_depth", da.BitDepth, db.BitDepth)
+	}
+	if da.NumSampleFrames != db.NumSampleFrames {
+		add("frames", da.NumSampleFrames, db.NumSampleFrames)
+	}
+	if da.NumChans != db.NumChans {
+		add("channels", da.NumChans, db.NumChans)
+	} else {
+		diffs = append(diffs, compareSamples(da, db, cfg.epsilon)...)
+	}
+
+	if !cfg.ignoreMetadata {
+		diffs = append(diffs, compareMetadata(a, b)...)
+	}
+	return len(diffs) == 0, diffs
+}
+
+// compareSamples compares the sound data of 2 decoders having the same
+// number of channels.
+func compareSamples(da, db *Decoder, epsilon int) []Difference {
+	var diffs []Difference
+	bitDepth := int(da.BitDepth)
+	if int(db.BitDepth) > bitDepth {
+		bitDepth = int(db.BitDepth)
+	}
+	numChans := int64(da.NumChans)
+	if numChans < 1 {
+		numChans = 1
+	}
+
+	bufA := &audio.IntBuffer{Data: make([]int, 4096)}
+	bufB := &audio.IntBuffer{Data: make([]int, 4096)}
+	// samples read but not compared yet, reads can be short
+	var pendingA, pendingB []int
+	var doneA, doneB bool
+	read := func(i int, d *Decoder, buf *audio.IntBuffer) ([]int, bool) {
+		n, err := d.PCMBuffer(buf)
+		if err != nil && err != io.EOF {
+			diffs = append(diffs, fileDifference(i, err.Error()))
+			return nil, true
+		}
+		return buf.Data[:n], n == 0
+	}
+
+	var pos, count int64
+	sampleDiff := Difference{Field: "samples"}
+	for {
+		if len(pendingA) == 0 && !doneA {
+			pendingA, doneA = read(0, da, bufA)
+		}
+		if len(pendingB) == 0 && !doneB {
+			pendingB, doneB = read(1, db, bufB)
+		}
+		n := len(pendingA)
+		if len(pendingB) < n {
+			n = len(pendingB)
+		}
+		if n == 0 {
+			break
+		}
+		for i := 0; i < n; i++ {
+			va := scaleSample(signedSample(pendingA[i], int(da.BitDepth)), int(da.BitDepth), bitDepth)
+			vb := scaleSample(signedSample(pendingB[i], int(db.BitDepth)), int(db.BitDepth), bitDepth)
+			delta := va - vb
+			if delta < 0 {
+				delta = -delta
+			}
+			if delta <= epsilon {
+				continue
+			}
+			if count == 0 {
+				sampleDiff.Frame = (pos + int64(i)) / numChans
+				sampleDiff.Channel = int((pos + int64(i)) % numChans)
+				sampleDiff.A = fmt.Sprint(va)
+				sampleDiff.B = fmt.Sprint(vb)
+			}
+			count++
+		}
+		pos += int64(n)
+		pendingA, pendingB = pendingA[n:], pendingB[n:]
+	}
+	if count > 0 {
+		sampleDiff.Count = count
+		diffs = append(diffs, sampleDiff)
+	}
+	// the declared frame counts match but the sound data doesn't
+	if da.NumSampleFrames == db.NumSampleFrames && (len(pendingA) > 0 || len(pendingB) > 0) {
+		diffs = append(diffs, Difference{
+			Field: "frames",
+			A:     fmt.Sprintf("%d+", (pos+int64(len(pendingA)))/numChans),
+			B:     fmt.Sprintf("%d+", (pos+int64(len(pendingB)))/numChans),
+		})
+	}
+	return diffs
+}
+
+// compareMetadata rewinds the readers and compares the content of the
+// metadata chunks.
+func compareMetadata(a, b io.ReadSeeker) []Difference {
+	var decoders [2]*Decoder
+	for i, r := range []io.ReadSeeker{a, b} {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return []Difference{fileDifference(i, err.Error())}
+		}
+		d := NewDecoder(r)
+		if err := d.Drain(); err != nil {
+			return []Difference{fileDifference(i, err.Error())}
+		}
+		decoders[i] = d
+	}
+	da, db := decoders[0], decoders[1]
+
+	var diffs []Difference
+	ma, mb := da.Metadata(), db.Metadata()
+	keys := map[string]bool{}
+	for k := range ma {
+		keys[k] = true
+	}
+	for k := range mb {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		if ma[k] != mb[k] {
+			diffs = append(diffs, Difference{Field: "metadata:" + k, A: ma[k], B: mb[k]})
+		}
+	}
+
+	if !reflect.DeepEqual(da.Markers, db.Markers) && (len(da.Markers) > 0 || len(db.Markers) > 0) {
+		diffs = append(diffs, Difference{Field: "markers", A: fmt.Sprint(da.Markers), B: fmt.Sprint(db.Markers)})
+	}
+	var bextA, bextB []byte
+	if da.BroadcastInfo != nil {
+		bextA = da.BroadcastInfo.Bytes()
+	}
+	if db.BroadcastInfo != nil {
+		bextB = db.BroadcastInfo.Bytes()
+	}
+	if !bytes.Equal(bextA, bextB) {
+		diffs = append(diffs, Difference{Field: "broadcast_info", A: fmt.Sprint(da.BroadcastInfo), B: fmt.Sprint(db.BroadcastInfo)})
+	}
+	if da.HasAppleInfo != db.HasAppleInfo || !reflect.DeepEqual(da.AppleInfo, db.AppleInfo) {
+		diffs = append(diffs, Difference{Field: "apple_info", A: fmt.Sprint(da.AppleInfo), B: fmt.Sprint(db.AppleInfo)})
+	}
+	return diffs
+}
+
+// fileDifference reports a problem with the file at index i (0 for a, 1 for
+// b) preventing the comparison.
+func fileDifference(i int, msg string) Difference {
+	diff := Difference{Field: "file", A: "valid", B: "valid"}
+	if i == 0 {
+		diff.A = msg
+	} else {
+		diff.B = msg
+	}
+	return diff
+}
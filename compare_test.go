@@ -0,0 +1,145 @@
+package aiff
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestEqual(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	open := func(path string) *os.File {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	// encode writes the buffer as a big endian AIFF file after applying the
+	// passed function to the samples.
+	encode := func(path string, d *Decoder, buf *audio.IntBuffer, fn func([]int)) *os.File {
+		data := make([]int, len(buf.Data))
+		copy(data, buf.Data)
+		if fn != nil {
+			fn(data)
+		}
+		out, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := NewEncoder(out, d.SampleRate, int(d.BitDepth), int(d.NumChans))
+		if err := e.Write(&audio.IntBuffer{Format: buf.Format, Data: data}); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := out.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	t.Run("same file", func(t *testing.T) {
+		a, b := open("fixtures/sowt2.aif"), open("fixtures/sowt2.aif")
+		defer a.Close()
+		defer b.Close()
+		if ok, diffs := Equal(a, b); !ok {
+			t.Fatalf("expected the files to be equal but got %v", diffs)
+		}
+	})
+
+	t.Run("different formats", func(t *testing.T) {
+		a, b := open("fixtures/kick.aif"), open("fixtures/bloop.aif")
+		defer a.Close()
+		defer b.Close()
+		ok, diffs := Equal(a, b)
+		if ok {
+			t.Fatal("expected the files to differ")
+		}
+		if diffs[0].Field != "sample_rate" || diffs[0].A != "22050" || diffs[0].B != "44100" {
+			t.Fatalf("unexpected first difference %v", diffs[0])
+		}
+	})
+
+	t.Run("byte order and metadata", func(t *testing.T) {
+		in := open("fixtures/sowt.aif")
+		defer in.Close()
+		d := NewDecoder(in)
+		buf, err := d.FullPCMBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := encode("testOutput/equal_twos.aif", d, buf, nil)
+		defer os.Remove(out.Name())
+		defer out.Close()
+
+		// the markers are dropped by the encoder
+		if _, err := in.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+		ok, diffs := Equal(in, out)
+		if ok || len(diffs) != 1 || diffs[0].Field != "markers" {
+			t.Fatalf("expected the markers to differ but got %v", diffs)
+		}
+
+		if _, err := in.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := out.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+		if ok, diffs := Equal(in, out, IgnoreMetadata()); !ok {
+			t.Fatalf("expected the sound data to be equal but got %v", diffs)
+		}
+	})
+
+	t.Run("epsilon", func(t *testing.T) {
+		in := open("fixtures/kick.aif")
+		defer in.Close()
+		d := NewDecoder(in)
+		buf, err := d.FullPCMBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := encode("testOutput/equal_epsilon.aif", d, buf, func(data []int) {
+			data[100]++
+			data[200]--
+		})
+		defer os.Remove(out.Name())
+		defer out.Close()
+
+		if _, err := in.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+		ok, diffs := Equal(in, out, IgnoreMetadata())
+		if ok || len(diffs) != 1 {
+			t.Fatalf("expected a single difference but got %v", diffs)
+		}
+		diff := diffs[0]
+		if diff.Field != "samples" || diff.Frame != 100 || diff.Count != 2 {
+			t.Fatalf("unexpected difference %v", diff)
+		}
+
+		if _, err := in.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := out.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+		if ok, diffs := Equal(in, out, IgnoreMetadata(), SampleTolerance(1)); !ok {
+			t.Fatalf("expected the files to be equal within the tolerance but got %v", diffs)
+		}
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		a, b := open("fixtures/kick.aif"), open("fixtures/sample.avi")
+		defer a.Close()
+		defer b.Close()
+		ok, diffs := Equal(a, b)
+		if ok || len(diffs) != 1 || diffs[0].Field != "file" || diffs[0].A != "valid" {
+			t.Fatalf("expected the second file to be reported as invalid but got %v", diffs)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Concat stitches the sound data of the passed inputs together and writes the
+// result as an AIFF file. All inputs must share the same sample rate, bit depth
+// and number of channels. The PCM data isn't decoded but copied as is (little
+// endian data only gets its bytes swapped) and the headers are updated when
+// the last input was copied. Chunks other than the sound data are dropped.
+// Note that the underlying writer is NOT being closed.
+func Concat(w io.WriteSeeker, inputs ...io.ReadSeeker) error {
+	if len(inputs) == 0 {
+		return errors.New("no inputs to concatenate")
+	}
+	var e *Encoder
+	for i, in := range inputs {
+		d := NewDecoder(in)
+		numFrames, err := d.fwdToRawPCM()
+		if err != nil {
+			return fmt.Errorf("input %d - %v", i, err)
+		}
+		if e == nil {
+			e = NewEncoder(w, d.SampleRate, int(d.BitDepth), int(d.NumChans))
+			if err := e.startPCMChunk(); err != nil {
+				return err
+			}
+		} else if d.SampleRate != e.SampleRate || int(d.BitDepth) != e.BitDepth || int(d.NumChans) != e.NumChans {
+			return fmt.Errorf("input %d - format mismatch, expected %d channels @ %d / %d bits but got %d channels @ %d / %d bits",
+				i, e.NumChans, e.SampleRate, e.BitDepth, d.NumChans, d.SampleRate, d.BitDepth)
+		}
+		if _, err := copyRawFrames(e, d, numFrames); err != nil {
+			return fmt.Errorf("input %d - failed to copy the PCM data - %v", i, err)
+		}
+	}
+	return e.Close()
+}
+
+// fwdToRawPCM forwards the decoder to the start of the PCM data and returns
+// the number of frames available if the data can be copied without decoding.
+func (d *Decoder) fwdToRawPCM() (int, error) {
+	if err := d.FwdToPCM(); err != nil {
+		return 0, fmt.Errorf("failed to forward to PCM - %v", err)
+	}
+	return d.rawPCMFrames()
+}
+
+// rawPCMFrames returns the number of frames left in the PCM chunk if the data
+// can be copied without decoding.
+func (d *Decoder) rawPCMFrames() (int, error) {
+	if d.PCMChunk == nil {
+		return 0, fmt.Errorf("PCM chunk not found - %v", d.Err())
+	}
+	if _, ok := pcmByteOrder(d.Encoding); !ok {
+		return 0, fmt.Errorf("%s - %q encoding can't be copied", ErrFmtNotSupported, d.Encoding)
+	}
+	frameSize := bytesPerSample(int(d.BitDepth)) * int(d.NumChans)
+	if frameSize < 1 {
+		return 0, fmt.Errorf("%s - %d channels @ %d bits", ErrFmtNotSupported, d.NumChans, d.BitDepth)
+	}
+	numFrames := (d.PCMChunk.Size - d.PCMChunk.Pos) / frameSize
+	if int(d.NumSampleFrames) < numFrames {
+		numFrames = int(d.NumSampleFrames)
+	}
+	return numFrames, nil
+}
+
+// copyRawFrames copies up to numFrames frames of PCM data from the decoder
+// to the encoder without decoding the samples. The bytes of the samples are
+// swapped when the byte orders differ. The number of copied frames is
+// returned.
+func copyRawFrames(e *Encoder, d *Decoder, numFrames int) (int, error) {
+	bPerSample := bytesPerSample(int(d.BitDepth))
+	frameSize := bPerSample * int(d.NumChans)
+	if err := e.checkPCMSize(int64(numFrames) * int64(frameSize)); err != nil {
+		return 0, err
+	}
+	framesPerRead := 4096
+	buf := make([]byte, framesPerRead*frameSize)
+	var copied int
+	for copied < numFrames {
+		toRead := numFrames - copied
+		if toRead > framesPerRead {
+			toRead = framesPerRead
+		}
+		n, err := io.ReadFull(d.PCMChunk, buf[:toRead*frameSize])
+		n -= n % frameSize
+		if n > 0 {
+			if d.byteOrder != e.byteOrder {
+				swapSampleBytes(buf[:n], bPerSample)
+			}
+			if err := e.addRawPCM(buf[:n]); err != nil {
+				return copied, err
+			}
+			copied += n / frameSize
+		}
+		if err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return copied, nil
+			}
+			return copied, err
+		}
+	}
+	return copied, nil
+}
+
+// swapSampleBytes inverts the byte order of each sample in place.
+func swapSampleBytes(data []byte, bPerSample int) {
+	for i := 0; i+bPerSample <= len(data); i += bPerSample {
+		for j, k := i, i+bPerSample-1; j < k; j, k = j+1, k-1 {
+			data[j], data[k] = data[k], data[j]
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package aiff
+
+import (
+	"os"
+	"testing"
+)
+
+func TestConcat(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	inputs := []string{"fixtures/bloop.aif", "fixtures/sowt.aif", "fixtures/bloop.aif"}
+
+	expected := []int{}
+	readers := []*os.File{}
+	for _, path := range inputs {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		buf, err := NewDecoder(f).FullPCMBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = append(expected, buf.Data...)
+		if _, err := f.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+		readers = append(readers, f)
+	}
+
+	out, err := os.Create("testOutput/concat.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	if err := Concat(out, readers[0], readers[1], readers[2]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(out)
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := out.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(d.Size) != info.Size()-8 {
+		t.Fatalf("wrong header size data, expected %d, got %d", info.Size()-8, d.Size)
+	}
+	if int(d.NumSampleFrames) != len(expected)/2 {
+		t.Fatalf("expected %d frames but got %d", len(expected)/2, d.NumSampleFrames)
+	}
+	if len(buf.Data) != len(expected) {
+		t.Fatalf("expected %d samples but got %d", len(expected), len(buf.Data))
+	}
+	for i, v := range expected {
+		if buf.Data[i] != v {
+			t.Fatalf("sample at position %d didn't match, expected %d, got %d", i, v, buf.Data[i])
+		}
+	}
+}
+
+func TestConcat_formatMismatch(t *testing.T) {
+	a, err := os.Open("fixtures/bloop.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	out, err := os.Create("testOutput/concat_mismatch.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	if err := Concat(out, a, b); err == nil {
+		t.Fatal("expected concatenating files with different formats to fail")
+	}
+}
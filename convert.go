@@ -0,0 +1,78 @@
+package aiff
+
+// SampleType is the set of types audio samples can be stored as.
+// Integer types hold samples of a given bit depth, float types hold samples
+// normalized within the [-1, 1] range.
+type SampleType interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~float32 | ~float64
+}
+
+// ConvertSamples converts the src samples into dst and returns the number of
+// converted samples (the smallest length of both slices). dst and src can
+// be the same slice to convert the samples in place.
+// srcBitDepth and dstBitDepth describe the bit depth of the integer samples
+// and are ignored for float types. Integer to integer conversions are bit
+// exact when going to a higher bit depth, values are clipped when converting
+// floats outside of the [-1, 1] range.
+func ConvertSamples[T, U SampleType](dst []U, src []T, srcBitDepth, dstBitDepth int) int {
+	n := len(src)
+	if len(dst) < n {
+		n = len(dst)
+	}
+	srcFloat, dstFloat := isFloatType[T](), isFloatType[U]()
+	switch {
+	case !srcFloat && !dstFloat:
+		shift := dstBitDepth - srcBitDepth
+		for i := 0; i < n; i++ {
+			v := int64(src[i])
+			if shift > 0 {
+				v <<= uint(shift)
+			} else if shift < 0 {
+				v >>= uint(-shift)
+			}
+			dst[i] = U(v)
+		}
+	case srcFloat && dstFloat:
+		for i := 0; i < n; i++ {
+			dst[i] = U(src[i])
+		}
+	case srcFloat:
+		max := float64(int64(1) << uint(dstBitDepth-1))
+		for i := 0; i < n; i++ {
+			v := float64(src[i]) * max
+			if v >= max {
+				v = max - 1
+			} else if v < -max {
+				v = -max
+			}
+			dst[i] = U(v)
+		}
+	default:
+		max := float64(int64(1) << uint(srcBitDepth-1))
+		for i := 0; i < n; i++ {
+			dst[i] = U(float64(src[i]) / max)
+		}
+	}
+	return n
+}
+
+// Frame returns a view (not a copy) of the samples of the frame at index i
+// in the passed interleaved data.
+func Frame[T SampleType](data []T, numChans, i int) []T {
+	return data[i*numChans : (i+1)*numChans]
+}
+
+// NumFrames returns the number of full frames contained in the passed
+// interleaved data.
+func NumFrames[T SampleType](data []T, numChans int) int {
+	if numChans < 1 {
+		return 0
+	}
+	return len(data) / numChans
+}
+
+// isFloatType reports whether T is a float type.
+func isFloatType[T SampleType]() bool {
+	half := T(1) / 2
+	return half != 0
+}
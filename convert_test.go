@@ -0,0 +1,55 @@
+package aiff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConvertSamples(t *testing.T) {
+	// int16 -> int32 (24 bit) and back
+	src := []int16{0, 1, -1, 32767, -32768}
+	i24 := make([]int32, len(src))
+	if n := ConvertSamples(i24, src, 16, 24); n != len(src) {
+		t.Fatalf("expected %d converted samples but got %d", len(src), n)
+	}
+	if exp := []int32{0, 256, -256, 8388352, -8388608}; !reflect.DeepEqual(i24, exp) {
+		t.Fatalf("expected %v but got %v", exp, i24)
+	}
+	back := make([]int16, len(src))
+	ConvertSamples(back, i24, 24, 16)
+	if !reflect.DeepEqual(back, src) {
+		t.Fatalf("expected %v but got %v", src, back)
+	}
+
+	// int -> float32 -> int16
+	f32 := make([]float32, 3)
+	ConvertSamples(f32, []int{0, 16384, -32768}, 16, 0)
+	if exp := []float32{0, 0.5, -1}; !reflect.DeepEqual(f32, exp) {
+		t.Fatalf("expected %v but got %v", exp, f32)
+	}
+	clipped := make([]int16, 4)
+	ConvertSamples(clipped, []float64{0.5, 1, -1, 2}, 0, 16)
+	if exp := []int16{16384, 32767, -32768, 32767}; !reflect.DeepEqual(clipped, exp) {
+		t.Fatalf("expected %v but got %v", exp, clipped)
+	}
+
+	// the shortest slice wins
+	if n := ConvertSamples(make([]float64, 2), []float32{1, 2, 3}, 0, 0); n != 2 {
+		t.Fatalf("expected 2 converted samples but got %d", n)
+	}
+}
+
+func TestFrame(t *testing.T) {
+	data := []int16{1, 2, 3, 4, 5, 6, 7}
+	if n := NumFrames(data, 2); n != 3 {
+		t.Fatalf("expected 3 frames but got %d", n)
+	}
+	frame := Frame(data, 2, 1)
+	if !reflect.DeepEqual(frame, []int16{3, 4}) {
+		t.Fatalf("expected [3 4] but got %v", frame)
+	}
+	frame[0] = 42
+	if data[2] != 42 {
+		t.Fatal("expected the frame to be a view of the data")
+	}
+}
@@ -0,0 +1,92 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// application signature of the APPL chunks holding the CRC-32 (IEEE) of the
+// sound data, see Encoder.Checksum.
+var crcSignature = [4]byte{'c', 'r', 'c', '3'}
+
+// writePCM writes encoded sound data and keeps the checksum up to date.
+// The data isn't written if the file would get too large to be described by
+// its headers.
+func (e *Encoder) writePCM(p []byte) (int, error) {
+	if err := e.checkPCMSize(int64(len(p))); err != nil {
+		return 0, err
+	}
+	n, err := e.w.Write(p)
+	e.WrittenBytes += n
+	if e.crc != nil {
+		e.crc.Write(p[:n])
+	}
+	return n, err
+}
+
+// writeChecksum writes the CRC-32 of the sound data in an APPL chunk.
+func (e *Encoder) writeChecksum() error {
+	if err := e.AddBE(applID); err != nil {
+		return fmt.Errorf("%v when writing APPL chunk ID header", err)
+	}
+	if err := e.AddBE(uint32(8)); err != nil {
+		return fmt.Errorf("%v when writing APPL chunk size header", err)
+	}
+	if err := e.AddBE(crcSignature); err != nil {
+		return fmt.Errorf("%v when writing APPL signature", err)
+	}
+	if err := e.AddBE(e.crc.Sum32()); err != nil {
+		return fmt.Errorf("%v when writing the checksum", err)
+	}
+	return nil
+}
+
+// VerifyChecksum compares the sound data with the checksum stored in the
+// file by an encoder (see Encoder.Checksum). false is returned if the file
+// doesn't have a checksum, an error wrapping ErrChecksumMismatch is
+// returned if the sound data doesn't match it.
+// The reader of the decoder isn't moved.
+func (d *Decoder) VerifyChecksum() (bool, error) {
+	idx, err := d.Index()
+	if idx == nil {
+		return false, err
+	}
+	var (
+		expected uint32
+		found    bool
+	)
+	for _, info := range idx.Chunks {
+		if info.ID != applID || info.Size < 8 {
+			continue
+		}
+		var b [8]byte
+		if _, err := d.ra.ReadAt(b[:], info.Offset+8); err != nil {
+			return false, fmt.Errorf("failed to read the APPL chunk at %d - %v", info.Offset, err)
+		}
+		if bytes.Equal(b[:4], crcSignature[:]) {
+			expected, found = binary.BigEndian.Uint32(b[4:]), true
+		}
+	}
+	if !found {
+		return false, nil
+	}
+	i := idx.Find(SSNDID)
+	if i < 0 {
+		return true, fmt.Errorf("%w - missing SSND chunk", ErrChecksumMismatch)
+	}
+	// the offset and block size fields aren't part of the sound data
+	info := idx.Chunks[i]
+	h := crc32.NewIEEE()
+	if info.Size > 8 {
+		if _, err := io.Copy(h, io.NewSectionReader(d.ra, info.Offset+16, int64(info.Size)-8)); err != nil {
+			return true, fmt.Errorf("failed to read the sound data - %v", err)
+		}
+	}
+	if sum := h.Sum32(); sum != expected {
+		return true, fmt.Errorf("%w - expected %08x but got %08x", ErrChecksumMismatch, expected, sum)
+	}
+	return true, nil
+}
@@ -0,0 +1,78 @@
+package aiff
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestEncoderChecksum(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/checksum.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	e := NewEncoder(out, 44100, 24, 2)
+	e.Checksum = true
+	data := make([]int, 2*1001)
+	for i := range data {
+		data[i] = i * 1000
+	}
+	if err := e.Write(&audio.IntBuffer{Data: data, Format: &audio.Format{NumChannels: 2, SampleRate: 44100}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(out)
+	if found, err := d.VerifyChecksum(); !found || err != nil {
+		t.Fatalf("expected a valid checksum but got %v, %v", found, err)
+	}
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf.Data) != len(data) {
+		t.Fatalf("expected %d samples but got %d", len(data), len(buf.Data))
+	}
+
+	// corrupt a sample
+	content, err := ioutil.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	content[bytes.Index(content, SSNDID[:])+16+100] ^= 0xff
+	d = NewDecoder(bytes.NewReader(content))
+	if found, err := d.VerifyChecksum(); !found || !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected a checksum mismatch but got %v, %v", found, err)
+	}
+	var reported bool
+	for _, issue := range d.Validate() {
+		if issue.Check == "checksum" && issue.Severity == SeverityError {
+			reported = true
+		}
+	}
+	if !reported {
+		t.Fatal("expected Validate to report the checksum mismatch")
+	}
+
+	f, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if found, err := NewDecoder(f).VerifyChecksum(); found || err != nil {
+		t.Fatalf("expected no checksum but got %v, %v", found, err)
+	}
+}
@@ -0,0 +1,78 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-audio/audio"
+)
+
+// DecodeAll decodes the whole file in memory and returns its samples along
+// with its format. It is meant for small files, see Decoder.PCMBuffer to
+// stream large ones.
+func DecodeAll(r io.ReadSeeker) (*audio.IntBuffer, *FileInfo, error) {
+	if r == nil {
+		return nil, nil, errors.New("can't decode a nil reader")
+	}
+	d := NewDecoder(r)
+	if err := d.ReadInfo(); err != nil {
+		return nil, nil, err
+	}
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		return nil, nil, err
+	}
+	info := &FileInfo{
+		Form:            d.Form,
+		NumChans:        d.NumChans,
+		NumSampleFrames: d.NumSampleFrames,
+		BitDepth:        d.BitDepth,
+		SampleRate:      d.SampleRate,
+		Encoding:        d.Encoding,
+		EncodingName:    d.EncodingName,
+	}
+	return buf, info, nil
+}
+
+// EncodeAll writes the samples of buf as a complete file. The bit depth, the
+// sample rate, the encoding and the form of the file are taken from info
+// when set (as returned by DecodeAll), otherwise from the format of the
+// buffer and its source bit depth (16 bits if not set). The writer isn't
+// closed.
+func EncodeAll(w io.WriteSeeker, buf *audio.IntBuffer, info *FileInfo) error {
+	if w == nil {
+		return errors.New("can't encode to a nil writer")
+	}
+	if buf == nil || buf.Format == nil {
+		return errors.New("can't encode a nil buffer or a buffer without a format")
+	}
+	bitDepth := buf.SourceBitDepth
+	if bitDepth == 0 {
+		bitDepth = 16
+	}
+	sampleRate := buf.Format.SampleRate
+	if info != nil {
+		if info.BitDepth > 0 {
+			bitDepth = int(info.BitDepth)
+		}
+		if info.SampleRate > 0 {
+			sampleRate = info.SampleRate
+		}
+		if info.NumChans > 0 && int(info.NumChans) != buf.Format.NumChannels {
+			return fmt.Errorf("the info describes %d channels but the buffer has %d", info.NumChans, buf.Format.NumChannels)
+		}
+	}
+	e := NewEncoder(w, sampleRate, bitDepth, buf.Format.NumChannels)
+	if info != nil {
+		e.Encoding = info.Encoding
+		e.EncodingName = info.EncodingName
+		if info.Form == aifcID {
+			e.FormType = FormAIFC
+		}
+	}
+	if err := e.Write(buf); err != nil {
+		return err
+	}
+	return e.Close()
+}
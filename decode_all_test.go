@@ -0,0 +1,102 @@
+package aiff
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestDecodeAll_RoundTrip(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	testCases := []struct {
+		in   string
+		form [4]byte
+	}{
+		{"fixtures/kick.aif", aiffID},
+		{"fixtures/kick8b.aiff", aiffID},
+		{"fixtures/sowt.aif", aifcID},
+		{"fixtures/zipper24b.aiff", aiffID},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			in, err := os.Open(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer in.Close()
+			buf, info, err := DecodeAll(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Form != tc.form {
+				t.Fatalf("expected a %q file but got %q", tc.form, info.Form)
+			}
+			if buf.NumFrames() != int(info.NumSampleFrames) {
+				t.Fatalf("expected %d frames but got %d", info.NumSampleFrames, buf.NumFrames())
+			}
+
+			out, err := os.Create("testOutput/encode_all.aif")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(out.Name())
+			defer out.Close()
+			if err := EncodeAll(out, buf, info); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			buf2, info2, err := DecodeAll(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the standard compression name is written when the file had none
+			if info.EncodingName == "" {
+				info2.EncodingName = ""
+			}
+			if !reflect.DeepEqual(info, info2) {
+				t.Fatalf("expected the info to survive the round trip\n%+v\nbut got\n%+v", info, info2)
+			}
+			if !reflect.DeepEqual(buf.Data, buf2.Data) {
+				t.Fatal("expected the samples to survive the round trip")
+			}
+		})
+	}
+}
+
+func TestEncodeAll_Defaults(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/encode_all_defaults.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	buf := &audio.IntBuffer{Format: &audio.Format{NumChannels: 2, SampleRate: 48000}, Data: []int{0, 1, -1, 2, 300, -300}}
+	if err := EncodeAll(out, buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	decoded, info, err := DecodeAll(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Form != aiffID || info.BitDepth != 16 || info.SampleRate != 48000 || info.NumChans != 2 {
+		t.Fatalf("expected a 16-bit stereo AIFF file @ 48kHz but got %+v", info)
+	}
+	if !reflect.DeepEqual(decoded.Data, buf.Data) {
+		t.Fatalf("expected %v but got %v", buf.Data, decoded.Data)
+	}
+
+	if err := EncodeAll(out, buf, &FileInfo{NumChans: 1}); err == nil {
+		t.Fatal("expected an error encoding a stereo buffer as a mono file")
+	}
+	if err := EncodeAll(out, nil, nil); err == nil {
+		t.Fatal("expected an error encoding a nil buffer")
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"os"
+	"math"
 	"time"
 
 	"bytes"
@@ -15,8 +15,13 @@ import (
 )
 
 // Decoder is the wrapper structure for the AIFF container
+// Code only reading the sound data should prefer Reader, which reads whole
+// frames of signed samples and seeks by frame.
 type Decoder struct {
 	r io.ReadSeeker
+	// ra gives positional access to the underlying reader so the file
+	// information can be parsed without moving the reader.
+	ra io.ReaderAt
 
 	// ID is always 'FORM'. This indicates that this is a FORM chunk
 	ID [4]byte
@@ -24,6 +29,14 @@ type Decoder struct {
 	// Note that the data portion has been
 	// broken into two parts, formType and chunks
 	Size uint32
+	// SizeTrusted is false when the sound data goes past the sizes declared
+	// in the FORM or SSND headers. This happens when recorders wrap or zero
+	// the 32-bit size fields of files over 4GB. The actual size of the data is
+	// then used to decode the samples and compute the duration.
+	SizeTrusted bool
+	// FrameCountSource indicates if NumSampleFrames was read from the COMM
+	// chunk or derived from the sound data.
+	FrameCountSource FrameCountSource
 	// Form describes what's in the 'FORM' chunk. For Audio IFF files,
 	// formType (aka Format) is always 'AIFF'.
 	// This indicates that the chunks within the FORM pertain to sampled sound.
@@ -34,35 +47,149 @@ type Decoder struct {
 	NumChans        uint16
 	NumSampleFrames uint32
 	BitDepth        uint16
-	SampleRate      int
+	// SampleRate is the sample rate rounded to the nearest integer, see
+	// ExactSampleRate for non integer rates.
+	SampleRate int
+	// sample rate as declared in the COMM chunk
+	sampleRate float64
+	// RawCOMM holds the exact content of the COMM chunk when KeepRawCOMM is
+	// set, see Encoder.RawCOMM.
+	RawCOMM []byte
+	// PCMSize is the size of the sound data in bytes.
 	//
-	PCMSize  uint32
-	PCMChunk *Chunk
+	// Deprecated: use PCMOffset, which also locates the sound data.
+	PCMSize uint32
+	// PCMChunk is the SSND chunk, positioned on the next sample to decode.
+	// Reading from it moves the decoder.
 	//
+	// Deprecated: use Reader to read the sound data frame by frame, or
+	// PCMSection for direct access to the bytes.
+	PCMChunk *Chunk
+	// SSNDOffset and SSNDBlockSize are the offset of the first sample frame
+	// in the SSND chunk and the size of the blocks the sound data is aligned
+	// on, both are usually 0.
+	SSNDOffset    uint32
+	SSNDBlockSize uint32
+	// TrailingBytes is the number of bytes at the end of the sound data that
+	// don't make a full sample frame. They are ignored by FullPCMBuffer and
+	// PCMBuffer, which stop at the last full frame.
+	TrailingBytes int
+	// FormatVersion is the timestamp of the FVER chunk of AIFC files, it
+	// identifies the version of the spec the file follows (May 23, 1990
+	// 14:40 UTC for the only published version).
+	FormatVersion time.Time
+	// Comments holds the text of the comments of all the COMT chunks.
 	Comments []string
+	// CommentEntries holds the comments of all the COMT chunks along with
+	// their timestamp and marker.
+	CommentEntries []Comment
+	Markers        []Marker
+	// Instrument is the content of the INST chunk if any.
+	Instrument *Instrument
+	// content of the text chunks
+	Name        string
+	Author      string
+	Copyright   string
+	Annotations []string
+	// ID3 holds the text frames of an embedded ID3v2 tag keyed by frame ID
+	// (TIT2, TPE1...). Comments are stored under COMM and user defined
+	// frames under TXXX:<description>. iTunes comments (iTunSMPB...) are
+	// stored under COMM:<description>.
+	ID3 map[string]string
+	// BroadcastInfo is the BWF bext data stored in an APPL chunk if any
+	BroadcastInfo *BroadcastInfo
+	// ChannelLayout is the content of the CHAN chunk if any, see
+	// ChannelLabels.
+	ChannelLayout *ChannelLayout
 
 	// AIFC data
-	Encoding     [4]byte
+	Encoding     Encoding
 	EncodingName string
 
 	// Apple specific
 	HasAppleInfo bool
 	AppleInfo    AppleMetadata
+	// UnknownChunks holds the raw content of the chunks the decoder doesn't
+	// parse (such as the Apple Loops trns and SDST chunks), keyed by ID in
+	// the order they were found, when CollectUnknownChunks is set.
+	UnknownChunks map[[4]byte][][]byte
+
+	// SkipMetadata disables the parsing of the metadata chunks (comments,
+	// markers, text, Apple specific...) by ReadInfo, Drain and FwdToPCM.
+	// Handlers registered via OnChunk are still called.
+	SkipMetadata bool
+	// FrameAligned makes PCMBuffer return full frames only, the number of
+	// samples returned is always a multiple of the number of channels.
+	//
+	// Deprecated: use Reader, which always reads full frames.
+	FrameAligned bool
+	// CollectUnknownChunks stores the content of the chunks that aren't
+	// parsed in UnknownChunks, see Drain.
+	CollectUnknownChunks bool
+	// KeepRawCOMM stashes the content of the COMM chunk in RawCOMM so it can
+	// be written back unchanged, bit for bit.
+	KeepRawCOMM bool
+	// ResyncWindow is the number of bytes searched for the next chunk when
+	// garbage is found instead of a chunk header, so the audio of files left
+	// with stray bytes between chunks by broken writers can be salvaged. The
+	// skipped regions are listed in Resyncs. Resyncing is disabled if not
+	// positive.
+	ResyncWindow int
+	// Resyncs lists the stray bytes skipped while parsing, see ResyncWindow.
+	Resyncs []Resync
+	// Logger receives the diagnostic messages, nothing is logged if not set.
+	Logger Logger
+	// Metrics receives the decoding events when set, see Metrics.
+	Metrics Metrics
+	// ValidationPolicy restricts the formats accepted by IsValidFile and
+	// Validate, any positive sample rate and number of channels is accepted
+	// if not set.
+	ValidationPolicy *ValidationPolicy
 
 	err             error
 	pcmDataAccessed bool
+	// absolute position and length of the sample data in the underlying reader
+	pcmStart  int64
+	pcmLength int64
+	// position in PCMChunk where the last full frame ends
+	pcmEnd int
+	// actual size of the SSND chunk and number of frames it holds when the
+	// declared sizes can't be trusted
+	ssndSize     int64
+	actualFrames int64
+	// SSND chunk to decode (see SelectSSND) and number of SSND chunks
+	// skipped so far
+	ssndIndex   int
+	ssndSkipped int
 
 	byteOrder binary.ByteOrder
-
-	// read the file information to setup the audio clip
-	// find the beginning of the SSND chunk and set the clip reader to it.
-	rewindBytes int64
+	// character encoding of the text chunks, see SetTextEncoding
+	textEncoding TextEncoding
+	// byte order set by the user, overriding the one declared by the file
+	forcedByteOrder binary.ByteOrder
+
+	// offsets of the chunks already parsed while reading the file information
+	parsedChunks map[int64]bool
+	// table of the chunks of the file, see scanChunks
+	chunkTable    []ChunkInfo
+	chunkTableErr error
+	// custom chunk parsers registered via OnChunk
+	chunkHandlers map[[4]byte]func(*Chunk) error
+	// chunks to parse or skip, see WithChunks and WithoutChunks
+	allowedChunks map[[4]byte]bool
+	deniedChunks  map[[4]byte]bool
+	// decoder of the registered codec matching the encoding
+	sampleDecoder SampleDecoder
+	// samples of the incomplete frame kept by PCMBuffer, see FrameAligned
+	pendingSamples []int
 }
 
 // NewDecoder creates a new reader reading the given reader and pushing audio data to the given channel.
 // It is the caller's responsibility to call Close on the reader when done.
+// The file must start at offset 0 of r whatever the current position of r,
+// use NewDecoderAt to decode a file embedded further in the data.
 func NewDecoder(r io.ReadSeeker) *Decoder {
-	return &Decoder{r: r, byteOrder: binary.BigEndian}
+	return &Decoder{r: r, ra: newReaderAt(r), byteOrder: binary.BigEndian}
 }
 
 // SampleBitDepth returns the bit depth encoding of each sample.
@@ -73,17 +200,24 @@ func (d *Decoder) SampleBitDepth() int32 {
 	return int32(d.BitDepth)
 }
 
-// PCMLen returns the total number of bytes in the PCM data chunk
+// PCMLen returns the total number of bytes of sound data in the PCM data
+// chunk, it's known once the decoder was forwarded to the PCM data.
 func (d *Decoder) PCMLen() int64 {
 	if d == nil {
 		return 0
 	}
+	if d.PCMChunk != nil {
+		return d.pcmLength
+	}
 	return int64(d.PCMSize)
 }
 
 // Err returns the first non-EOF error that was encountered by the Decoder.
+// The methods returning an error report it directly, Err is meant to be
+// checked after calling the ones that don't (IsValidFile, Duration...).
+// The error is cleared by Reset.
 func (d *Decoder) Err() error {
-	if d.err == io.EOF {
+	if d == nil || d.err == io.EOF {
 		return nil
 	}
 	return d.err
@@ -105,6 +239,18 @@ func (d *Decoder) WasPCMAccessed() bool {
 	return d.pcmDataAccessed
 }
 
+// ExactSampleRate returns the sample rate declared in the COMM chunk without
+// rounding it, such as 44056.0 or 22254.545454.
+func (d *Decoder) ExactSampleRate() float64 {
+	if d == nil {
+		return 0
+	}
+	if d.sampleRate == 0 {
+		return float64(d.SampleRate)
+	}
+	return d.sampleRate
+}
+
 // Format returns the audio format of the decoded content.
 func (d *Decoder) Format() *audio.Format {
 	if d == nil {
@@ -116,6 +262,21 @@ func (d *Decoder) Format() *audio.Format {
 	}
 }
 
+// OnChunk registers a function called with each chunk matching the passed ID
+// when the chunks are parsed (see Drain and FwdToPCM). Registered handlers take
+// precedence over the built-in parsers, passing a nil function removes the
+// handler. The chunk is drained after the handler returns.
+func (d *Decoder) OnChunk(id [4]byte, fn func(*Chunk) error) {
+	if fn == nil {
+		delete(d.chunkHandlers, id)
+		return
+	}
+	if d.chunkHandlers == nil {
+		d.chunkHandlers = map[[4]byte]func(*Chunk) error{}
+	}
+	d.chunkHandlers[id] = fn
+}
+
 // NextChunk returns the next available chunk
 func (d *Decoder) NextChunk() (*Chunk, error) {
 	// we need to read the info so we have access to the encoding.
@@ -125,26 +286,41 @@ func (d *Decoder) NextChunk() (*Chunk, error) {
 	}
 
 	var (
-		id   [4]byte
-		size uint32
+		id     [4]byte
+		size   uint32
+		offset int64
 	)
 
-	id, size, d.err = d.iDnSize()
-	if size%2 != 0 {
-		// realign, the encoder lied about the size of the chunk header :(
-		size++
+	if offset, d.err = d.r.Seek(0, io.SeekCurrent); d.err != nil {
+		return nil, fmt.Errorf("error locating chunk header - %v", d.err)
 	}
+	id, size, d.err = d.iDnSize()
 	if d.err != nil {
 		if d.err == io.EOF || d.err == io.ErrUnexpectedEOF {
 			return nil, io.EOF
 		}
 		return nil, fmt.Errorf("error reading chunk header - %v", d.err)
 	}
+	if !isChunkID(id) {
+		if next, ok := d.resync(offset); ok {
+			if offset, d.err = d.r.Seek(next, io.SeekStart); d.err != nil {
+				return nil, fmt.Errorf("error skipping stray bytes - %v", d.err)
+			}
+			if id, size, d.err = d.iDnSize(); d.err != nil {
+				return nil, fmt.Errorf("error reading chunk header - %v", d.err)
+			}
+		}
+	}
 
+	// odd sized chunks are followed by a pad byte which isn't part of the
+	// data, it's consumed when the chunk is drained.
+	pad := int(size % 2)
 	c := &Chunk{
-		ID:   id,
-		Size: int(size),
-		R:    io.LimitReader(d.r, int64(size)),
+		ID:     id,
+		Size:   int(size),
+		R:      io.LimitReader(d.r, int64(size)+int64(pad)),
+		offset: offset,
+		pad:    pad,
 	}
 
 	return c, d.err
@@ -156,35 +332,45 @@ func (d *Decoder) IsValidFile() bool {
 	if d.err != nil {
 		return false
 	}
-	if d.NumChans < 1 {
+	if err := d.ValidationPolicy.checkChannels(int(d.NumChans)); err != nil {
+		return false
+	}
+	if err := d.ValidationPolicy.checkSampleRate(d.ExactSampleRate()); err != nil {
 		return false
 	}
 	if d.BitDepth < 8 {
 		return false
 	}
-	if d, err := d.Duration(); err != nil || d <= 0 {
+	duration, err := d.Duration()
+	if err != nil || duration < 0 {
+		return false
+	}
+	if duration == 0 && !d.ValidationPolicy.allowsEmptyAudio() {
 		return false
 	}
-	switch d.Encoding {
-	case encSowt, encNone, encNotSet:
-	default:
+	if !isSupportedEncoding(d.Encoding) {
 		return false
 	}
 
 	return true
 }
 
+// isSupportedEncoding reports whether the sound data of the passed encoding
+// can be decoded.
+func isSupportedEncoding(encoding Encoding) bool {
+	if _, ok := pcmByteOrder(encoding); ok {
+		return true
+	}
+	_, ok := lookupCodec(encoding)
+	return ok
+}
+
 // Duration returns the time duration for the current AIFF container
 func (d *Decoder) Duration() (time.Duration, error) {
 	if d == nil {
 		return 0, errors.New("can't calculate the duration of a nil pointer")
 	}
-	d.ReadInfo()
-	if err := d.Err(); err != nil {
-		return 0, err
-	}
-	duration := time.Duration(float64(d.NumSampleFrames) / float64(d.SampleRate) * float64(time.Second))
-	return duration, nil
+	return d.PreciseDuration()
 }
 
 // Tempo returns a tempo when available, otherwise -1
@@ -225,7 +411,7 @@ func (d *Decoder) Drain() error {
 func (d *Decoder) FwdToPCM() error {
 	if d.err = d.readHeaders(); d.err != nil {
 		d.err = fmt.Errorf("failed to read header - %v", d.err)
-		return nil
+		return d.err
 	}
 
 	var chunk *Chunk
@@ -236,40 +422,14 @@ func (d *Decoder) FwdToPCM() error {
 			return d.err
 		}
 
+		if chunk.ID == SSNDID && d.ssndSkipped < d.ssndIndex {
+			// another SSND chunk was selected
+			d.ssndSkipped++
+			chunk.Done()
+			continue
+		}
 		if chunk.ID == SSNDID {
-			//            SSND chunk: Must be defined
-			//   0      4 bytes  "SSND"
-			//   4      4 bytes  <Chunk size(x)>
-			//   8      4 bytes  <Offset(n)>
-			//  12      4 bytes  <block size>
-			//  16     (n)bytes  Comment
-			//  16+(n) (s)bytes  <Sample data>
-
-			var offset uint32
-			if d.err = chunk.ReadBE(&offset); d.err != nil {
-				d.err = fmt.Errorf("PCM offset failed to parse - %s", d.err)
-				return d.err
-			}
-
-			if d.err = chunk.ReadBE(&d.PCMSize); d.err != nil {
-				d.err = fmt.Errorf("PCMSize failed to parse - %s", d.err)
-				return d.err
-			}
-			if offset > 0 {
-				d.PCMSize -= offset
-				// skip pcm comment
-				buf := make([]byte, offset)
-				if err := chunk.ReadBE(&buf); err != nil {
-					d.err = fmt.Errorf("failed to read the offsetted buffer - %v", err)
-					return d.err
-				}
-			}
-			d.PCMChunk = chunk
-			d.pcmDataAccessed = true
-			if d.err != nil {
-				d.err = fmt.Errorf("failed to read the SSND chunk - %v", d.err)
-			}
-			return d.err
+			return d.openSSND(chunk)
 		}
 
 		if err := d.parseChunk(chunk); err != nil {
@@ -279,21 +439,183 @@ func (d *Decoder) FwdToPCM() error {
 	return d.err
 }
 
-// Reset resets the decoder (and rewind the underlying reader)
+// openSSND reads the header of the SSND chunk and sets it as the PCM chunk.
+func (d *Decoder) openSSND(chunk *Chunk) error {
+	// the corrected size and the number of frames of the COMM chunk only
+	// describe the first SSND chunk, see SelectSSND
+	first := d.ssndIndex == 0
+	if d.ssndSize > 0 && first {
+		chunk.Size = int(d.ssndSize)
+		chunk.R = io.LimitReader(d.r, d.ssndSize)
+	}
+	//            SSND chunk: Must be defined
+	//   0      4 bytes  "SSND"
+	//   4      4 bytes  <Chunk size(x)>
+	//   8      4 bytes  <Offset(n)>
+	//  12      4 bytes  <block size>
+	//  16     (n)bytes  Comment
+	//  16+(n) (s)bytes  <Sample data>
+
+	// an empty SSND chunk (placeholder files) might not even contain
+	// the offset and block size fields
+	if chunk.Size > 0 {
+		if d.err = chunk.ReadBE(&d.SSNDOffset); d.err != nil {
+			d.err = fmt.Errorf("PCM offset failed to parse - %s", d.err)
+			return d.err
+		}
+		if d.err = chunk.ReadBE(&d.SSNDBlockSize); d.err != nil {
+			d.err = fmt.Errorf("PCM block size failed to parse - %s", d.err)
+			return d.err
+		}
+	}
+	if offset := d.SSNDOffset; offset > 0 {
+		// skip pcm comment
+		buf := make([]byte, offset)
+		if err := chunk.ReadBE(&buf); err != nil {
+			d.err = fmt.Errorf("failed to read the offsetted buffer - %v", err)
+			return d.err
+		}
+	}
+	d.pcmStart = chunk.offset + 8 + int64(chunk.Pos)
+	d.pcmLength = int64(chunk.Size - chunk.Pos)
+	if frameSize := int64(bytesPerSample(int(d.BitDepth)) * int(d.NumChans)); frameSize > 0 {
+		// the sound data is truncated to the last full frame
+		if _, ok := lookupCodec(d.Encoding); !ok {
+			d.TrailingBytes = int(d.pcmLength % frameSize)
+			d.pcmLength -= int64(d.TrailingBytes)
+		}
+		if dataSize := int64(d.NumSampleFrames) * frameSize; dataSize < d.pcmLength && d.actualFrames == 0 && first {
+			d.pcmLength = dataSize
+		}
+	}
+	d.pcmEnd = chunk.Pos + int(d.pcmLength)
+	d.PCMSize = uint32(d.pcmLength)
+	d.chunkParsed(chunk)
+	d.meterPCM(chunk)
+	d.PCMChunk = chunk
+	d.pcmDataAccessed = true
+	if d.err != nil {
+		d.err = fmt.Errorf("failed to read the SSND chunk - %v", d.err)
+	}
+	return d.err
+}
+
+// PCMOffset returns the absolute position of the sample data in the
+// underlying reader and its length in bytes. This is useful to mmap or pread
+// the audio data directly. The decoder is forwarded to the PCM data if needed.
+func (d *Decoder) PCMOffset() (start int64, length int64, err error) {
+	if d == nil {
+		return 0, 0, errors.New("can't locate the PCM data of a nil pointer")
+	}
+	if !d.WasPCMAccessed() {
+		if err := d.FwdToPCM(); err != nil {
+			return 0, 0, fmt.Errorf("failed to forward to PCM: %v", err)
+		}
+	}
+	if d.PCMChunk == nil {
+		return 0, 0, fmt.Errorf("PCM chunk not found - %v", d.Err())
+	}
+	return d.pcmStart, d.pcmLength, nil
+}
+
+// seekFrame positions the decoder on the frame at index frame of the sound
+// data, the samples of an incomplete frame kept by PCMBuffer are dropped.
+// Only the files storing uncompressed PCM data can be sought anywhere, the
+// others can only be rewound (frame 0).
+func (d *Decoder) seekFrame(frame int64) error {
+	if _, ok := pcmByteOrder(d.Encoding); !ok {
+		if frame != 0 {
+			return fmt.Errorf("%s - can't seek in %q encoded data", ErrFmtNotSupported, d.Encoding)
+		}
+		if err := d.Rewind(); err != nil {
+			return err
+		}
+		return d.FwdToPCM()
+	}
+
+	start, length, err := d.PCMOffset()
+	if err != nil {
+		return err
+	}
+	offset := frame * int64(bytesPerSample(int(d.BitDepth))*int(d.NumChans))
+	if offset > length {
+		return fmt.Errorf("frame %d is past the end of the sound data", frame)
+	}
+	if _, err := d.r.Seek(start+offset, io.SeekStart); err != nil {
+		return err
+	}
+	d.PCMChunk = &Chunk{
+		ID:   SSNDID,
+		Size: int(length - offset),
+		R:    io.LimitReader(d.r, length-offset),
+	}
+	d.meterPCM(d.PCMChunk)
+	d.pcmEnd = d.PCMChunk.Size
+	d.pendingSamples = nil
+	return nil
+}
+
+// HeaderBytes returns a copy of the raw bytes of the file preceding the
+// sample data: the FORM header, the chunks stored before the SSND chunk and
+// the SSND header. The positions reported by PCMOffset, Chunk.Offset and
+// ChunkInfo.Offset can be used to locate the chunks in it. The decoder is
+// forwarded to the PCM data if needed.
+func (d *Decoder) HeaderBytes() ([]byte, error) {
+	start, _, err := d.PCMOffset()
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, start)
+	if n, err := d.ra.ReadAt(b, 0); n < len(b) {
+		return nil, fmt.Errorf("failed to read the %d header bytes - %v", start, err)
+	}
+	return b, nil
+}
+
+// Reset resets the decoder (and rewind the underlying reader).
+// All the decoded data (format, comments, markers, metadata, PCM chunk...)
+// and errors are cleared so the file is parsed again from scratch. The
+// settings (SkipMetadata, KeepRawCOMM, CollectUnknownChunks, FrameAligned,
+// ResyncWindow, Logger, Metrics, SetTextEncoding, ForceByteOrder, WithChunks,
+// WithoutChunks and the OnChunk handlers) are kept, see ResetWithReader to
+// clear them too.
+// Failing to rewind the reader is reported by Err.
 func (d *Decoder) Reset() {
-	d.ID = [4]byte{}
-	d.Size = 0
-	d.Form = [4]byte{}
-	d.commSize = 0
-	d.NumChans = 0
-	d.NumSampleFrames = 0
-	d.BitDepth = 0
-	d.SampleRate = 0
-	d.Encoding = [4]byte{}
-	d.EncodingName = ""
-	d.err = nil
-	d.pcmDataAccessed = false
-	d.r.Seek(0, 0)
+	*d = Decoder{
+		r:                    d.r,
+		ra:                   d.ra,
+		byteOrder:            binary.BigEndian,
+		SkipMetadata:         d.SkipMetadata,
+		KeepRawCOMM:          d.KeepRawCOMM,
+		CollectUnknownChunks: d.CollectUnknownChunks,
+		FrameAligned:         d.FrameAligned,
+		ResyncWindow:         d.ResyncWindow,
+		textEncoding:         d.textEncoding,
+		forcedByteOrder:      d.forcedByteOrder,
+		ssndIndex:            d.ssndIndex,
+		chunkHandlers:        d.chunkHandlers,
+		allowedChunks:        d.allowedChunks,
+		deniedChunks:         d.deniedChunks,
+		Logger:               d.Logger,
+		Metrics:              d.Metrics,
+		ValidationPolicy:     d.ValidationPolicy,
+	}
+	if d.forcedByteOrder != nil {
+		d.byteOrder = d.forcedByteOrder
+	}
+	if _, err := d.r.Seek(0, io.SeekStart); err != nil {
+		d.err = fmt.Errorf("failed to rewind - %v", err)
+	}
+}
+
+// ResetWithReader makes the decoder read from r as if it was created by
+// NewDecoder: the decoded data, the errors and the settings (SkipMetadata,
+// Logger, Metrics, SetTextEncoding, ForceByteOrder, OnChunk...) are all
+// cleared.
+// It allows decoders to be reused via a sync.Pool when probing a lot of
+// files.
+func (d *Decoder) ResetWithReader(r io.ReadSeeker) {
+	*d = Decoder{r: r, ra: newReaderAt(r), byteOrder: binary.BigEndian}
 }
 
 // Seek provides access to the cursor position in the PCM data
@@ -305,26 +627,69 @@ func (d *Decoder) Seek(offset int64, whence int) (int64, error) {
 // This is useful if you want to keep on decoding the same file in a loop.
 func (d *Decoder) Rewind() error {
 	d.Reset()
-	return nil
+	return d.err
 }
 
+// MaxFullPCMBufferSamples is the maximum number of samples (all channels
+// included) FullPCMBuffer loads in memory, protecting the callers from
+// hostile or corrupted files declaring huge sizes. Use FullPCMBufferLimit
+// to decode larger files.
+var MaxFullPCMBufferSamples = 1 << 27
+
+// maxPresizedSamples is the maximum number of samples FullPCMBuffer allocates
+// upfront based on the declared size of the sound data.
+const maxPresizedSamples = 1 << 22
+
 // FullPCMBuffer is an inneficient way to access all the PCM data contained in the
 // audio container. The entire PCM data is held in memory.
 // Consider using Buffer() instead.
+// Files holding more than MaxFullPCMBufferSamples samples are refused, see
+// FullPCMBufferLimit.
 func (d *Decoder) FullPCMBuffer() (*audio.IntBuffer, error) {
+	return d.FullPCMBufferLimit(MaxFullPCMBufferSamples)
+}
+
+// FullPCMBufferLimit is like FullPCMBuffer but refuses the files holding more
+// than maxSamples samples (all channels included) with ErrTooManySamples,
+// before allocating anything. There is no limit if maxSamples isn't
+// positive.
+func (d *Decoder) FullPCMBufferLimit(maxSamples int) (*audio.IntBuffer, error) {
+	defer d.decodeTimer()()
 	if !d.WasPCMAccessed() {
 		err := d.FwdToPCM()
 		if err != nil {
 			return nil, fmt.Errorf("failed to forward to PCM: %v", err)
 		}
 	}
-	format := &audio.Format{
-		NumChannels: int(d.NumChans),
-		SampleRate:  int(d.SampleRate),
+	if maxSamples > 0 {
+		if n := d.pcmSampleCount(); n > int64(maxSamples) {
+			return nil, fmt.Errorf("%w - the file holds %d samples, the limit is %d", ErrTooManySamples, n, maxSamples)
+		}
+	}
+	format := d.Format()
+	if codec, ok := lookupCodec(d.Encoding); ok {
+		return d.fullCodecPCMBuffer(codec, maxSamples)
 	}
 
-	chunkSize := 4096
-	buf := &audio.IntBuffer{Data: make([]int, chunkSize),
+	// the buffer is sized from the headers (number of frames and SSND size)
+	// so the samples don't need to be copied while the buffer grows. The
+	// declared size can't be trusted, it's capped by the size of the file and
+	// maxPresizedSamples, the buffer grows if needed.
+	var size int
+	if bPerSample := bytesPerSample(int(d.BitDepth)); bPerSample > 0 {
+		remaining := int64(d.pcmRemaining())
+		if fileSize, err := d.fileSize(); err == nil && fileSize-d.pcmStart < remaining {
+			remaining = fileSize - d.pcmStart
+		}
+		size = int(remaining / int64(bPerSample))
+		if size > maxPresizedSamples {
+			size = maxPresizedSamples
+		}
+	}
+	if size < 1 {
+		size = 4096
+	}
+	buf := &audio.IntBuffer{Data: make([]int, size),
 		Format:         format,
 		SourceBitDepth: int(d.BitDepth),
 	}
@@ -336,35 +701,52 @@ func (d *Decoder) FullPCMBuffer() (*audio.IntBuffer, error) {
 	sampleBuf := make([]byte, 4, 4)
 	n := 0
 	i := 0
-	chunkSize = 2048 * bytesPerSample(buf.SourceBitDepth)
+	chunkSize := 2048 * bytesPerSample(buf.SourceBitDepth)
 	sizeToRead := chunkSize
+	bulkDecode := d.bulkDecodeFunc()
+	// the scratch buffer and its reader are reused for each block
+	scratch := make([]byte, chunkSize)
+	bufReader := bytes.NewReader(nil)
 	var innerErr error
 	for err == nil {
 		// to avoid doing too many small reads (bad performance)
 		// we are loading part of the chunk in memory and reading from there
 		sizeToRead = chunkSize
 		if adjust := sizeToRead % bytesPerSample(buf.SourceBitDepth); adjust != 0 {
-			fmt.Fprintf(os.Stderr, "should be 0: %d %d %d\n", adjust, sizeToRead, buf.SourceBitDepth)
+			d.logf("misaligned read of %d bytes for %d-bit samples (%d extra bytes)", sizeToRead, buf.SourceBitDepth, adjust)
 		}
 
-		if leftOverSize := d.PCMChunk.Size - d.PCMChunk.Pos; leftOverSize < chunkSize {
+		if leftOverSize := d.pcmRemaining(); leftOverSize < chunkSize {
 			sizeToRead = leftOverSize
 		}
 		if sizeToRead < 1 {
 			break
 		}
-		optBuf := make([]byte, sizeToRead)
+		optBuf := scratch[:sizeToRead]
 		n, err = d.PCMChunk.Read(optBuf)
 		if err != nil {
-			// fmt.Println("-->", sizeToRead, err)
 			break
 		}
-		if n != sizeToRead {
-			optBuf = optBuf[:n]
-		}
+		// a truncated file can end in the middle of a sample
+		optBuf = optBuf[:n-n%bytesPerSample(buf.SourceBitDepth)]
 
-		bufReader := bytes.NewReader(optBuf)
+		if bulkDecode != nil {
+			for i+len(optBuf)/bytesPerSample(buf.SourceBitDepth) > len(buf.Data) {
+				buf.Data = append(buf.Data, make([]int, len(buf.Data))...)
+			}
+			i += bulkDecode(buf.Data[i:], optBuf)
+			continue
+		}
+		bufReader.Reset(optBuf)
 		for innerErr == nil {
+			// double the size of the underlying slice if the header
+			// underestimated the number of samples
+			if i >= len(buf.Data) {
+				if bufReader.Len() == 0 {
+					break
+				}
+				buf.Data = append(buf.Data, make([]int, len(buf.Data))...)
+			}
 			buf.Data[i], innerErr = decodeF(bufReader, sampleBuf)
 			if innerErr != nil {
 				if innerErr == io.EOF {
@@ -373,10 +755,6 @@ func (d *Decoder) FullPCMBuffer() (*audio.IntBuffer, error) {
 				break
 			}
 			i++
-			// grow the underlying slice if needed
-			if i >= len(buf.Data) {
-				buf.Data = append(buf.Data, make([]int, chunkSize)...)
-			}
 		}
 	}
 	buf.Data = buf.Data[:i]
@@ -388,12 +766,69 @@ func (d *Decoder) FullPCMBuffer() (*audio.IntBuffer, error) {
 	return buf, err
 }
 
+// pcmSampleCount returns the number of samples left to decode, as declared
+// by the file.
+func (d *Decoder) pcmSampleCount() int64 {
+	if _, ok := lookupCodec(d.Encoding); ok {
+		return int64(d.NumSampleFrames) * int64(d.NumChans)
+	}
+	bPerSample := bytesPerSample(int(d.BitDepth))
+	if d.PCMChunk == nil || bPerSample < 1 {
+		return 0
+	}
+	return int64(d.PCMChunk.Remaining()) / int64(bPerSample)
+}
+
+// pcmRemaining returns the number of bytes of sound data left to read, up
+// to the last full frame.
+func (d *Decoder) pcmRemaining() int {
+	if d.PCMChunk == nil || d.PCMChunk.Pos >= d.pcmEnd {
+		return 0
+	}
+	return d.pcmEnd - d.PCMChunk.Pos
+}
+
 // PCMBuffer populates the passed PCM buffer and returns the number of samples
 // read and a potential error. If the reader reaches EOF, an io.EOF error will be returned.
+// The number of samples is a multiple of the number of channels when
+// FrameAligned is set.
 func (d *Decoder) PCMBuffer(buf *audio.IntBuffer) (n int, err error) {
 	if buf == nil {
 		return 0, nil
 	}
+	if d.FrameAligned {
+		return d.frameAlignedPCMBuffer(buf)
+	}
+	return d.pcmBuffer(buf)
+}
+
+// frameAlignedPCMBuffer populates the passed buffer with full frames only,
+// the samples of the last incomplete frame are kept for the next call.
+func (d *Decoder) frameAlignedPCMBuffer(buf *audio.IntBuffer) (int, error) {
+	if !d.WasPCMAccessed() {
+		if err := d.FwdToPCM(); err != nil {
+			return 0, err
+		}
+	}
+	numChans := int(d.NumChans)
+	if numChans < 1 {
+		return d.pcmBuffer(buf)
+	}
+	if len(buf.Data) < numChans {
+		return 0, fmt.Errorf("a buffer of %d samples can't hold a frame of %d channels", len(buf.Data), numChans)
+	}
+	pending := copy(buf.Data, d.pendingSamples)
+	n, err := d.pcmBuffer(&audio.IntBuffer{Data: buf.Data[pending:]})
+	buf.Format = d.Format()
+	buf.SourceBitDepth = int(d.BitDepth)
+	total := pending + n
+	n = total - total%numChans
+	d.pendingSamples = append(d.pendingSamples[:0], buf.Data[n:total]...)
+	return n, err
+}
+
+func (d *Decoder) pcmBuffer(buf *audio.IntBuffer) (n int, err error) {
+	defer d.decodeTimer()()
 
 	if !d.WasPCMAccessed() {
 		err = d.FwdToPCM()
@@ -401,12 +836,12 @@ func (d *Decoder) PCMBuffer(buf *audio.IntBuffer) (n int, err error) {
 			return 0, err
 		}
 	}
+	if codec, ok := lookupCodec(d.Encoding); ok {
+		return d.codecPCMBuffer(codec, buf)
+	}
 
 	// TODO: avoid a potentially unecessary allocation
-	format := &audio.Format{
-		NumChannels: int(d.NumChans),
-		SampleRate:  int(d.SampleRate),
-	}
+	format := d.Format()
 
 	buf.SourceBitDepth = int(d.BitDepth)
 	decodeF, err := sampleDecodeFunc(buf.SourceBitDepth, d.byteOrder)
@@ -416,36 +851,39 @@ func (d *Decoder) PCMBuffer(buf *audio.IntBuffer) (n int, err error) {
 
 	bPerSample := bytesPerSample(int(d.BitDepth))
 	// populate a file buffer to avoid multiple very small reads
-	// we need to cap the buffer size to not be bigger than the pcm chunk.
+	// we need to cap the buffer size to not be bigger than the pcm data.
 	size := len(buf.Data) * bPerSample
+	if remaining := d.pcmRemaining(); remaining < size {
+		size = remaining
+	}
+	if size < 1 {
+		return 0, nil
+	}
 	tmpBuf := make([]byte, size)
 	var m int
-	m, err = d.PCMChunk.R.Read(tmpBuf)
+	m, err = io.ReadFull(d.PCMChunk, tmpBuf)
+	if err == io.ErrUnexpectedEOF {
+		// truncated file, the incomplete sample is dropped
+		err = nil
+	}
 	if err != nil {
 		if err == io.EOF {
-			return m, nil
+			return 0, nil
 		}
-		return m, err
+		return 0, err
 	}
-	if m == 0 {
-		return m, nil
+	if bulkDecode := d.bulkDecodeFunc(); bulkDecode != nil {
+		buf.Format = format
+		return bulkDecode(buf.Data, tmpBuf[:m-m%bPerSample]), nil
 	}
-	bufR := bytes.NewReader(tmpBuf[:m])
+	bufR := bytes.NewReader(tmpBuf[:m-m%bPerSample])
 	sampleBuf := make([]byte, bPerSample, bPerSample)
-	var misaligned bool
-	if m%bPerSample > 0 {
-		misaligned = true
-	}
 
 	// Note that we populate the buffer even if the
 	// size of the buffer doesn't fit an even number of frames.
-	for n = 0; n < len(buf.Data); n++ {
+	for n = 0; n < m/bPerSample; n++ {
 		buf.Data[n], err = decodeF(bufR, sampleBuf)
 		if err != nil {
-			// the last sample isn't a full sample but just padding.
-			if misaligned {
-				n--
-			}
 			break
 		}
 	}
@@ -457,43 +895,22 @@ func (d *Decoder) PCMBuffer(buf *audio.IntBuffer) (n int, err error) {
 	return n, err
 }
 
-// String implements the Stringer interface.
-func (d *Decoder) String() string {
-	out := fmt.Sprintf("Format: %s - ", d.Form)
-	if d.Form == aifcID {
-		out += fmt.Sprintf("%s - ", string(d.Encoding[:]))
-	}
-	if d.SampleRate != 0 {
-		out += fmt.Sprintf("%d channels @ %d / %d bits - ", d.NumChans, d.SampleRate, d.BitDepth)
-		dur, _ := d.Duration()
-		out += fmt.Sprintf("Duration: %f seconds\n", dur.Seconds())
-	}
-	if len(d.Comments) > 0 {
-		for _, comment := range d.Comments {
-			out += fmt.Sprintln(comment)
-		}
-	}
-	if d.HasAppleInfo {
-		out += fmt.Sprintln("Key note:", AppleNoteToPitch(d.AppleInfo.Note))
-		out += fmt.Sprintln("Scale:", AppleScaleToString(d.AppleInfo.Scale))
-		out += fmt.Sprintf("Tempo: %.2f BPM\n", d.Tempo())
-		out += fmt.Sprintf("Number of beats: %d\n", d.AppleInfo.Beats)
-		out += fmt.Sprintf("Time signature: %d/%d\n", d.AppleInfo.Numerator, d.AppleInfo.Denominator)
-		var format string
-		if d.AppleInfo.IsLooping {
-			format = "loop"
-		} else {
-			format = "one-shot"
-		}
-		out += fmt.Sprintln("Sample format:", format)
-		if len(d.AppleInfo.Tags) > 0 {
-			out += "Tags:\n"
-			for _, tag := range d.AppleInfo.Tags {
-				out += fmt.Sprintln("\t" + tag)
-			}
+// iDnSizeAt returns the ID + block size of the chunk starting at the given
+// offset without moving the underlying reader.
+func (d *Decoder) iDnSizeAt(offset int64) ([4]byte, uint32, error) {
+	var (
+		ID     [4]byte
+		header [8]byte
+	)
+	n, err := d.ra.ReadAt(header[:], offset)
+	if n < len(header) {
+		if err == nil {
+			err = io.ErrUnexpectedEOF
 		}
+		return ID, 0, err
 	}
-	return out
+	copy(ID[:], header[:4])
+	return ID, binary.BigEndian.Uint32(header[4:]), nil
 }
 
 // iDnSize returns the next ID + block size
@@ -512,10 +929,16 @@ func (d *Decoder) iDnSize() ([4]byte, uint32, error) {
 // readHeaders is safe to call multiple times
 // byte size of the header: 12
 func (d *Decoder) readHeaders() error {
-	// prevent the headers to be re-read
-	if d.Size > 0 {
+	// prevent the headers to be re-read, the size can't be used since it
+	// might have been zeroed.
+	if d.Form == aiffID || d.Form == aifcID {
 		return nil
 	}
+	// the chunks are located using absolute offsets (see scanChunks), the
+	// FORM header must be at the start of the reader
+	if _, d.err = d.r.Seek(0, io.SeekStart); d.err != nil {
+		return d.err
+	}
 	var n int64
 	size := 12 // 4 + 4 + 4
 	src := bytes.NewBuffer(make([]byte, 0, size))
@@ -551,72 +974,85 @@ func (d *Decoder) readHeaders() error {
 
 // ReadInfo reads the underlying reader to extract information.
 // This method is safe to call multiple times.
-func (d *Decoder) ReadInfo() {
-	if d == nil || d.SampleRate > 0 {
-		return
+func (d *Decoder) ReadInfo() error {
+	if d == nil {
+		return errors.New("can't read the info of a nil pointer")
+	}
+	if d.SampleRate > 0 {
+		// the information was already read, report an earlier failure
+		return d.Err()
 	}
 	if d.err = d.readHeaders(); d.err != nil {
 		d.err = fmt.Errorf("failed to read header - %v", d.err)
-		return
+		return d.err
 	}
 
-	var (
-		id          [4]byte
-		size        uint32
-		rewindBytes int64
-	)
-	for d.err != io.EOF {
-		id, size, d.err = d.iDnSize()
-		if d.err != nil {
-			d.err = fmt.Errorf("error reading chunk header - %v", d.err)
+	// the chunk table is built first so the COMM chunk can be found
+	// wherever it is, even after the sound data, without reading the file
+	// sequentially.
+	table, scanErr := d.scanChunks()
+	comm := -1
+	for i, entry := range table {
+		if entry.ID == COMMID {
+			comm = i
 			break
 		}
-		switch id {
-		case COMMID:
-			d.parseCommChunk(size)
-			// if we found other chunks before the COMM,
-			// we need to rewind the reader so we can properly
-			// read the rest later.
-			if rewindBytes > 0 {
-				// we need to rewind rewindBytes+size of chunk ID and size
-				d.r.Seek(-(rewindBytes + int64(size) + 8), io.SeekCurrent)
-				rewindBytes = 0
+	}
+	if comm < 0 {
+		if scanErr == nil {
+			scanErr = io.EOF
+		}
+		d.err = fmt.Errorf("COMM chunk not found - %v", scanErr)
+		return d.err
+	}
+	entry := table[comm]
+	if d.parseCommChunk(io.NewSectionReader(d.ra, entry.Offset+8, int64(entry.Size)), entry.Size) != nil {
+		return d.Err()
+	}
+	d.checkSizes()
+	d.deriveNumFrames()
+
+	if !d.SkipMetadata && !d.filtersOut(COMTID) {
+		for _, entry := range table {
+			if entry.ID != COMTID || d.parsedChunks[entry.Offset] {
+				continue
 			}
-			return
-		case COMTID:
 			chunk := &Chunk{
-				ID:   id,
-				Size: int(size),
-				R:    io.LimitReader(d.r, int64(size)),
+				ID:     entry.ID,
+				Size:   int(entry.Size),
+				R:      io.NewSectionReader(d.ra, entry.Offset+8, int64(entry.Size)),
+				offset: entry.Offset,
 			}
 			if err := d.parseCommentsChunk(chunk); err != nil {
-				fmt.Fprintf(os.Stderr, "failed to read comments (ignored) - %v", err)
-			}
-		default:
-			// we haven't read the COMM chunk yet, we need to track location to rewind
-			if d.SampleRate == 0 {
-				rewindBytes += int64(size) + 8 // we add 8 for the ID and size of this chunk
+				d.logf("failed to read the COMT chunk (ignored) - %v", err)
+				d.err = nil
 			}
-			if d.err = d.jumpTo(int(size)); d.err != nil {
-				return
+			if d.parsedChunks == nil {
+				d.parsedChunks = map[int64]bool{}
 			}
+			d.parsedChunks[entry.Offset] = true
 		}
 	}
+	return d.Err()
 }
 
-func (d *Decoder) parseCommChunk(size uint32) error {
+func (d *Decoder) parseCommChunk(r io.Reader, size uint32) error {
 	// don't re-parse the comm chunk
 	if d.NumChans > 0 {
 		return nil
 	}
 	d.commSize = size
 
+	// the size can't be trusted, the buffer grows with the data actually read
 	var n int64
-	src := bytes.NewBuffer(make([]byte, 0, size))
-	n, d.err = io.CopyN(src, d.r, int64(size))
+	src := &bytes.Buffer{}
+	n, d.err = io.CopyN(src, r, int64(size))
 	if n < int64(size) {
 		src.Truncate(int(n))
 	}
+	if d.KeepRawCOMM {
+		d.RawCOMM = append([]byte(nil), src.Bytes()...)
+	}
 
 	if d.err = binary.Read(src, binary.BigEndian, &d.NumChans); d.err != nil {
 		d.err = fmt.Errorf("num of channels failed to parse - %s", d.err)
@@ -635,7 +1071,8 @@ func (d *Decoder) parseCommChunk(size uint32) error {
 		d.err = fmt.Errorf("sample rate failed to parse - %s", d.err)
 		return d.err
 	}
-	d.SampleRate = audio.IEEEFloatToInt(srBytes)
+	d.sampleRate = Float80ToFloat64(srBytes)
+	d.SampleRate = int(math.Round(d.sampleRate))
 
 	read := 18
 
@@ -644,44 +1081,35 @@ func (d *Decoder) parseCommChunk(size uint32) error {
 			d.err = fmt.Errorf("AIFC encoding failed to parse - %s", d.err)
 			return d.err
 		}
-		if d.Encoding == encSowt {
-			d.byteOrder = binary.LittleEndian
+		if byteOrder, ok := pcmByteOrder(d.Encoding); ok && d.forcedByteOrder == nil {
+			d.byteOrder = byteOrder
 		}
 		// pascal style string with the description of the encoding
-		var encNameSize uint8
-		if d.err = binary.Read(src, binary.BigEndian, &encNameSize); d.err != nil {
+		var n int
+		var name string
+		if name, n, d.err = readPstring(src); d.err != nil {
 			d.err = fmt.Errorf("AIFC encoding failed to parse - %s", d.err)
 			return d.err
 		}
-		read += 5
-		if encNameSize > 0 {
-			desc := make([]byte, encNameSize)
-			if d.err = binary.Read(src, binary.BigEndian, &desc); d.err != nil {
-				d.err = fmt.Errorf("AIFC encoding failed to parse - %s", d.err)
-				return d.err
-			}
-			d.EncodingName = string(desc[:encNameSize])
-			read += int(encNameSize)
+		d.EncodingName = d.decodeText([]byte(name))
+		read += 4 + n
+		if d.Metrics != nil && !isSupportedEncoding(d.Encoding) {
+			d.Metrics.UnsupportedEncoding(d.Encoding)
 		}
 	}
 	if read < int(size) {
+		// some writers (such as macOS `say`) store an AIFC style encoding
+		// and compression name in the COMM chunk of plain AIFF files, the
+		// extra bytes are ignored.
+		if d.Form == aiffID {
+			d.logf("ignoring %d extra bytes in the %d bytes AIFF COMM chunk", int(size)-read, size)
+		}
 		io.CopyN(ioutil.Discard, src, int64(int(size)-read))
 	}
 
 	return d.err
 }
 
-// jumpTo advances the reader to the amount of bytes provided
-func (d *Decoder) jumpTo(bytesAhead int) error {
-	var err error
-	if bytesAhead > 0 {
-		_, err = d.r.Seek(int64(bytesAhead), io.SeekCurrent)
-		// TODO: benchmark against
-		// _, err = io.CopyN(ioutil.Discard, d.r, int64(bytesAhead))
-	}
-	return err
-}
-
 func bytesPerSample(bitDepth int) int {
 	return bitDepth / 8
 }
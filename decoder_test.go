@@ -2,13 +2,16 @@ package aiff
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -51,13 +54,18 @@ func TestContainerAttributes(t *testing.T) {
 		{"fixtures/kick.aif", formID, 9642, aiffID,
 			18, 1, 4484, 16, 22050, 4484, [4]byte{}, "", nil},
 		{"fixtures/ring.aif", formID, 354310, aiffID,
-			18, 2, 88064, 16, 44100, 88064, [4]byte{}, "", []string{"Creator: Logic"}},
+			18, 2, 88064, 16, 44100, 88064, [4]byte{}, "", []string{" Creator: Logic"}},
 		{"fixtures/sowt.aif", formID, 17276, aifcID,
-			24, 2, 4064, 16, 44100, 4064, encSowt, "", nil},
+			24, 2, 4064, 16, 44100, 4064, EncSowt, "", nil},
 		// misaligned chunk sizes
 		{"fixtures/sowt2.aif", formID, 683420, aifcID,
-			24, 2, 166677, 16, 44100, 166677, encSowt, "", []string{"c) 2009 mutekki-media.de"}},
-		{"fixtures/ableton.aif", formID, 203316, aifcID, 38, 2, 33815, 24, 48000, 33815, encAble, "Ableton Content", nil},
+			24, 2, 166677, 16, 44100, 166677, EncSowt, "", []string{"(c) 2009 mutekki-media.de"}},
+		{"fixtures/ableton.aif", formID, 203316, aifcID, 38, 2, 33815, 24, 48000, 33815, EncAble, "Ableton Content", nil},
+		// high sample rates
+		{"fixtures/sine96k24b.aif", formID, 2926, aiffID, 18, 1, 960, 24, 96000, 960, [4]byte{}, "", nil},
+		{"fixtures/sine192k.aif", formID, 7726, aiffID, 18, 2, 1920, 16, 192000, 1920, [4]byte{}, "", nil},
+		// COMM chunk larger than 18 bytes in an AIFF file
+		{"fixtures/say.aif", formID, 1090, aiffID, 38, 1, 512, 16, 22050, 512, [4]byte{}, "", nil},
 	}
 
 	for _, exp := range expectations {
@@ -205,6 +213,67 @@ func TestDecoder_FullPCMBuffer(t *testing.T) {
 	}
 }
 
+func TestDecoder_FullPCMBufferLimit(t *testing.T) {
+	// kick.aif holds 4484 mono samples
+	numSamples := 4484
+	open := func() *Decoder {
+		f, err := os.Open("fixtures/kick.aif")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { f.Close() })
+		return NewDecoder(f)
+	}
+	if _, err := open().FullPCMBufferLimit(numSamples - 1); !errors.Is(err, ErrTooManySamples) {
+		t.Fatalf("expected ErrTooManySamples but got %v", err)
+	}
+	buf, err := open().FullPCMBufferLimit(numSamples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf.Data) != numSamples {
+		t.Fatalf("expected %d samples but got %d", numSamples, len(buf.Data))
+	}
+	// the buffer is sized from the headers
+	if cap(buf.Data) != numSamples {
+		t.Fatalf("expected a buffer of %d samples but got %d", numSamples, cap(buf.Data))
+	}
+	if _, err := open().FullPCMBufferLimit(0); err != nil {
+		t.Fatalf("expected no limit but got %v", err)
+	}
+
+	defer func(max int) { MaxFullPCMBufferSamples = max }(MaxFullPCMBufferSamples)
+	MaxFullPCMBufferSamples = 100
+	if _, err := open().FullPCMBuffer(); !errors.Is(err, ErrTooManySamples) {
+		t.Fatalf("expected the package limit to apply but got %v", err)
+	}
+}
+
+func TestDecoder_FullPCMBuffer_truncated(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// declare 100M frames and keep 50 of them
+	comm := bytes.Index(data, []byte("COMM"))
+	ssnd := bytes.Index(data, []byte("SSND"))
+	binary.BigEndian.PutUint32(data[comm+10:], 100_000_000)
+	binary.BigEndian.PutUint32(data[ssnd+4:], 8+200_000_000)
+	data = data[:ssnd+16+100]
+
+	buf, err := NewDecoder(bytes.NewReader(data)).FullPCMBufferLimit(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf.Data) != 50 {
+		t.Fatalf("expected 50 samples but got %d", len(buf.Data))
+	}
+	// the buffer isn't sized from the declared size
+	if cap(buf.Data) > 4096 {
+		t.Fatalf("expected a small buffer but got %d samples", cap(buf.Data))
+	}
+}
+
 func TestDecoderPCMBuffer(t *testing.T) {
 	testCases := []struct {
 		input            string
@@ -276,6 +345,113 @@ func TestDecoderPCMBuffer(t *testing.T) {
 	}
 }
 
+func TestDecoder_MisalignedPCM(t *testing.T) {
+	// 10 stereo frames followed by an incomplete frame
+	numFrames := 10
+	for _, bitDepth := range []int{8, 16, 24, 32} {
+		t.Run(fmt.Sprintf("%d-bit", bitDepth), func(t *testing.T) {
+			f, err := os.Open(fmt.Sprintf("fixtures/misaligned%db.aif", bitDepth))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			check := func(data []int) {
+				t.Helper()
+				if len(data) != numFrames*2 {
+					t.Fatalf("expected %d samples but got %d", numFrames*2, len(data))
+				}
+				for i, v := range data {
+					expected := i/2*10 + i%2 + 1
+					if i%2 == 1 {
+						expected = -expected
+					}
+					if signedSample(v, bitDepth) != expected {
+						t.Fatalf("expected sample %d to be %d but got %d", i, expected, signedSample(v, bitDepth))
+					}
+				}
+			}
+
+			d := NewDecoder(f)
+			full, err := d.FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(full.Data)
+			if expected := 2*bitDepth/8 - 1; d.TrailingBytes != expected {
+				t.Fatalf("expected %d trailing bytes but got %d", expected, d.TrailingBytes)
+			}
+
+			// the same samples are returned whatever the size of the buffer
+			for _, size := range []int{1, 3, 7, 64} {
+				if err := d.Rewind(); err != nil {
+					t.Fatal(err)
+				}
+				var data []int
+				buf := &audio.IntBuffer{Data: make([]int, size)}
+				for {
+					n, err := d.PCMBuffer(buf)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if n == 0 {
+						break
+					}
+					data = append(data, buf.Data[:n]...)
+				}
+				check(data)
+			}
+		})
+	}
+}
+
+func TestDecoder_FrameAligned(t *testing.T) {
+	for _, input := range []string{"fixtures/ring.aif", "fixtures/misaligned24b.aif"} {
+		f, err := os.Open(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		d := NewDecoder(f)
+		full, err := d.FullPCMBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		numChans := int(d.NumChans)
+
+		d.FrameAligned = true
+		for _, size := range []int{3, 5, 255} {
+			if err := d.Rewind(); err != nil {
+				t.Fatal(err)
+			}
+			var data []int
+			buf := &audio.IntBuffer{Data: make([]int, size)}
+			for {
+				n, err := d.PCMBuffer(buf)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n == 0 {
+					break
+				}
+				if n%numChans != 0 {
+					t.Fatalf("%s: expected full frames of %d channels but got %d samples", input, numChans, n)
+				}
+				data = append(data, buf.Data[:n]...)
+			}
+			if !reflect.DeepEqual(data, full.Data) {
+				t.Fatalf("%s: expected the frame aligned reads of %d samples to return all the samples", input, size)
+			}
+		}
+
+		if err := d.Rewind(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := d.PCMBuffer(&audio.IntBuffer{Data: make([]int, numChans-1)}); err == nil {
+			t.Fatalf("%s: expected an error reading in a buffer smaller than a frame", input)
+		}
+	}
+}
+
 func TestDecoder_IsValidFile(t *testing.T) {
 	testCases := []struct {
 		in      string
@@ -284,6 +460,8 @@ func TestDecoder_IsValidFile(t *testing.T) {
 		{"fixtures/bloop.aif", true},
 		{"fixtures/kick8b.aiff", true},
 		{"fixtures/zipper24b.aiff", true},
+		{"fixtures/sine96k24b.aif", true},
+		{"fixtures/sine192k.aif", true},
 		{"fixtures/sample.avi", false},
 		{"fixtures/kick.wav", false},
 		{"fixtures/ableton.aif", false},
@@ -338,6 +516,91 @@ func TestDecoderRewind(t *testing.T) {
 	}
 }
 
+func TestDecoder_Reset(t *testing.T) {
+	f, err := os.Open("fixtures/ring.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	d.SetTextEncoding(TextEncodingMacRoman)
+	if _, err := d.FullPCMBuffer(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Markers) != 2 {
+		t.Fatalf("expected 2 markers but got %d", len(d.Markers))
+	}
+
+	d.Reset()
+	// all the decoded data is cleared, new fields must be reset too
+	v := reflect.ValueOf(d).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		switch field.Name {
+		case "r", "ra", "byteOrder", "textEncoding", "Logger", "Metrics", "ValidationPolicy":
+			continue
+		}
+		if !v.Field(i).IsZero() {
+			t.Errorf("expected %s to be reset but got %v", field.Name, v.Field(i))
+		}
+	}
+	if d.textEncoding != TextEncodingMacRoman {
+		t.Fatal("expected the text encoding setting to be kept")
+	}
+	if pos, _ := f.Seek(0, io.SeekCurrent); pos != 0 {
+		t.Fatalf("expected the reader to be rewound but it's at %d", pos)
+	}
+
+	// a second pass gives the same results
+	if _, err := d.FullPCMBuffer(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Markers) != 2 {
+		t.Fatalf("expected 2 markers after the reset but got %d", len(d.Markers))
+	}
+}
+
+func TestDecoder_ResetWithReader(t *testing.T) {
+	f, err := os.Open("fixtures/sowt2.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	d.SkipMetadata = true
+	d.OnChunk(COMTID, func(*Chunk) error { return nil })
+	if _, err := d.FullPCMBuffer(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+
+	f2, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f2.Close()
+	d.ResetWithReader(f2)
+	if !reflect.DeepEqual(d, NewDecoder(f2)) {
+		t.Fatalf("expected the decoder to be reset, got %#v", d)
+	}
+
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.SampleRate != 22050 || len(buf.Data) != 4484 {
+		t.Fatalf("expected to decode kick.aif but got %d samples @ %d", len(buf.Data), d.SampleRate)
+	}
+}
+
 func TestJump(t *testing.T) {
 	chunk := Chunk{}
 	if err := chunk.Jump(1); err == nil {
@@ -357,3 +620,275 @@ func TestJump(t *testing.T) {
 		t.Errorf("Expected '%x' got '%x'", data[1], c)
 	}
 }
+
+func TestDecoder_PCMOffset(t *testing.T) {
+	testCases := []struct {
+		input  string
+		start  int64
+		length int64
+	}{
+		{"fixtures/kick.aif", 54, 8968},
+		{"fixtures/sowt.aif", 114, 16256},
+	}
+
+	for _, tc := range testCases {
+		f, err := os.Open(tc.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		d := NewDecoder(f)
+		start, length, err := d.PCMOffset()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if start != tc.start || length != tc.length {
+			t.Fatalf("expected the PCM data of %s to be at %d (%d bytes) but got %d (%d bytes)", tc.input, tc.start, tc.length, start, length)
+		}
+		// the raw data should decode to the same samples
+		buf, err := d.FullPCMBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw := make([]byte, length)
+		if _, err := f.ReadAt(raw, start); err != nil {
+			t.Fatal(err)
+		}
+		decodeF, err := sampleDecodeFunc(int(d.BitDepth), d.byteOrder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := bytes.NewReader(raw)
+		sampleBuf := make([]byte, 4)
+		for i, expected := range buf.Data {
+			v, err := decodeF(r, sampleBuf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != expected {
+				t.Fatalf("sample %d of %s didn't match, expected %d, got %d", i, tc.input, expected, v)
+			}
+		}
+	}
+}
+
+func TestDecoder_SSNDAlignment(t *testing.T) {
+	comm := []byte{0, 1, 0, 0, 0, 4, 0, 16}
+	rate := Float80FromFloat64(22050)
+	comm = append(comm, rate[:]...)
+	// 4 bytes of padding before the first frame, 8 bytes blocks
+	ssnd := []byte{0, 0, 0, 4, 0, 0, 0, 8, 0xff, 0xff, 0xff, 0xff, 0, 1, 0, 2, 0, 3, 0, 4}
+	data := []byte("FORM\x00\x00\x00\x00AIFF")
+	data = append(data, testChunk("COMM", comm)...)
+	data = append(data, testChunk("SSND", ssnd)...)
+	binary.BigEndian.PutUint32(data[4:], uint32(len(data)-8))
+
+	d := NewDecoder(bytes.NewReader(data))
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.SSNDOffset != 4 || d.SSNDBlockSize != 8 {
+		t.Fatalf("expected an offset of 4 and 8 byte blocks but got %d and %d", d.SSNDOffset, d.SSNDBlockSize)
+	}
+	if d.PCMLen() != 8 {
+		t.Fatalf("expected 8 bytes of sound data but got %d", d.PCMLen())
+	}
+	if !reflect.DeepEqual(buf.Data, []int{1, 2, 3, 4}) {
+		t.Fatalf("unexpected samples %v", buf.Data)
+	}
+	if !strings.Contains(d.Report(), "offset 4, block size 8") {
+		t.Fatalf("expected the alignment to be reported:\n%s", d.Report())
+	}
+}
+
+func TestDecoder_ReadInfoCOMMLast(t *testing.T) {
+	comm := []byte{0, 1, 0, 0, 0, 4, 0, 16}
+	rate := Float80FromFloat64(22050)
+	comm = append(comm, rate[:]...)
+	comt := []byte{0, 1, 0, 0, 0, 0, 0, 0, 0, 2, 'h', 'i'}
+	ssnd := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 3, 0, 4}
+	// the COMM chunk comes last, after the sound data and the comments
+	data := []byte("FORM\x00\x00\x00\x00AIFF")
+	data = append(data, testChunk("SSND", ssnd)...)
+	data = append(data, testChunk("COMT", comt)...)
+	data = append(data, testChunk("COMM", comm)...)
+	binary.BigEndian.PutUint32(data[4:], uint32(len(data)-8))
+
+	r := bytes.NewReader(data)
+	d := NewDecoder(r)
+	if err := d.ReadInfo(); err != nil {
+		t.Fatal(err)
+	}
+	if d.NumChans != 1 || d.NumSampleFrames != 4 || d.SampleRate != 22050 {
+		t.Fatalf("unexpected format %d channels, %d frames @ %d", d.NumChans, d.NumSampleFrames, d.SampleRate)
+	}
+	// only the FORM header should have been consumed
+	if pos, _ := r.Seek(0, io.SeekCurrent); pos != 12 {
+		t.Fatalf("expected the reader to be at 12 but it's at %d", pos)
+	}
+
+	// the chunks are still iterated in order
+	var ids []string
+	it := d.Chunks()
+	for it.Next() {
+		ids = append(ids, string(it.Chunk().ID[:]))
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []string{"SSND", "COMT", "COMM"}) {
+		t.Fatalf("unexpected chunk order %v", ids)
+	}
+
+	d.Reset()
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(buf.Data, []int{1, 2, 3, 4}) {
+		t.Fatalf("unexpected samples %v", buf.Data)
+	}
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(d.Comments, []string{"hi"}) {
+		t.Fatalf("expected the comment to be parsed once but got %q", d.Comments)
+	}
+
+	// without COMM chunk
+	if err := NewDecoder(bytes.NewReader(data[:len(data)-8-len(comm)])).ReadInfo(); err == nil {
+		t.Fatal("expected an error when the COMM chunk is missing")
+	}
+}
+
+// readSeeker hides the io.ReaderAt implementation of the wrapped reader.
+type readSeeker struct {
+	io.ReadSeeker
+}
+
+func TestDecoder_ReadInfoDoesNotMoveReader(t *testing.T) {
+	testCases := []struct {
+		input    string
+		comments int
+	}{
+		{"fixtures/ring.aif", 1},
+		{"fixtures/ableton.aif", 0},
+		{"fixtures/sowt2.aif", 1},
+	}
+
+	for _, tc := range testCases {
+		for _, wrap := range []bool{false, true} {
+			f, err := os.Open(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			var r io.ReadSeeker = f
+			if wrap {
+				r = readSeeker{f}
+			}
+			d := NewDecoder(r)
+			d.ReadInfo()
+			if err := d.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if d.SampleRate == 0 {
+				t.Fatalf("expected %s to have its sample rate parsed", tc.input)
+			}
+			pos, err := f.Seek(0, io.SeekCurrent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// only the FORM header should have been consumed
+			if pos != 12 {
+				t.Fatalf("expected the reader of %s to be at 12 but was at %d", tc.input, pos)
+			}
+			if _, err := d.FullPCMBuffer(); err != nil {
+				t.Fatal(err)
+			}
+			if err := d.Drain(); err != nil {
+				t.Fatal(err)
+			}
+			if len(d.Comments) != tc.comments {
+				t.Fatalf("expected %d comments in %s but got %d", tc.comments, tc.input, len(d.Comments))
+			}
+		}
+	}
+}
+
+func TestDecoder_HeaderBytes(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(bytes.NewReader(data))
+	header, err := d.HeaderBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// FORM header + COMM chunk + SSND header, offset and block size
+	if len(header) != 54 {
+		t.Fatalf("expected 54 header bytes but got %d", len(header))
+	}
+	if !bytes.Equal(header, data[:54]) {
+		t.Fatal("expected the header bytes to match the start of the file")
+	}
+	if string(header[38:42]) != "SSND" {
+		t.Fatalf("expected the SSND chunk @38 but got %q", header[38:42])
+	}
+}
+
+func TestDecoder_readerNotAtStart(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := bytes.NewReader(data)
+	if _, err := r.Seek(100, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	// the header is read at the start of the reader, like the chunks
+	// located by the index
+	d := NewDecoder(r)
+	if err := d.ReadInfo(); err != nil {
+		t.Fatal(err)
+	}
+	idx, err := d.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.FindAll(SSNDID)) != 1 {
+		t.Fatalf("expected the index to find the SSND chunk, got %+v", idx.Chunks)
+	}
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf.Data) != 4484 {
+		t.Fatalf("expected 4484 samples but got %d", len(buf.Data))
+	}
+}
+
+func TestDecoder_ReadInfoReportsEarlierError(t *testing.T) {
+	f, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	if err := d.ReadInfo(); err != nil {
+		t.Fatal(err)
+	}
+	// reaching the end of the file isn't an error
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.ReadInfo(); err != nil {
+		t.Fatalf("expected no error after draining the file but got %v", err)
+	}
+	d.err = errors.New("read failure")
+	if err := d.ReadInfo(); err != d.err {
+		t.Fatalf("expected the earlier error to be returned but got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-audio/audio"
+)
+
+// PCMBuffer16 works like PCMBuffer but populates the I16 store of the passed
+// buffer, using 2 bytes per sample instead of 8. This is only supported for
+// files with a bit depth of 16 or less.
+// The number of samples read is returned, 0 when no more data is available.
+func (d *Decoder) PCMBuffer16(buf *audio.PCMBuffer) (n int, err error) {
+	if buf == nil {
+		return 0, nil
+	}
+	if err := d.prepareTypedBuffer(buf); err != nil {
+		return 0, err
+	}
+	if d.BitDepth > 16 {
+		return 0, fmt.Errorf("%d bit samples can't be decoded as int16", d.BitDepth)
+	}
+	buf.DataType = audio.DataTypeI16
+	return d.decodeTyped(len(buf.I16), func(i, v int) {
+		buf.I16[i] = int16(v)
+	})
+}
+
+// PCMBuffer32 works like PCMBuffer but populates the I32 store of the passed
+// buffer, using 4 bytes per sample instead of 8.
+// The number of samples read is returned, 0 when no more data is available.
+func (d *Decoder) PCMBuffer32(buf *audio.PCMBuffer) (n int, err error) {
+	if buf == nil {
+		return 0, nil
+	}
+	if err := d.prepareTypedBuffer(buf); err != nil {
+		return 0, err
+	}
+	buf.DataType = audio.DataTypeI32
+	return d.decodeTyped(len(buf.I32), func(i, v int) {
+		buf.I32[i] = int32(v)
+	})
+}
+
+func (d *Decoder) prepareTypedBuffer(buf *audio.PCMBuffer) error {
+	if d == nil {
+		return errors.New("can't decode using a nil decoder")
+	}
+	if !d.WasPCMAccessed() {
+		if err := d.FwdToPCM(); err != nil {
+			return err
+		}
+	}
+	if d.PCMChunk == nil {
+		return fmt.Errorf("PCM chunk not found - %v", d.Err())
+	}
+	buf.Format = d.Format()
+	buf.SourceBitDepth = uint8(d.BitDepth)
+	return nil
+}
+
+// decodeTyped reads up to numSamples samples and passes them to set.
+func (d *Decoder) decodeTyped(numSamples int, set func(i, v int)) (int, error) {
+	if codec, ok := lookupCodec(d.Encoding); ok {
+		buf := &audio.IntBuffer{Data: make([]int, numSamples)}
+		n, err := d.codecPCMBuffer(codec, buf)
+		for i, v := range buf.Data[:n] {
+			set(i, v)
+		}
+		return n, err
+	}
+	bPerSample := bytesPerSample(int(d.BitDepth))
+	if bPerSample < 1 || bPerSample > 4 {
+		return 0, fmt.Errorf("%v bit depth not supported", d.BitDepth)
+	}
+	size := numSamples * bPerSample
+	if remaining := d.pcmRemaining(); remaining < size {
+		size = remaining
+	}
+	raw := make([]byte, size)
+	m, err := io.ReadFull(d.PCMChunk, raw)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	n := m / bPerSample
+	for i := 0; i < n; i++ {
+		set(i, decodeSample(raw[i*bPerSample:(i+1)*bPerSample], d.byteOrder))
+	}
+	return n, err
+}
@@ -0,0 +1,94 @@
+package aiff
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestDecoder_PCMBufferTyped(t *testing.T) {
+	testCases := []struct {
+		input string
+		is16  bool
+	}{
+		{"fixtures/kick.aif", true},
+		{"fixtures/sowt.aif", true},
+		{"fixtures/zipper24b.aiff", false},
+		{"fixtures/kick32b.aiff", false},
+		// compressed data goes through the codec
+		{ulawTestFile(t, []int{1000, -1000, 8000, -8000}), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			f, err := os.Open(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			expected, err := NewDecoder(f).FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decode := func(use16 bool) []int {
+				if _, err := f.Seek(0, io.SeekStart); err != nil {
+					t.Fatal(err)
+				}
+				d := NewDecoder(f)
+				buf := &audio.PCMBuffer{I16: make([]int16, 333), I32: make([]int32, 333)}
+				samples := []int{}
+				for {
+					var n int
+					if use16 {
+						n, err = d.PCMBuffer16(buf)
+					} else {
+						n, err = d.PCMBuffer32(buf)
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+					if n == 0 {
+						break
+					}
+					for i := 0; i < n; i++ {
+						if use16 {
+							samples = append(samples, int(buf.I16[i]))
+						} else {
+							samples = append(samples, int(buf.I32[i]))
+						}
+					}
+				}
+				if int(buf.SourceBitDepth) != expected.SourceBitDepth {
+					t.Fatalf("expected a source bit depth of %d but got %d", expected.SourceBitDepth, buf.SourceBitDepth)
+				}
+				return samples
+			}
+
+			variants := []bool{false}
+			if tc.is16 {
+				variants = append(variants, true)
+			} else {
+				if _, err := f.Seek(0, io.SeekStart); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := NewDecoder(f).PCMBuffer16(&audio.PCMBuffer{I16: make([]int16, 10)}); err == nil {
+					t.Fatal("expected an error decoding a high bit depth file into int16")
+				}
+			}
+			for _, use16 := range variants {
+				samples := decode(use16)
+				if len(samples) != len(expected.Data) {
+					t.Fatalf("expected %d samples but got %d", len(expected.Data), len(samples))
+				}
+				for i, v := range expected.Data {
+					if samples[i] != v {
+						t.Fatalf("sample %d didn't match, expected %d, got %d", i, v, samples[i])
+					}
+				}
+			}
+		})
+	}
+}
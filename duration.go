@@ -0,0 +1,119 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+// FrameCountSource indicates where the number of sample frames comes from.
+type FrameCountSource int
+
+const (
+	// FrameCountFromCOMM is used when the number of frames declared by the
+	// COMM chunk is used.
+	FrameCountFromCOMM FrameCountSource = iota
+	// FrameCountFromSSND is used when the number of frames is derived from
+	// the size of the sound data because the COMM chunk declares 0 frames
+	// or less frames than available (see SizeTrusted).
+	FrameCountFromSSND
+)
+
+func (s FrameCountSource) String() string {
+	switch s {
+	case FrameCountFromCOMM:
+		return "COMM"
+	case FrameCountFromSSND:
+		return "SSND"
+	}
+	return "unknown"
+}
+
+// DurationFrames returns the number of sample frames of the file and its
+// sample rate, allowing sample accurate duration computations.
+// See FrameCountSource.
+func (d *Decoder) DurationFrames() (numFrames int64, sampleRate int) {
+	if d == nil {
+		return 0, 0
+	}
+	d.ReadInfo()
+	numFrames = int64(d.NumSampleFrames)
+	if d.actualFrames > numFrames {
+		numFrames = d.actualFrames
+	}
+	return numFrames, d.SampleRate
+}
+
+// deriveNumFrames sets the number of frames using the size of the sound
+// data when the COMM chunk declares 0 frames.
+func (d *Decoder) deriveNumFrames() {
+	if d.actualFrames > int64(d.NumSampleFrames) {
+		d.FrameCountSource = FrameCountFromSSND
+	}
+	if d.NumSampleFrames > 0 {
+		return
+	}
+	if numFrames := d.ssndFrames(); numFrames > 0 && numFrames <= math.MaxUint32 {
+		d.NumSampleFrames = uint32(numFrames)
+		d.FrameCountSource = FrameCountFromSSND
+	}
+}
+
+// PreciseDuration returns the duration of the file computed using integer
+// math so long files don't lose precision. The result is truncated to the
+// nanosecond.
+func (d *Decoder) PreciseDuration() (time.Duration, error) {
+	if d == nil {
+		return 0, errors.New("can't calculate the duration of a nil pointer")
+	}
+	numFrames, sampleRate := d.DurationFrames()
+	if err := d.Err(); err != nil {
+		return 0, err
+	}
+	if sampleRate < 1 {
+		return 0, errors.New("can't calculate the duration without a sample rate")
+	}
+	rate := int64(sampleRate)
+	secs, rem := numFrames/rate, numFrames%rate
+	return time.Duration(secs)*time.Second + time.Duration(rem*int64(time.Second)/rate), nil
+}
+
+// framesFor returns the number of whole frames lasting dur at the
+// passed sample rate, the counterpart of PreciseDuration. Integer math is
+// used so durations such as 700ms are sample accurate.
+func framesFor(dur time.Duration, sampleRate int) int64 {
+	rate := int64(sampleRate)
+	secs, rem := int64(dur/time.Second), int64(dur%time.Second)
+	return secs*rate + rem*rate/int64(time.Second)
+}
+
+// ssndFrames returns the number of frames held by the SSND chunk, 0 if
+// it can't be found or if the encoding isn't PCM.
+func (d *Decoder) ssndFrames() int64 {
+	frameSize := int64(bytesPerSample(int(d.BitDepth)) * int(d.NumChans))
+	if _, ok := pcmByteOrder(d.Encoding); !ok || frameSize < 1 {
+		return 0
+	}
+	fileSize, err := d.fileSize()
+	if err != nil {
+		return 0
+	}
+	ssnd, ok := d.ssndInfo()
+	if !ok || ssnd.Offset+16 > fileSize {
+		return 0
+	}
+	var dataOffset [4]byte
+	if _, err := d.ra.ReadAt(dataOffset[:], ssnd.Offset+8); err != nil {
+		return 0
+	}
+	dataSize := int64(ssnd.Size)
+	if available := fileSize - ssnd.Offset - 8; available < dataSize {
+		dataSize = available
+	}
+	dataSize -= 8 + int64(binary.BigEndian.Uint32(dataOffset[:]))
+	if dataSize < 0 {
+		return 0
+	}
+	return dataSize / frameSize
+}
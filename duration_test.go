@@ -0,0 +1,92 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDecoder_PreciseDuration(t *testing.T) {
+	f, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	numFrames, sampleRate := d.DurationFrames()
+	if numFrames != 4484 || sampleRate != 22050 {
+		t.Fatalf("expected 4484 frames @ 22050 but got %d @ %d", numFrames, sampleRate)
+	}
+	if d.FrameCountSource != FrameCountFromCOMM {
+		t.Fatalf("expected the frame count to come from the COMM chunk but got %s", d.FrameCountSource)
+	}
+	dur, err := d.PreciseDuration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dur != 203356009 {
+		t.Fatalf("unexpected duration: %d", dur)
+	}
+
+	// largest number of frames, float math would be off
+	d = &Decoder{SampleRate: 44100, NumSampleFrames: 4294967295}
+	dur, err = d.PreciseDuration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := 97391*time.Second + 548639455; dur != expected {
+		t.Fatalf("expected %d but got %d", expected, dur)
+	}
+}
+
+func TestDecoder_DurationFramesFromSSND(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// zero the number of frames of the COMM chunk
+	binary.BigEndian.PutUint32(data[22:], 0)
+	d := NewDecoder(bytes.NewReader(data))
+	if !d.IsValidFile() {
+		t.Fatal("expected a file without a frame count to be valid")
+	}
+	numFrames, _ := d.DurationFrames()
+	if numFrames != 4484 || d.NumSampleFrames != 4484 {
+		t.Fatalf("expected the number of frames to be derived from the SSND chunk, got %d", numFrames)
+	}
+	if d.FrameCountSource != FrameCountFromSSND {
+		t.Fatalf("expected the frame count to come from the SSND chunk but got %s", d.FrameCountSource)
+	}
+	if _, length, err := d.PCMOffset(); err != nil || length != 8968 {
+		t.Fatalf("expected 8968 bytes of sound data but got %d (%v)", length, err)
+	}
+	dur, err := d.Duration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dur != 203356009 {
+		t.Fatalf("unexpected duration: %d", dur)
+	}
+}
+
+func TestFramesFor(t *testing.T) {
+	testCases := []struct {
+		dur        time.Duration
+		sampleRate int
+		expected   int64
+	}{
+		{700 * time.Millisecond, 44100, 30870},
+		{time.Second, 48000, 48000},
+		{50 * time.Millisecond, 22050, 1102},
+		// largest duration PreciseDuration reports for a 44.1kHz file
+		{97391*time.Second + 548639455, 44100, 4294967294},
+	}
+	for _, tc := range testCases {
+		if n := framesFor(tc.dur, tc.sampleRate); n != tc.expected {
+			t.Errorf("%v @ %d: expected %d frames but got %d", tc.dur, tc.sampleRate, tc.expected, n)
+		}
+	}
+}
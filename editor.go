@@ -0,0 +1,99 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-audio/audio"
+)
+
+// Editor modifies the sound data of an existing file in place. Only the
+// bytes of the edited frames are written so regions of very large files can
+// be replaced (punch-in recording, censoring...) without rewriting them.
+// Replacing regions never changes the size of the file or its headers, the
+// chunks can also be deleted or reordered (see DeleteChunk and Reorder).
+type Editor struct {
+	rw io.ReadWriteSeeker
+
+	SampleRate int
+	BitDepth   int
+	NumChans   int
+	// NumFrames is the number of frames of the sound data.
+	NumFrames int64
+
+	byteOrder binary.ByteOrder
+	pcmStart  int64
+}
+
+// NewEditor parses the headers of the passed file and returns an editor
+// for its sound data. Only uncompressed files can be edited.
+func NewEditor(rw io.ReadWriteSeeker) (*Editor, error) {
+	if rw == nil {
+		return nil, errors.New("can't edit a nil pointer")
+	}
+	d := NewDecoder(rw)
+	if err := d.ReadInfo(); err != nil {
+		return nil, err
+	}
+	if _, ok := pcmByteOrder(d.Encoding); !ok {
+		return nil, fmt.Errorf("%s - %q encoding can't be edited", ErrFmtNotSupported, d.Encoding)
+	}
+	frameSize := int64(bytesPerSample(int(d.BitDepth)) * int(d.NumChans))
+	if frameSize < 1 {
+		return nil, fmt.Errorf("%s - %d channels @ %d bits", ErrFmtNotSupported, d.NumChans, d.BitDepth)
+	}
+	start, length, err := d.PCMOffset()
+	if err != nil {
+		return nil, err
+	}
+	return &Editor{
+		rw:         rw,
+		SampleRate: d.SampleRate,
+		BitDepth:   int(d.BitDepth),
+		NumChans:   int(d.NumChans),
+		NumFrames:  length / frameSize,
+		byteOrder:  d.byteOrder,
+		pcmStart:   start,
+	}, nil
+}
+
+// ReplaceRegion overwrites the frames starting at startFrame with the
+// content of the buffer. The format of the buffer must match the one of the
+// file and the region must fit within the existing sound data.
+func (ed *Editor) ReplaceRegion(startFrame int64, buf *audio.IntBuffer) error {
+	if ed == nil || buf == nil {
+		return errors.New("can't replace a region using a nil pointer")
+	}
+	if buf.Format != nil {
+		if buf.Format.NumChannels != ed.NumChans || buf.Format.SampleRate != ed.SampleRate {
+			return fmt.Errorf("format mismatch, can't write %d channels @ %d in a file with %d channels @ %d",
+				buf.Format.NumChannels, buf.Format.SampleRate, ed.NumChans, ed.SampleRate)
+		}
+	}
+	if buf.SourceBitDepth != 0 && buf.SourceBitDepth != ed.BitDepth {
+		return fmt.Errorf("bit depth mismatch, can't write %d bit samples in a %d bit file", buf.SourceBitDepth, ed.BitDepth)
+	}
+	if len(buf.Data)%ed.NumChans != 0 {
+		return fmt.Errorf("the buffer doesn't contain full frames (%d samples for %d channels)", len(buf.Data), ed.NumChans)
+	}
+	numFrames := int64(len(buf.Data) / ed.NumChans)
+	if startFrame < 0 || startFrame+numFrames > ed.NumFrames {
+		return fmt.Errorf("region [%d, %d) out of range [0, %d)", startFrame, startFrame+numFrames, ed.NumFrames)
+	}
+
+	frameSize := int64(bytesPerSample(ed.BitDepth) * ed.NumChans)
+	bb := bytes.NewBuffer(make([]byte, 0, numFrames*frameSize))
+	for _, v := range buf.Data {
+		if err := writePCMSample(bb, ed.byteOrder, ed.BitDepth, v); err != nil {
+			return err
+		}
+	}
+	if _, err := ed.rw.Seek(ed.pcmStart+startFrame*frameSize, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := ed.rw.Write(bb.Bytes())
+	return err
+}
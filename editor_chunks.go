@@ -0,0 +1,244 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// editBufferSize is the size of the buffer used to move data around when
+// editing the chunk table, whatever the size of the file.
+const editBufferSize = 64 << 10
+
+// truncater is implemented by the writers which can be shrunk, such as
+// *os.File.
+type truncater interface {
+	Truncate(size int64) error
+}
+
+// Chunks returns the table of the chunks of the edited file.
+func (ed *Editor) Chunks() ([]ChunkInfo, error) {
+	if ed == nil {
+		return nil, errors.New("can't index a nil editor")
+	}
+	if _, err := ed.rw.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	idx, err := NewDecoder(ed.rw).Index()
+	if err != nil {
+		return nil, err
+	}
+	return idx.Chunks, nil
+}
+
+// DeleteChunk removes the first chunk matching the ID from the file. The
+// following chunks are moved back using a bounded buffer and the file is
+// truncated, the writer must implement Truncate(size int64) error (as
+// *os.File does). The COMM and SSND chunks can't be deleted.
+func (ed *Editor) DeleteChunk(id [4]byte) error {
+	if id == COMMID || id == SSNDID {
+		return fmt.Errorf("the %q chunk is required and can't be deleted", id[:])
+	}
+	chunks, err := ed.Chunks()
+	if err != nil {
+		return err
+	}
+	t, ok := ed.rw.(truncater)
+	if !ok {
+		return errors.New("the file can't be truncated, the writer doesn't implement Truncate")
+	}
+	i := (&ChunkIndex{Chunks: chunks}).Find(id)
+	if i < 0 {
+		return fmt.Errorf("%q chunk not found", id[:])
+	}
+	fileEnd, err := ed.rw.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	start := chunks[i].Offset
+	end := start + chunkLen(chunks[i])
+	if end > fileEnd {
+		// missing pad byte at the end of the file
+		end = fileEnd
+	}
+	if err := ed.moveBytes(start, end, fileEnd-end); err != nil {
+		return err
+	}
+	newSize := fileEnd - (end - start)
+	if err := t.Truncate(newSize); err != nil {
+		return err
+	}
+	if err := ed.writeFormSize(newSize); err != nil {
+		return err
+	}
+	return ed.reload()
+}
+
+// Reorder rearranges the chunks of the file in place, order lists the
+// positions of the chunks (see Chunks) in their new order and must contain
+// each of them once. The size of the file doesn't change, the data is moved
+// using a bounded buffer.
+func (ed *Editor) Reorder(order []int) error {
+	chunks, err := ed.Chunks()
+	if err != nil {
+		return err
+	}
+	if len(order) != len(chunks) {
+		return fmt.Errorf("expected the order of %d chunks but got %d positions", len(chunks), len(order))
+	}
+	fileEnd, err := ed.rw.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if last := chunks[len(chunks)-1]; last.Offset+chunkLen(last) > fileEnd {
+		return fmt.Errorf("the last chunk goes past the end of the file (%d bytes)", fileEnd)
+	}
+	seen := make([]bool, len(chunks))
+	for _, i := range order {
+		if i < 0 || i >= len(chunks) || seen[i] {
+			return fmt.Errorf("invalid chunk order %v", order)
+		}
+		seen[i] = true
+	}
+
+	// the chunk meant to be at each position is brought in place by rotating
+	// the region going from this position to the end of the chunk, the
+	// chunks in between keep their relative order.
+	// current holds the original positions of the chunks in the file.
+	current := make([]int, len(chunks))
+	for i := range current {
+		current[i] = i
+	}
+	offsetOf := func(pos int) int64 {
+		offset := chunks[0].Offset
+		for _, i := range current[:pos] {
+			offset += chunkLen(chunks[i])
+		}
+		return offset
+	}
+	for pos, want := range order {
+		j := pos
+		for current[j] != want {
+			j++
+		}
+		if j == pos {
+			continue
+		}
+		start, mid := offsetOf(pos), offsetOf(j)
+		if err := ed.rotate(start, mid, mid+chunkLen(chunks[want])); err != nil {
+			return err
+		}
+		copy(current[pos+1:j+1], current[pos:j])
+		current[pos] = want
+	}
+	return ed.reload()
+}
+
+// reload parses the headers again after the chunks were moved.
+func (ed *Editor) reload() error {
+	if _, err := ed.rw.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	fresh, err := NewEditor(ed.rw)
+	if err != nil {
+		return err
+	}
+	*ed = *fresh
+	return nil
+}
+
+// writeFormSize updates the size of the FORM chunk.
+func (ed *Editor) writeFormSize(fileSize int64) error {
+	if _, err := ed.rw.Seek(4, io.SeekStart); err != nil {
+		return err
+	}
+	return binary.Write(ed.rw, binary.BigEndian, uint32(fileSize-8))
+}
+
+// moveBytes copies n bytes from src to dst, dst being before src.
+func (ed *Editor) moveBytes(dst, src, n int64) error {
+	buf := make([]byte, editBufferSize)
+	for n > 0 {
+		size := int64(len(buf))
+		if n < size {
+			size = n
+		}
+		if err := ed.readAt(buf[:size], src); err != nil {
+			return err
+		}
+		if err := ed.writeAt(buf[:size], dst); err != nil {
+			return err
+		}
+		src, dst, n = src+size, dst+size, n-size
+	}
+	return nil
+}
+
+// rotate swaps the [start, mid) and [mid, end) regions of the file.
+func (ed *Editor) rotate(start, mid, end int64) error {
+	if err := ed.reverse(start, mid); err != nil {
+		return err
+	}
+	if err := ed.reverse(mid, end); err != nil {
+		return err
+	}
+	return ed.reverse(start, end)
+}
+
+// reverse reverses the bytes of the [start, end) region of the file.
+func (ed *Editor) reverse(start, end int64) error {
+	left := make([]byte, editBufferSize/2)
+	right := make([]byte, editBufferSize/2)
+	for end-start > 1 {
+		size := (end - start) / 2
+		if size > int64(len(left)) {
+			size = int64(len(left))
+		}
+		l, r := left[:size], right[:size]
+		if err := ed.readAt(l, start); err != nil {
+			return err
+		}
+		if err := ed.readAt(r, end-size); err != nil {
+			return err
+		}
+		reverseBytes(l)
+		reverseBytes(r)
+		if err := ed.writeAt(r, start); err != nil {
+			return err
+		}
+		if err := ed.writeAt(l, end-size); err != nil {
+			return err
+		}
+		start, end = start+size, end-size
+	}
+	return nil
+}
+
+func (ed *Editor) readAt(p []byte, offset int64) error {
+	if _, err := ed.rw.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.ReadFull(ed.rw, p)
+	return err
+}
+
+func (ed *Editor) writeAt(p []byte, offset int64) error {
+	if _, err := ed.rw.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := ed.rw.Write(p)
+	return err
+}
+
+func reverseBytes(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+// chunkLen returns the number of bytes taken by a chunk in the file, header
+// and pad byte included.
+func chunkLen(c ChunkInfo) int64 {
+	return 8 + int64(c.Size) + int64(c.Size%2)
+}
@@ -0,0 +1,240 @@
+package aiff
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestEditor_ReplaceRegion(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	testCases := []string{
+		"fixtures/kick.aif",
+		"fixtures/sowt.aif",
+		"fixtures/zipper24b.aiff",
+	}
+	for _, path := range testCases {
+		t.Run(path, func(t *testing.T) {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := "testOutput/edited.aif"
+			if err := ioutil.WriteFile(out, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(out)
+			f, err := os.OpenFile(out, os.O_RDWR, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			orig, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer orig.Close()
+			expected, err := NewDecoder(orig).FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ed, err := NewEditor(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			numChans := ed.NumChans
+			start, numFrames := 100, 50
+			region := &audio.IntBuffer{
+				Format:         &audio.Format{NumChannels: numChans, SampleRate: ed.SampleRate},
+				SourceBitDepth: ed.BitDepth,
+				Data:           make([]int, numFrames*numChans),
+			}
+			for i := range region.Data {
+				region.Data[i] = i - 50
+			}
+			if err := ed.ReplaceRegion(int64(start), region); err != nil {
+				t.Fatal(err)
+			}
+			copy(expected.Data[start*numChans:], region.Data)
+
+			info, err := f.Stat()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Size() != int64(len(data)) {
+				t.Fatalf("expected the file size to stay %d but got %d", len(data), info.Size())
+			}
+			if _, err := f.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			buf, err := NewDecoder(f).FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(buf.Data) != len(expected.Data) {
+				t.Fatalf("expected %d samples but got %d", len(expected.Data), len(buf.Data))
+			}
+			for i, v := range expected.Data {
+				if buf.Data[i] != v {
+					t.Fatalf("sample %d: expected %d, got %d", i, v, buf.Data[i])
+				}
+			}
+
+			// the region must fit in the existing data
+			if err := ed.ReplaceRegion(ed.NumFrames-10, region); err == nil {
+				t.Fatal("expected an error when writing past the end of the sound data")
+			}
+			region.Format.SampleRate++
+			if err := ed.ReplaceRegion(0, region); err == nil {
+				t.Fatal("expected an error when the formats don't match")
+			}
+		})
+	}
+}
+
+// chunkContents returns the data of each chunk of the file keyed by ID.
+func chunkContents(t *testing.T, r io.ReadSeeker) (ids []string, contents map[string][]byte) {
+	t.Helper()
+	idx, err := NewDecoder(r).Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents = map[string][]byte{}
+	for i, info := range idx.Chunks {
+		c, err := idx.OpenChunkAt(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := c.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, string(info.ID[:]))
+		contents[string(info.ID[:])] = b
+	}
+	return ids, contents
+}
+
+func TestEditor_Chunks(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	data, err := ioutil.ReadFile("fixtures/sowt2.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, original := chunkContents(t, bytes.NewReader(data))
+	expected, err := NewDecoder(bytes.NewReader(data)).FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := "testOutput/edited_chunks.aif"
+	if err := ioutil.WriteFile(out, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out)
+	f, err := os.OpenFile(out, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	ed, err := NewEditor(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(t *testing.T, expectedIDs []string) {
+		t.Helper()
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		ids, contents := chunkContents(t, f)
+		if !reflect.DeepEqual(ids, expectedIDs) {
+			t.Fatalf("expected the chunks to be %q but got %q", expectedIDs, ids)
+		}
+		for id, b := range contents {
+			if !bytes.Equal(b, original[id]) {
+				t.Fatalf("the content of the %q chunk changed", id)
+			}
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		d := NewDecoder(f)
+		for _, issue := range d.Validate() {
+			if issue.Severity == SeverityError {
+				t.Fatalf("unexpected issue: %s", issue)
+			}
+		}
+		buf, err := d.FullPCMBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(buf.Data, expected.Data) {
+			t.Fatal("the sound data changed")
+		}
+	}
+
+	// COMM, (c) , COMT, AFmd, SSND, AFAn
+	if err := ed.Reorder([]int{5, 0, 2, 4, 1, 3}); err != nil {
+		t.Fatal(err)
+	}
+	check(t, []string{"AFAn", "COMM", "COMT", "SSND", "(c) ", "AFmd"})
+	if info, _ := f.Stat(); info.Size() != int64(len(data)) {
+		t.Fatalf("expected the file size to stay %d but got %d", len(data), info.Size())
+	}
+	if err := ed.Reorder([]int{0, 0, 1, 2, 3, 4}); err == nil {
+		t.Fatal("expected an error when the order isn't a permutation")
+	}
+
+	if err := ed.DeleteChunk([4]byte{'A', 'F', 'A', 'n'}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ed.DeleteChunk(copyrightID); err != nil {
+		t.Fatal(err)
+	}
+	check(t, []string{"COMM", "COMT", "SSND", "AFmd"})
+	if err := ed.DeleteChunk(COMMID); err == nil {
+		t.Fatal("expected an error deleting the COMM chunk")
+	}
+	if err := ed.DeleteChunk(copyrightID); err == nil {
+		t.Fatal("expected an error deleting a missing chunk")
+	}
+	// the editor keeps track of the moved sound data
+	region := &audio.IntBuffer{Format: &audio.Format{NumChannels: 2, SampleRate: 44100}, Data: []int{1, 2, 3, 4}}
+	if err := ed.ReplaceRegion(0, region); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := NewDecoder(f).FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(buf.Data[:4], region.Data) {
+		t.Fatalf("expected the region to be replaced but got %v", buf.Data[:4])
+	}
+
+	// the file can't be shrunk without Truncate
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	ed, err = NewEditor(readWriteSeeker{f})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ed.DeleteChunk(COMTID); err == nil {
+		t.Fatal("expected an error deleting a chunk of a file which can't be truncated")
+	}
+}
+
+// readWriteSeeker hides the Truncate method of the wrapped file.
+type readWriteSeeker struct {
+	io.ReadWriteSeeker
+}
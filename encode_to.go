@@ -0,0 +1,113 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-audio/audio"
+)
+
+// encodeToFrames is the number of frames converted at once by EncodeTo.
+const encodeToFrames = 4096
+
+// EncodeTo streams the remaining sound data of the decoder to the encoder
+// block by block and returns the number of frames written. The samples are
+// converted to the bit depth of the encoder, the sample rate and number of
+// channels must match. When the sound data doesn't need to be decoded
+// (uncompressed PCM, same bit depth, no sample transform), the bytes are
+// copied as is which is the fastest way to re-encode a file. The same goes
+// for compressed data when both use the same encoding and the encoder
+// didn't encode any sample yet.
+// An incomplete frame at the end of the data is dropped.
+// Note that the encoder isn't closed.
+func (d *Decoder) EncodeTo(e *Encoder) (int64, error) {
+	if d == nil || e == nil {
+		return 0, errors.New("can't encode from or to a nil pointer")
+	}
+	if err := d.ReadInfo(); err != nil {
+		return 0, err
+	}
+	if d.NumChans < 1 {
+		return 0, fmt.Errorf("invalid number of channels: %d", d.NumChans)
+	}
+	if int(d.NumChans) != e.NumChans || d.SampleRate != e.SampleRate {
+		return 0, fmt.Errorf("format mismatch, can't encode %d channels @ %d as %d channels @ %d",
+			d.NumChans, d.SampleRate, e.NumChans, e.SampleRate)
+	}
+	rawCopy := !d.WasPCMAccessed()
+	if rawCopy {
+		if err := d.FwdToPCM(); err != nil {
+			return 0, fmt.Errorf("failed to forward to PCM - %v", err)
+		}
+	}
+	if err := e.startPCMChunk(); err != nil {
+		return 0, err
+	}
+
+	if rawCopy && int(d.BitDepth) == e.BitDepth && e.sampleEncoder == nil && len(e.transforms) == 0 {
+		if numFrames, err := d.rawPCMFrames(); err == nil {
+			n, err := copyRawFrames(e, d, numFrames)
+			return int64(n), err
+		}
+	}
+
+	if rawCopy && d.Encoding == e.Encoding && e.sampleEncoder != nil && e.frames == 0 &&
+		int(d.BitDepth) == e.BitDepth && len(e.transforms) == 0 {
+		n, err := copyEncodedData(e, d)
+		return int64(n), err
+	}
+
+	numChans := int(d.NumChans)
+	format := &audio.Format{NumChannels: numChans, SampleRate: d.SampleRate}
+	data := make([]int, encodeToFrames*numChans)
+	var (
+		frames int64
+		// samples of an incomplete frame carried over to the next read
+		pending int
+	)
+	for {
+		n, err := d.PCMBuffer(&audio.IntBuffer{Data: data[pending:]})
+		if err != nil {
+			return frames, err
+		}
+		if n == 0 {
+			return frames, nil
+		}
+		total := pending + n
+		whole := total - total%numChans
+		if whole > 0 {
+			samples := data[:whole]
+			if int(d.BitDepth) != e.BitDepth {
+				for i, v := range samples {
+					samples[i] = signedSample(v, int(d.BitDepth))
+				}
+				ConvertSamples(samples, samples, int(d.BitDepth), e.BitDepth)
+			}
+			if err := e.Write(&audio.IntBuffer{Format: format, SourceBitDepth: e.BitDepth, Data: samples}); err != nil {
+				return frames, err
+			}
+			frames += int64(whole / numChans)
+		}
+		pending = copy(data, data[whole:total])
+	}
+}
+
+// copyEncodedData copies the remaining compressed sound data of the decoder
+// to the encoder without decoding it. The number of frames declared by the
+// decoder is returned since it can't be derived from the size of the data.
+func copyEncodedData(e *Encoder, d *Decoder) (int, error) {
+	if err := e.checkPCMSize(int64(d.PCMChunk.Remaining())); err != nil {
+		return 0, err
+	}
+	if _, err := io.CopyN(encoderWriter{e}, d.PCMChunk, int64(d.PCMChunk.Remaining())); err != nil {
+		return 0, err
+	}
+	e.frames += int(d.NumSampleFrames)
+	return int(d.NumSampleFrames), nil
+}
+
+// EncodeFrom is the Encoder counterpart of Decoder.EncodeTo.
+func (e *Encoder) EncodeFrom(d *Decoder) (int64, error) {
+	return d.EncodeTo(e)
+}
@@ -0,0 +1,170 @@
+package aiff
+
+import (
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestDecoder_EncodeTo(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	testCases := []struct {
+		in       string
+		bitDepth int
+		encoding Encoding
+		frames   int64
+	}{
+		// raw copies
+		{"fixtures/kick.aif", 16, EncNotSet, 4484},
+		{"fixtures/sowt.aif", 16, EncNotSet, 4064},
+		{"fixtures/kick.aif", 16, EncSowt, 4484},
+		{"fixtures/zipper24b.aiff", 24, Enc23ni, 105936},
+		// conversions
+		{"fixtures/zipper24b.aiff", 16, EncNotSet, 105936},
+		{"fixtures/kick8b.aiff", 16, EncNotSet, 4484},
+		{"fixtures/kick.aif", 24, EncNotSet, 4484},
+		{"fixtures/kick.aif", 16, EncUlaw, 4484},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			in, err := os.Open(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer in.Close()
+			out, err := os.Create("testOutput/encode_to.aif")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(out.Name())
+			defer out.Close()
+
+			d := NewDecoder(in)
+			d.ReadInfo()
+			e := NewEncoder(out, d.SampleRate, tc.bitDepth, int(d.NumChans))
+			e.Encoding = tc.encoding
+			n, err := d.EncodeTo(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tc.frames {
+				t.Fatalf("expected %d frames to be written but got %d", tc.frames, n)
+			}
+			if err := e.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := in.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			// the precision lost by the conversions
+			epsilon := 0
+			switch {
+			case tc.encoding == EncUlaw:
+				epsilon = 1024
+			case int(d.BitDepth) > tc.bitDepth:
+				epsilon = 1<<uint(int(d.BitDepth)-tc.bitDepth) - 1
+			}
+			ok, diffs := Equal(in, out, IgnoreMetadata(), SampleTolerance(epsilon))
+			if !ok && (int(d.BitDepth) == tc.bitDepth || len(diffs) != 1 || diffs[0].Field != "bit_depth") {
+				t.Fatalf("expected the sound data to match but got %v", diffs)
+			}
+		})
+	}
+}
+
+func TestDecoder_EncodeTo_formatMismatch(t *testing.T) {
+	in, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	out, err := os.Create("testOutput/encode_to_mismatch.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	e := NewEncoder(out, 44100, 16, 1)
+	if _, err := NewDecoder(in).EncodeTo(e); err == nil {
+		t.Fatal("expected the sample rate mismatch to be reported")
+	}
+}
+
+// copyOnlyCodec encodes like deltaCodec but can't decode, making sure the
+// data is copied without being decoded.
+type copyOnlyCodec struct{ deltaCodec }
+
+func (copyOnlyCodec) NewSampleDecoder(r io.Reader, numChans, bitDepth int) (SampleDecoder, error) {
+	return nil, errors.New("decoding isn't supported")
+}
+
+func TestDecoder_EncodeTo_compressedCopy(t *testing.T) {
+	id := Encoding{'d', 'l', 't', 'a'}
+	RegisterCodec(id, deltaCodec{})
+	defer RegisterCodec(id, nil)
+
+	os.Mkdir("testOutput", 0777)
+	src, err := os.Create("testOutput/encode_to_compressed_src.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(src.Name())
+	defer src.Close()
+	samples := []int{0, 10, 20, -30, 40, 50, -60}
+	e := NewEncoder(src, 22050, 16, 1)
+	e.Encoding = id
+	if err := e.Write(&audio.IntBuffer{Data: samples, Format: &audio.Format{NumChannels: 1, SampleRate: 22050}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	RegisterCodec(id, copyOnlyCodec{})
+	if _, err := src.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.Create("testOutput/encode_to_compressed.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	e = NewEncoder(out, 22050, 16, 1)
+	e.Encoding = id
+	n, err := NewDecoder(src).EncodeTo(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(samples)) {
+		t.Fatalf("expected %d frames to be copied but got %d", len(samples), n)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	RegisterCodec(id, deltaCodec{})
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(out)
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.NumSampleFrames != uint32(len(samples)) {
+		t.Fatalf("expected %d frames but got %d", len(samples), d.NumSampleFrames)
+	}
+	if !reflect.DeepEqual(buf.Data, samples) {
+		t.Fatalf("expected %v but got %v", samples, buf.Data)
+	}
+}
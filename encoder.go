@@ -4,23 +4,99 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"hash"
+	"hash/crc32"
 	"io"
+	"math"
 	"os"
+	"time"
 
 	"github.com/go-audio/audio"
 )
 
+// maxFileSize is the size of the largest file that can be described by the
+// 32-bit FORM size (which doesn't include the FORM ID and size).
+const maxFileSize = math.MaxUint32 + 8
+
+// encodeBlockSize is the maximum number of bytes of sound data serialized
+// before being written.
+const encodeBlockSize = 64 << 10
+
 // Encoder encodes LPCM data into an aiff content.
 type Encoder struct {
 	w          io.WriteSeeker
 	SampleRate int
 	BitDepth   int
 	NumChans   int
+	// ExactSampleRate takes precedence over SampleRate when set, allowing non
+	// integer sample rates to be written.
+	ExactSampleRate float64
+
+	// Markers are written in a MARK chunk when set.
+	Markers []Marker
+	// Comments are written in a COMT chunk when set.
+	Comments []Comment
+	// BroadcastInfo is written in an APPL chunk when set, see BroadcastInfo.
+	BroadcastInfo *BroadcastInfo
+	// ChannelLayout is written in a CHAN chunk when set, see ChannelLabels.
+	ChannelLayout *ChannelLayout
+	// AppleInfo is written in a basc chunk when set, the tags aren't
+	// written.
+	AppleInfo *AppleMetadata
+	// Tempo is the tempo in BPM of the audio, when set the number of beats
+	// of the basc chunk is computed from the duration of the written audio
+	// (see BeatsFromDuration) and the Beats field of AppleInfo is ignored.
+	// A 4/4 time signature is used if AppleInfo isn't set.
+	Tempo float64
+	// Gapless is written as an iTunSMPB comment in an ID3 chunk when set.
+	// The original number of frames is computed from the written frames if
+	// not set.
+	Gapless *GaplessInfo
+	// Checksum appends an APPL chunk holding the CRC-32 of the sound data
+	// when the encoder is closed, see Decoder.VerifyChecksum.
+	Checksum bool
+
+	// Encoding is the AIFC encoding to use, an AIFF file is written if not set
+	// (unless FormType is FormAIFC). Only uncompressed encodings are supported.
+	Encoding Encoding
+	// FormType forces the type of file to write, by default it is picked
+	// based on the encoding, see Form.
+	FormType FormType
+	// EncodingName is the AIFC compression name, the standard name of the
+	// encoding is used if not set.
+	EncodingName string
+	// RawCOMM is written as the content of the COMM chunk when set, only the
+	// number of frames is updated. It allows the exact header of a decoded
+	// file to be written back (see Decoder.KeepRawCOMM), the sample rate
+	// isn't converted and the channels, bit depth and encoding must match
+	// the ones of the encoder.
+	RawCOMM []byte
 
 	WrittenBytes    int
 	frames          int
 	pcmChunkStarted bool
 	pcmChunkSizePos int
+	// position of the number of frames in the COMM chunk
+	numFramesPos int
+	// position of the number of beats in the basc chunk
+	beatsPos int
+	// position of the original number of frames in the iTunSMPB comment
+	gaplessPos int
+	byteOrder  binary.ByteOrder
+	// encoder of the registered codec matching the encoding
+	sampleEncoder SampleEncoder
+
+	transforms []func(frame []int)
+
+	// format and source bit depth of the first written buffer
+	bufFormat      *audio.Format
+	sourceBitDepth int
+	// first inconsistency detected between the written buffers
+	mismatchErr error
+	// scratch buffer holding the serialized samples before they are written
+	pcmBuf []byte
+	// checksum of the sound data when Checksum is set
+	crc hash.Hash32
 }
 
 // NewEncoder creates a new encoder to create a new aiff file.
@@ -31,9 +107,21 @@ func NewEncoder(w io.WriteSeeker, sampleRate, bitDepth, numChans int) *Encoder {
 		SampleRate: sampleRate,
 		BitDepth:   bitDepth,
 		NumChans:   numChans,
+		byteOrder:  binary.BigEndian,
 	}
 }
 
+// WithSampleTransform registers a function called with each frame (one
+// sample per channel) before it gets written. The transform can modify the
+// samples in place, the buffer passed to Write isn't altered.
+// Transforms are applied in the order they were registered.
+func (e *Encoder) WithSampleTransform(fn func(frame []int)) *Encoder {
+	if fn != nil {
+		e.transforms = append(e.transforms, fn)
+	}
+	return e
+}
+
 // AddBE serializes and adds the passed value using big endian
 func (e *Encoder) AddBE(src interface{}) error {
 	e.WrittenBytes += binary.Size(src)
@@ -50,42 +138,194 @@ func (e *Encoder) addBuffer(buf *audio.IntBuffer) error {
 	if buf == nil {
 		return fmt.Errorf("can't add a nil buffer")
 	}
+	if buf.Format == nil {
+		return fmt.Errorf("can't add a buffer without a format")
+	}
+	if buf.Format.NumChannels != e.NumChans {
+		return fmt.Errorf("can't add a buffer with %d channels to a %d channel file", buf.Format.NumChannels, e.NumChans)
+	}
 
+	e.checkBufferFormat(buf)
+
+	if e.byteOrder == nil {
+		e.byteOrder = binary.BigEndian
+	}
 	frameCount := buf.NumFrames()
-	// setup a buffer so we don't do many writes
-	bb := bytes.NewBuffer(nil)
-	var err error
-	for i := 0; i < frameCount; i++ {
-		for j := 0; j < buf.Format.NumChannels; j++ {
-			v := buf.Data[i*buf.Format.NumChannels+j]
-			switch e.BitDepth {
-			case 8:
-				if err = binary.Write(bb, binary.BigEndian, uint8(v)); err != nil {
-					return err
-				}
-			case 16:
-				if err = binary.Write(bb, binary.BigEndian, int16(v)); err != nil {
-					return err
+	// the size of compressed data is checked when written, see writePCM
+	if e.sampleEncoder == nil {
+		dataSize := int64(frameCount) * int64(buf.Format.NumChannels) * int64(bytesPerSample(e.BitDepth))
+		if err := e.checkPCMSize(dataSize); err != nil {
+			return err
+		}
+	}
+	if e.sampleEncoder != nil {
+		samples := buf.Data[:frameCount*buf.Format.NumChannels]
+		if len(e.transforms) > 0 {
+			samples = append([]int(nil), samples...)
+			for i := 0; i < frameCount; i++ {
+				for _, fn := range e.transforms {
+					fn(Frame(samples, buf.Format.NumChannels, i))
 				}
-			case 24:
-				if err = binary.Write(bb, binary.BigEndian, audio.Int32toInt24BEBytes(int32(v))); err != nil {
-					return err
+			}
+		}
+		if err := e.sampleEncoder.EncodeSamples(samples); err != nil {
+			return err
+		}
+		e.frames += frameCount
+		return nil
+	}
+	put := pcmSamplePutFunc(e.BitDepth, e.byteOrder)
+	if put == nil {
+		return fmt.Errorf("can't add frames of bit size %d", e.BitDepth)
+	}
+	numChans := buf.Format.NumChannels
+	bPerSample := bytesPerSample(e.BitDepth)
+	frameSize := bPerSample * numChans
+	// the samples are serialized in blocks of whole frames so we don't do
+	// many writes
+	blockFrames := encodeBlockSize / frameSize
+	if blockFrames < 1 {
+		blockFrames = 1
+	}
+	if cap(e.pcmBuf) < blockFrames*frameSize {
+		e.pcmBuf = make([]byte, blockFrames*frameSize)
+	}
+	var frame []int
+	if len(e.transforms) > 0 {
+		frame = make([]int, numChans)
+	}
+	for start := 0; start < frameCount; start += blockFrames {
+		end := start + blockFrames
+		if end > frameCount {
+			end = frameCount
+		}
+		block := e.pcmBuf[:(end-start)*frameSize]
+		samples := buf.Data[start*numChans : end*numChans]
+		if frame == nil {
+			for i, v := range samples {
+				put(block[i*bPerSample:], v)
+			}
+		} else {
+			for i := 0; i < end-start; i++ {
+				copy(frame, Frame(samples, numChans, i))
+				for _, fn := range e.transforms {
+					fn(frame)
 				}
-			case 32:
-				if err = binary.Write(bb, binary.BigEndian, int32(v)); err != nil {
-					return err
+				for j, v := range frame {
+					put(block[(i*numChans+j)*bPerSample:], v)
 				}
-			default:
-				return fmt.Errorf("can't add frames of bit size %d", e.BitDepth)
 			}
 		}
-		e.frames++
+		n, err := e.writePCM(block)
+		e.frames += n / frameSize
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBufferFormat records the first buffer ending with a partial frame (the
+// extra samples are dropped) or that doesn't share the format of the previous
+// ones. The mismatch is reported when closing the encoder.
+func (e *Encoder) checkBufferFormat(buf *audio.IntBuffer) {
+	if e.mismatchErr != nil {
+		return
+	}
+	if numChans := buf.Format.NumChannels; numChans > 0 && len(buf.Data)%numChans != 0 {
+		e.mismatchErr = fmt.Errorf("%w - a buffer of %d samples isn't a multiple of %d channels, %d samples were dropped",
+			ErrFrameMismatch, len(buf.Data), numChans, len(buf.Data)%numChans)
+		return
+	}
+	if e.bufFormat == nil {
+		e.bufFormat = &audio.Format{NumChannels: buf.Format.NumChannels, SampleRate: buf.Format.SampleRate}
+		e.sourceBitDepth = buf.SourceBitDepth
+		return
+	}
+	if buf.Format.SampleRate != e.bufFormat.SampleRate {
+		e.mismatchErr = fmt.Errorf("%w - a %d Hz buffer was written after a %d Hz one",
+			ErrFrameMismatch, buf.Format.SampleRate, e.bufFormat.SampleRate)
+		return
+	}
+	if buf.SourceBitDepth != e.sourceBitDepth {
+		e.mismatchErr = fmt.Errorf("%w - a %d bit buffer was written after a %d bit one",
+			ErrFrameMismatch, buf.SourceBitDepth, e.sourceBitDepth)
+	}
+}
+
+// writePCMSample serializes an uncompressed sample using the passed byte
+// order and bit depth.
+func writePCMSample(w io.Writer, byteOrder binary.ByteOrder, bitDepth int, v int) error {
+	put := pcmSamplePutFunc(bitDepth, byteOrder)
+	if put == nil {
+		return fmt.Errorf("can't add frames of bit size %d", bitDepth)
+	}
+	var b [4]byte
+	put(b[:], v)
+	_, err := w.Write(b[:bytesPerSample(bitDepth)])
+	return err
+}
+
+// pcmSamplePutFunc returns a function serializing an uncompressed sample at
+// the start of the passed slice, nil is returned if the bit depth isn't
+// supported.
+func pcmSamplePutFunc(bitDepth int, byteOrder binary.ByteOrder) func(b []byte, v int) {
+	littleEndian := byteOrder == binary.LittleEndian
+	switch bitDepth {
+	case 8:
+		return func(b []byte, v int) { b[0] = byte(v) }
+	case 16:
+		if littleEndian {
+			return func(b []byte, v int) { binary.LittleEndian.PutUint16(b, uint16(v)) }
+		}
+		return func(b []byte, v int) { binary.BigEndian.PutUint16(b, uint16(v)) }
+	case 24:
+		if littleEndian {
+			return func(b []byte, v int) { b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16) }
+		}
+		return func(b []byte, v int) { b[0], b[1], b[2] = byte(v>>16), byte(v>>8), byte(v) }
+	case 32:
+		if littleEndian {
+			return func(b []byte, v int) { binary.LittleEndian.PutUint32(b, uint32(v)) }
+		}
+		return func(b []byte, v int) { binary.BigEndian.PutUint32(b, uint32(v)) }
 	}
-	n, err := e.w.Write(bb.Bytes())
-	e.WrittenBytes += n
+	return nil
+}
+
+// addRawPCM writes already encoded PCM data to the sound chunk.
+// The data is expected to use the byte order of the encoding.
+// The passed data is expected to only contain full frames.
+func (e *Encoder) addRawPCM(p []byte) error {
+	frameSize := bytesPerSample(e.BitDepth) * e.NumChans
+	if frameSize < 1 {
+		return fmt.Errorf("can't add frames of bit size %d", e.BitDepth)
+	}
+	if len(p)%frameSize != 0 {
+		return fmt.Errorf("raw PCM data isn't frame aligned (%d bytes for %d byte frames)", len(p), frameSize)
+	}
+	if err := e.checkPCMSize(int64(len(p))); err != nil {
+		return err
+	}
+	if err := e.startPCMChunk(); err != nil {
+		return err
+	}
+	n, err := e.writePCM(p)
+	e.frames += n / frameSize
 	return err
 }
 
+// checkPCMSize returns an error wrapping ErrTooLargeForAIFF if writing n more
+// bytes of sound data would result in a file too large to be described by
+// its headers.
+func (e *Encoder) checkPCMSize(n int64) error {
+	if size := int64(e.WrittenBytes) + n; size > maxFileSize {
+		return fmt.Errorf("%w - writing %d more bytes would result in a %d bytes file, the maximum is %d bytes",
+			ErrTooLargeForAIFF, n, size, int64(maxFileSize))
+	}
+	return nil
+}
+
 func (e *Encoder) writeHeader() error {
 	if e == nil {
 		return fmt.Errorf("can't write a nil encoder")
@@ -98,6 +338,37 @@ func (e *Encoder) writeHeader() error {
 		return nil
 	}
 
+	// any positive sample rate can be stored in the 80-bit COMM field
+	sampleRate := float64(e.SampleRate)
+	if e.ExactSampleRate > 0 {
+		sampleRate = e.ExactSampleRate
+	}
+	if !(sampleRate > 0) || math.IsInf(sampleRate, 0) {
+		return fmt.Errorf("invalid sample rate: %v", sampleRate)
+	}
+	if e.NumChans < 1 {
+		return fmt.Errorf("invalid number of channels: %d", e.NumChans)
+	}
+
+	form, err := e.Form()
+	if err != nil {
+		return err
+	}
+	isAIFC := form == FormAIFC
+	encoding := e.Encoding
+	if isAIFC && encoding == EncNotSet {
+		encoding = EncNone
+	}
+	e.byteOrder = binary.BigEndian
+	if byteOrder, ok := pcmByteOrder(encoding); ok {
+		e.byteOrder = byteOrder
+		if bitDepth := pcmBitDepth(encoding); bitDepth > 0 && bitDepth != e.BitDepth {
+			return fmt.Errorf("%s - %q encoding requires %d bits but got %d", ErrFmtNotSupported, encoding, bitDepth, e.BitDepth)
+		}
+	} else if _, ok := lookupCodec(encoding); !ok && encoding != EncAble {
+		return fmt.Errorf("%s - can't encode using %q", ErrFmtNotSupported, encoding)
+	}
+
 	// ID
 	if err := e.AddBE(formID); err != nil {
 		return fmt.Errorf("%v when writing FORM header", err)
@@ -107,15 +378,42 @@ func (e *Encoder) writeHeader() error {
 		return fmt.Errorf("%v when writing size header", err)
 	}
 	// Format
-	if err := e.AddBE(aiffID); err != nil {
+	format := aiffID
+	if isAIFC {
+		format = aifcID
+	}
+	if err := e.AddBE(format); err != nil {
 		return fmt.Errorf("%v when writing format header", err)
 	}
+	if isAIFC {
+		// version chunk, required in AIFC files
+		if err := e.AddBE(fverID); err != nil {
+			return fmt.Errorf("%v when writing FVER chunk ID header", err)
+		}
+		if err := e.AddBE(uint32(4)); err != nil {
+			return fmt.Errorf("%v when writing FVER chunk size header", err)
+		}
+		if err := e.AddBE(uint32(aifcVersion1)); err != nil {
+			return fmt.Errorf("%v when writing FVER timestamp", err)
+		}
+	}
+	if e.RawCOMM != nil {
+		if err := e.writeRawCOMM(isAIFC, encoding); err != nil {
+			return err
+		}
+		return e.writeExtraChunks()
+	}
 	// comm chunk
 	if err := e.AddBE(COMMID); err != nil {
 		return fmt.Errorf("%v when writing comm chunk ID header", err)
 	}
+	encName := e.encodingName(encoding)
+	commSize := 18
+	if isAIFC {
+		commSize += 4 + pstringSize(len(encName))
+	}
 	// blocksize uint32
-	if err := e.AddBE(uint32(18)); err != nil {
+	if err := e.AddBE(uint32(commSize)); err != nil {
 		return fmt.Errorf("%v when writing comm chunk size header", err)
 	}
 	if err := e.AddBE(uint16(e.NumChans)); err != nil {
@@ -123,6 +421,7 @@ func (e *Encoder) writeHeader() error {
 	}
 	// number of sample frames (unknown at this point)
 	// will have to come back and edit
+	e.numFramesPos = e.WrittenBytes
 	if err := e.AddBE(uint32(42)); err != nil {
 		return fmt.Errorf("%v when writing comm num sample frames", err)
 	}
@@ -130,13 +429,118 @@ func (e *Encoder) writeHeader() error {
 		return fmt.Errorf("%v when writing comm chan numbers", err)
 	}
 	// sample rate in IeeeFloat (10 bytes)
-	if err := e.AddBE(audio.IntToIEEEFloat(int(e.SampleRate))); err != nil {
+	if err := e.AddBE(Float80FromFloat64(sampleRate)); err != nil {
 		return fmt.Errorf("%v when writing comm sample rate", err)
 	}
+	if isAIFC {
+		if err := e.AddBE(encoding); err != nil {
+			return fmt.Errorf("%v when writing comm encoding", err)
+		}
+		if err := e.AddBE(pstring(encName)); err != nil {
+			return fmt.Errorf("%v when writing comm compression name", err)
+		}
+	}
+	return e.writeExtraChunks()
+}
+
+// writeExtraChunks writes the optional chunks following the COMM chunk.
+func (e *Encoder) writeExtraChunks() error {
+	if len(e.Markers) > 0 {
+		if err := e.writeMarkers(); err != nil {
+			return err
+		}
+	}
+	if len(e.Comments) > 0 {
+		if err := e.writeComments(); err != nil {
+			return err
+		}
+	}
+	if e.BroadcastInfo != nil {
+		if err := e.writeBroadcastInfo(); err != nil {
+			return err
+		}
+	}
+	if e.ChannelLayout != nil {
+		if err := e.writeChannelLayout(); err != nil {
+			return err
+		}
+	}
+	if e.AppleInfo != nil || e.Tempo > 0 {
+		if err := e.writeAppleInfo(); err != nil {
+			return err
+		}
+	}
+	if e.Gapless != nil {
+		if err := e.writeGapless(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRawCOMM writes the COMM chunk stored in RawCOMM after checking it
+// describes the data the encoder writes.
+func (e *Encoder) writeRawCOMM(isAIFC bool, encoding Encoding) error {
+	comm := e.RawCOMM
+	if len(comm) < 18 {
+		return fmt.Errorf("invalid raw COMM chunk, expected at least 18 bytes but got %d", len(comm))
+	}
+	if numChans := int(binary.BigEndian.Uint16(comm[0:2])); numChans != e.NumChans {
+		return fmt.Errorf("the raw COMM chunk declares %d channels but the encoder writes %d", numChans, e.NumChans)
+	}
+	if bitDepth := int(binary.BigEndian.Uint16(comm[6:8])); bitDepth != e.BitDepth {
+		return fmt.Errorf("the raw COMM chunk declares %d bits but the encoder writes %d", bitDepth, e.BitDepth)
+	}
+	if isAIFC {
+		if len(comm) < 22 || !bytes.Equal(comm[18:22], encoding[:]) {
+			return fmt.Errorf("the raw COMM chunk doesn't declare the %q encoding", encoding)
+		}
+	}
+
+	if err := e.AddBE(COMMID); err != nil {
+		return fmt.Errorf("%v when writing comm chunk ID header", err)
+	}
+	if err := e.AddBE(uint32(len(comm))); err != nil {
+		return fmt.Errorf("%v when writing comm chunk size header", err)
+	}
+	// the number of frames is updated when closing the encoder
+	e.numFramesPos = e.WrittenBytes + 2
+	if err := e.AddBE(comm); err != nil {
+		return fmt.Errorf("%v when writing raw comm chunk", err)
+	}
+	if len(comm)%2 == 1 {
+		if err := e.AddBE(uint8(0)); err != nil {
+			return fmt.Errorf("%v when writing comm pad byte", err)
+		}
+	}
 	return nil
 }
 
-func (e *Encoder) Write(buf *audio.IntBuffer) error {
+// encodingNames are the standard compression names of the supported encodings.
+var encodingNames = map[Encoding]string{
+	EncNone: "not compressed",
+	EncTwos: "not compressed",
+	EncSowt: "not compressed",
+	EncIn24: "24-bit integer",
+	Enc23ni: "24-bit integer",
+	EncIn32: "32-bit integer",
+	Enc42n1: "32-bit integer",
+	EncUlaw: "\xb5Law 2:1",
+	EncULAW: "\xb5Law 2:1",
+	EncAlaw: "ALaw 2:1",
+	EncALAW: "ALaw 2:1",
+}
+
+// encodingName returns the compression name to write in the COMM chunk.
+func (e *Encoder) encodingName(encoding Encoding) string {
+	if e.EncodingName != "" {
+		return e.EncodingName
+	}
+	return encodingNames[encoding]
+}
+
+// startPCMChunk writes the headers and the SSND chunk header if needed.
+func (e *Encoder) startPCMChunk() error {
 	if err := e.writeHeader(); err != nil {
 		return err
 	}
@@ -163,34 +567,138 @@ func (e *Encoder) Write(buf *audio.IntBuffer) error {
 		if err := e.AddBE(uint32(0)); err != nil {
 			return fmt.Errorf("%v when writing SSND block size", err)
 		}
+		if e.Checksum {
+			e.crc = crc32.NewIEEE()
+		}
+		if codec, ok := lookupCodec(e.Encoding); ok {
+			var err error
+			if e.sampleEncoder, err = codec.NewSampleEncoder(encoderWriter{e}, e.NumChans, e.BitDepth); err != nil {
+				return fmt.Errorf("failed to create the %q sample encoder - %v", e.Encoding, err)
+			}
+		}
+	}
+	return nil
+}
+
+// encoderWriter writes to the underlying writer of an encoder and keeps
+// track of the written bytes.
+type encoderWriter struct {
+	e *Encoder
+}
+
+func (w encoderWriter) Write(p []byte) (int, error) {
+	return w.e.writePCM(p)
+}
+
+// Write encodes the content of the passed buffer.
+// Integer buffers are written as is, float buffers are expected to hold
+// samples in the [-1, 1] range and are scaled to the bit depth of the
+// encoder (values outside of the range are clipped). Other buffer types are
+// converted using their AsIntBuffer method.
+func (e *Encoder) Write(buf audio.Buffer) error {
+	if err := e.startPCMChunk(); err != nil {
+		return err
+	}
+	var intBuf *audio.IntBuffer
+	switch b := buf.(type) {
+	case *audio.IntBuffer:
+		intBuf = b
+	case *audio.FloatBuffer:
+		if b != nil {
+			intBuf = e.floatsToIntBuffer(b.Format, 0, len(b.Data), func(i int) float64 { return b.Data[i] })
+		}
+	case *audio.Float32Buffer:
+		if b != nil {
+			intBuf = e.floatsToIntBuffer(b.Format, b.SourceBitDepth, len(b.Data), func(i int) float64 { return float64(b.Data[i]) })
+		}
+	case nil:
+	default:
+		intBuf = buf.AsIntBuffer()
 	}
+	return e.addBuffer(intBuf)
+}
 
-	return e.addBuffer(buf)
+// floatsToIntBuffer scales n normalized float samples to the bit depth of
+// the encoder.
+func (e *Encoder) floatsToIntBuffer(format *audio.Format, sourceBitDepth, n int, sample func(i int) float64) *audio.IntBuffer {
+	max := float64(int(1)<<uint(e.BitDepth-1) - 1)
+	data := make([]int, n)
+	for i := range data {
+		v := math.Round(sample(i) * max)
+		if v > max {
+			v = max
+		} else if v < -max-1 {
+			v = -max - 1
+		}
+		data[i] = int(v)
+	}
+	return &audio.IntBuffer{Format: format, SourceBitDepth: sourceBitDepth, Data: data}
 }
 
 // Close flushes the content to disk, make sure the headers are up to date
 // Note that the underlying writter is NOT being closed.
+// The headers are always updated but an error wrapping ErrFrameMismatch is
+// returned if a written buffer didn't hold whole frames or if buffers
+// with different sample rates or source bit depths were mixed.
 func (e *Encoder) Close() error {
+	if e.sampleEncoder != nil {
+		if err := e.sampleEncoder.Flush(); err != nil {
+			return fmt.Errorf("%w when flushing the %q sample encoder", err, e.Encoding)
+		}
+	}
+	// offset + block size + sound data, the pad byte isn't part of the chunk
+	ssndSize := e.WrittenBytes - e.pcmChunkSizePos - 4
+	if e.pcmChunkSizePos > 0 && ssndSize%2 != 0 {
+		if err := e.AddBE(uint8(0)); err != nil {
+			return fmt.Errorf("%v when writing the SSND pad byte", err)
+		}
+	}
+	if e.crc != nil {
+		if err := e.writeChecksum(); err != nil {
+			return err
+		}
+	}
+	totalSize := e.WrittenBytes
+	if int64(totalSize) > maxFileSize {
+		return fmt.Errorf("%w - %d bytes were written, the maximum is %d bytes", ErrTooLargeForAIFF, totalSize, int64(maxFileSize))
+	}
 	// go back and write total size
 	if _, err := e.w.Seek(4, 0); err != nil {
 		return err
 	}
-	if err := e.AddBE(uint32(e.WrittenBytes) - 8); err != nil {
+	if err := e.AddBE(uint32(totalSize) - 8); err != nil {
 		return fmt.Errorf("%v when writing the total written bytes", err)
 	}
-	if _, err := e.w.Seek(22, 0); err != nil {
-		return err
+	if e.numFramesPos > 0 {
+		if _, err := e.w.Seek(int64(e.numFramesPos), 0); err != nil {
+			return err
+		}
+		if err := e.AddBE(uint32(e.frames)); err != nil {
+			return fmt.Errorf("%v when writing the total of frames", err)
+		}
+	}
+	if e.beatsPos > 0 && e.Tempo > 0 {
+		sampleRate := float64(e.SampleRate)
+		if e.ExactSampleRate > 0 {
+			sampleRate = e.ExactSampleRate
+		}
+		dur := time.Duration(float64(e.frames) / sampleRate * float64(time.Second))
+		if _, err := e.w.Seek(int64(e.beatsPos), 0); err != nil {
+			return err
+		}
+		if err := e.AddBE(BeatsFromDuration(dur, e.Tempo)); err != nil {
+			return fmt.Errorf("%v when writing the number of beats", err)
+		}
 	}
-	if err := e.AddBE(uint32(e.frames)); err != nil {
-		return fmt.Errorf("%v when writing the total of frames", err)
+	if err := e.updateGapless(); err != nil {
+		return err
 	}
 	// rewrite the audio chunk length header
 	if e.pcmChunkSizePos > 0 {
 		if _, err := e.w.Seek(int64(e.pcmChunkSizePos), 0); err != nil {
 			return err
 		}
-		chunksize := uint32((int(e.BitDepth)/8)*int(e.NumChans)*e.frames + 8)
-		if err := e.AddBE(uint32(chunksize)); err != nil {
+		if err := e.AddBE(uint32(ssndSize)); err != nil {
 			return fmt.Errorf("%v when writing wav data chunk size header", err)
 		}
 	}
@@ -200,5 +708,5 @@ func (e *Encoder) Close() error {
 	case *os.File:
 		e.w.(*os.File).Sync()
 	}
-	return nil
+	return e.mismatchErr
 }
@@ -2,10 +2,14 @@ package aiff
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/go-audio/audio"
@@ -309,3 +313,585 @@ func TestBufferEncoderRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestEncoderAIFCRoundTrip(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	testCases := []struct {
+		in           string
+		encoding     Encoding
+		encodingName string
+		expectedName string
+	}{
+		// custom compression name preserved
+		{"fixtures/ableton.aif", EncAble, "Ableton Content", "Ableton Content"},
+		{"fixtures/sowt.aif", EncSowt, "", "not compressed"},
+		{"fixtures/zipper24b.aiff", EncSowt, "little endian", "little endian"},
+		{"fixtures/kick.aif", EncNone, "", "not compressed"},
+		{"fixtures/zipper24b.aiff", EncIn24, "", "24-bit integer"},
+		{"fixtures/zipper24b.aiff", Enc23ni, "", "24-bit integer"},
+		{"fixtures/kick32b.aiff", EncIn32, "", "32-bit integer"},
+		{"fixtures/kick32b.aiff", Enc42n1, "", "32-bit integer"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			in, err := os.Open(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer in.Close()
+			d := NewDecoder(in)
+			buf, err := d.FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out, err := os.Create("testOutput/aifc.aif")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(out.Name())
+			defer out.Close()
+			e := NewEncoder(out, d.SampleRate, int(d.BitDepth), int(d.NumChans))
+			e.Encoding = tc.encoding
+			e.EncodingName = tc.encodingName
+			if err := e.Write(buf); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			if issues := Lint(out); len(issues) > 0 {
+				t.Fatalf("expected the encoded file to be spec compliant but got %v", issues)
+			}
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			d2 := NewDecoder(out)
+			d2.ReadInfo()
+			if d2.Form != aifcID {
+				t.Fatalf("expected an AIFC file but got %q", d2.Form)
+			}
+			if d2.Encoding != tc.encoding {
+				t.Fatalf("expected the encoding to be %q but got %q", tc.encoding, d2.Encoding)
+			}
+			if d2.EncodingName != tc.expectedName {
+				t.Fatalf("expected the encoding name to be %q but got %q", tc.expectedName, d2.EncodingName)
+			}
+			if _, err := in.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			if ok, diffs := Equal(in, out, IgnoreMetadata()); !ok {
+				t.Fatalf("expected the sound data to survive the round trip but got %v", diffs)
+			}
+		})
+	}
+}
+
+func TestEncoderPCMEncodingBitDepth(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/in24.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	e := NewEncoder(out, 44100, 16, 2)
+	e.Encoding = EncIn24
+	if err := e.Write(&audio.IntBuffer{Data: []int{0, 0}, Format: &audio.Format{NumChannels: 2, SampleRate: 44100}}); err == nil {
+		t.Fatal("expected an error encoding 16-bit samples using the in24 encoding")
+	}
+}
+
+func TestEncoderForm(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	testCases := []struct {
+		form     FormType
+		encoding Encoding
+		expected [4]byte
+		encoded  Encoding
+	}{
+		{FormAuto, EncNotSet, aiffID, EncNotSet},
+		{FormAuto, EncNone, aifcID, EncNone},
+		{FormAuto, EncSowt, aifcID, EncSowt},
+		{FormAIFF, EncNotSet, aiffID, EncNotSet},
+		{FormAIFF, EncNone, aiffID, EncNotSet},
+		{FormAIFC, EncNotSet, aifcID, EncNone},
+		{FormAIFC, EncSowt, aifcID, EncSowt},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%v %q", tc.form, tc.encoding), func(t *testing.T) {
+			out, err := os.Create("testOutput/form.aif")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(out.Name())
+			defer out.Close()
+			e := NewEncoder(out, 44100, 16, 1)
+			e.FormType = tc.form
+			e.Encoding = tc.encoding
+			if err := e.Write(&audio.IntBuffer{Data: []int{0, 1, -1, 2}, Format: &audio.Format{NumChannels: 1, SampleRate: 44100}}); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			if issues := Lint(out); len(issues) > 0 {
+				t.Fatalf("expected the encoded file to be spec compliant but got %v", issues)
+			}
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			d := NewDecoder(out)
+			if err := d.ReadInfo(); err != nil {
+				t.Fatal(err)
+			}
+			if d.Form != tc.expected {
+				t.Fatalf("expected a %q file but got %q", tc.expected, d.Form)
+			}
+			if d.Encoding != tc.encoded {
+				t.Fatalf("expected the encoding to be %q but got %q", tc.encoded, d.Encoding)
+			}
+		})
+	}
+
+	// little endian and float data can't be stored in an AIFF file
+	for _, enc := range []Encoding{EncSowt, EncFl32} {
+		e := NewEncoder(nil, 44100, 32, 1)
+		e.FormType = FormAIFF
+		e.Encoding = enc
+		if _, err := e.Form(); err == nil {
+			t.Fatalf("expected an error forcing an AIFF file with the %q encoding", enc)
+		}
+	}
+	e := NewEncoder(nil, 44100, 32, 1)
+	e.Encoding = EncFl32
+	if form, err := e.Form(); err != nil || form != FormAIFC {
+		t.Fatalf("expected the %q encoding to upgrade the file to AIFC but got %v, %v", EncFl32, form, err)
+	}
+}
+
+func TestEncoderRawCOMM(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	// 44100 Hz with mantissa bits a float64 can't hold
+	oddRate := []byte{0, 1, 0, 0, 0, 0, 0, 16, 0x40, 0x0E, 0xAC, 0x44, 0, 0, 0, 0, 0, 0x01}
+	testCases := []struct {
+		in       string
+		comm     []byte
+		encoding Encoding
+	}{
+		{"fixtures/kick.aif", nil, EncNotSet},
+		{"fixtures/sowt.aif", nil, EncSowt},
+		{"fixtures/kick.aif", oddRate, EncNotSet},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			in, err := os.Open(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer in.Close()
+			d := NewDecoder(in)
+			d.KeepRawCOMM = true
+			buf, err := d.FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			comm := d.RawCOMM
+			if tc.comm != nil {
+				comm = append([]byte(nil), tc.comm...)
+				binary.BigEndian.PutUint16(comm[0:2], d.NumChans)
+				binary.BigEndian.PutUint16(comm[6:8], d.BitDepth)
+			}
+			if len(comm) != int(d.commSize) {
+				t.Fatalf("expected the raw COMM chunk to be %d bytes but got %d", d.commSize, len(comm))
+			}
+
+			out, err := os.Create("testOutput/raw_comm.aif")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(out.Name())
+			defer out.Close()
+			e := NewEncoder(out, d.SampleRate, int(d.BitDepth), int(d.NumChans))
+			e.Encoding = tc.encoding
+			e.RawCOMM = comm
+			if err := e.Write(buf); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			d2 := NewDecoder(out)
+			d2.KeepRawCOMM = true
+			if err := d2.ReadInfo(); err != nil {
+				t.Fatal(err)
+			}
+			expected := append([]byte(nil), comm...)
+			binary.BigEndian.PutUint32(expected[2:6], uint32(len(buf.Data)/int(d.NumChans)))
+			if !bytes.Equal(d2.RawCOMM, expected) {
+				t.Fatalf("expected the COMM chunk to be written unchanged\n%x\nbut got\n%x", expected, d2.RawCOMM)
+			}
+		})
+	}
+
+	out, err := os.Create("testOutput/raw_comm_mismatch.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	e := NewEncoder(out, 44100, 24, 1)
+	e.RawCOMM = oddRate
+	if err := e.writeHeader(); err == nil {
+		t.Fatal("expected an error writing a COMM chunk declaring another bit depth")
+	}
+}
+
+func TestEncoderTooLarge(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/too_large.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	e := NewEncoder(out, 44100, 16, 2)
+	buf := &audio.IntBuffer{Data: make([]int, 64), Format: &audio.Format{NumChannels: 2, SampleRate: 44100}}
+	if err := e.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	// pretend almost 4GB of data were written
+	e.WrittenBytes = maxFileSize - 100
+	if err := e.Write(buf); !errors.Is(err, ErrTooLargeForAIFF) {
+		t.Fatalf("expected ErrTooLargeForAIFF but got %v", err)
+	}
+	if err := e.addRawPCM(make([]byte, 256)); !errors.Is(err, ErrTooLargeForAIFF) {
+		t.Fatalf("expected ErrTooLargeForAIFF copying raw PCM data but got %v", err)
+	}
+	if e.WrittenBytes != maxFileSize-100 {
+		t.Fatalf("expected nothing to be written, %d bytes were", e.WrittenBytes-(maxFileSize-100))
+	}
+
+	// raw copy of a whole file
+	in, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	e = NewEncoder(out, 22050, 16, 1)
+	if err := e.startPCMChunk(); err != nil {
+		t.Fatal(err)
+	}
+	e.WrittenBytes = maxFileSize - 100
+	if _, err := NewDecoder(in).EncodeTo(e); !errors.Is(err, ErrTooLargeForAIFF) {
+		t.Fatalf("expected ErrTooLargeForAIFF copying a file but got %v", err)
+	}
+
+	// the compressed data is checked when the codec writes it
+	e = NewEncoder(out, 44100, 16, 2)
+	e.Encoding = EncUlaw
+	if err := e.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	e.WrittenBytes = maxFileSize - 10
+	if err := e.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); !errors.Is(err, ErrTooLargeForAIFF) {
+		t.Fatalf("expected ErrTooLargeForAIFF flushing compressed data but got %v", err)
+	}
+}
+
+func TestEncoderExactSampleRate(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	for _, rate := range []float64{44056, 22254.545454545454, 88200, 8000, 384000, 768000} {
+		out, err := os.Create("testOutput/exact_rate.aif")
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := NewEncoder(out, int(rate), 16, 1)
+		e.ExactSampleRate = rate
+		buf := &audio.IntBuffer{Format: &audio.Format{NumChannels: 1, SampleRate: int(rate)}, Data: []int{0, 1, 2, 3}}
+		if err := e.Write(buf); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := out.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+		d := NewDecoder(out)
+		if err := d.ReadInfo(); err != nil {
+			t.Fatal(err)
+		}
+		out.Close()
+		os.Remove(out.Name())
+		if d.ExactSampleRate() != rate {
+			t.Fatalf("expected the sample rate to be %v but got %v", rate, d.ExactSampleRate())
+		}
+		if d.SampleRate != int(math.Round(rate)) {
+			t.Fatalf("expected the rounded sample rate to be %v but got %d", math.Round(rate), d.SampleRate)
+		}
+	}
+}
+
+func TestEncoderInvalidSampleRate(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/invalid_rate.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	for _, rate := range []int{0, -44100} {
+		e := NewEncoder(out, rate, 16, 1)
+		if err := e.Write(&audio.IntBuffer{Data: []int{0, 0}, Format: &audio.Format{NumChannels: 1, SampleRate: rate}}); err == nil {
+			t.Fatalf("expected an error encoding at %d Hz", rate)
+		}
+	}
+}
+
+func TestEncoderOddSSNDPadding(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/odd_ssnd.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	// 3 frames of 8-bit mono audio: 8 + 3 bytes of SSND data
+	e := NewEncoder(out, 44100, 8, 1)
+	e.Markers = []Marker{{ID: 1, Position: 2, Name: "end"}}
+	if err := e.Write(&audio.IntBuffer{Data: []int{1, 2, 3}, Format: &audio.Format{NumChannels: 1, SampleRate: 44100}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := out.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size()%2 != 0 {
+		t.Fatalf("expected the SSND chunk to be padded but the file is %d bytes", info.Size())
+	}
+
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(out)
+	for _, issue := range d.Validate() {
+		if issue.Severity > SeverityInfo {
+			t.Fatalf("unexpected issue: %s", issue)
+		}
+	}
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.PCMChunk.Size != 11 {
+		t.Fatalf("expected the SSND chunk size to be 11 but got %d", d.PCMChunk.Size)
+	}
+	if len(buf.Data) != 3 {
+		t.Fatalf("expected the pad byte to be skipped but got %d samples", len(buf.Data))
+	}
+}
+
+func TestEncoderFrameMismatch(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	testCases := []struct {
+		desc string
+		bufs []*audio.IntBuffer
+	}{
+		{"partial frame", []*audio.IntBuffer{
+			{Data: []int{1, 2, 3}, Format: &audio.Format{NumChannels: 2, SampleRate: 44100}},
+		}},
+		{"partial frames adding up to whole frames", []*audio.IntBuffer{
+			{Data: []int{1, 2, 3}, Format: &audio.Format{NumChannels: 2, SampleRate: 44100}},
+			{Data: []int{4, 5, 6}, Format: &audio.Format{NumChannels: 2, SampleRate: 44100}},
+		}},
+		{"mixed sample rates", []*audio.IntBuffer{
+			{Data: []int{1, 2}, Format: &audio.Format{NumChannels: 2, SampleRate: 44100}},
+			{Data: []int{3, 4}, Format: &audio.Format{NumChannels: 2, SampleRate: 48000}},
+		}},
+		{"mixed source bit depths", []*audio.IntBuffer{
+			{Data: []int{1, 2}, Format: &audio.Format{NumChannels: 2, SampleRate: 44100}, SourceBitDepth: 16},
+			{Data: []int{3, 4}, Format: &audio.Format{NumChannels: 2, SampleRate: 44100}, SourceBitDepth: 24},
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			out, err := os.Create("testOutput/frame_mismatch.aif")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(out.Name())
+			defer out.Close()
+			e := NewEncoder(out, 44100, 16, 2)
+			for _, buf := range tc.bufs {
+				if err := e.Write(buf); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := e.Close(); !errors.Is(err, ErrFrameMismatch) {
+				t.Fatalf("expected ErrFrameMismatch but got %v", err)
+			}
+			// the headers must still describe the written frames
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			d := NewDecoder(out)
+			if err := d.ReadInfo(); err != nil {
+				t.Fatal(err)
+			}
+			if int(d.NumSampleFrames) != e.frames {
+				t.Fatalf("expected %d frames but got %d", e.frames, d.NumSampleFrames)
+			}
+		})
+	}
+}
+
+func TestEncoderWriteFloatBuffers(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	format := &audio.Format{NumChannels: 1, SampleRate: 44100}
+	testCases := []struct {
+		desc string
+		buf  audio.Buffer
+	}{
+		{"float64", &audio.FloatBuffer{Format: format, Data: []float64{0, 0.5, -0.5, 1, -1, 2, -2}}},
+		{"float32", &audio.Float32Buffer{Format: format, Data: []float32{0, 0.5, -0.5, 1, -1, 2, -2}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			out, err := os.Create("testOutput/float_buffer.aif")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(out.Name())
+			defer out.Close()
+			e := NewEncoder(out, 44100, 16, 1)
+			if err := e.Write(tc.buf); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			buf, err := NewDecoder(out).FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := []int{0, 16384, -16384, 32767, -32767, 32767, -32768}
+			if !reflect.DeepEqual(buf.Data, expected) {
+				t.Fatalf("expected %v but got %v", expected, buf.Data)
+			}
+		})
+	}
+}
+
+func TestEncoderAppleInfo(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/apple_info.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	e := NewEncoder(out, 44100, 16, 1)
+	e.AppleInfo = &AppleMetadata{Beats: 1, Note: 57, Scale: 1, Numerator: 3, Denominator: 4, IsLooping: true}
+	e.Tempo = 120
+	// 2 seconds @ 120 BPM
+	if err := e.Write(&audio.IntBuffer{Data: make([]int, 88200), Format: &audio.Format{NumChannels: 1, SampleRate: 44100}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(out)
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	expected := AppleMetadata{Beats: 4, Note: 57, Scale: 1, Numerator: 3, Denominator: 4, IsLooping: true}
+	if !reflect.DeepEqual(d.AppleInfo, expected) {
+		t.Fatalf("expected %+v but got %+v", expected, d.AppleInfo)
+	}
+	if tempo := d.Tempo(); tempo != 120 {
+		t.Fatalf("expected a tempo of 120 BPM but got %v", tempo)
+	}
+	if issues := Lint(out); len(issues) > 0 {
+		t.Fatalf("unexpected lint issues: %v", issues)
+	}
+}
+
+func TestEncoderGapless(t *testing.T) {
+	testCases := []struct {
+		name     string
+		info     GaplessInfo
+		expected GaplessInfo
+	}{
+		{"original frames", GaplessInfo{Priming: 2112, Remainder: 576, OriginalFrames: 1000}, GaplessInfo{Priming: 2112, Remainder: 576, OriginalFrames: 1000}},
+		{"computed frames", GaplessInfo{Priming: 2112, Remainder: 576}, GaplessInfo{Priming: 2112, Remainder: 576, OriginalFrames: 4410 - 2112 - 576}},
+	}
+	os.Mkdir("testOutput", 0777)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := os.Create("testOutput/gapless.aif")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(out.Name())
+			defer out.Close()
+
+			e := NewEncoder(out, 44100, 16, 1)
+			info := tc.info
+			e.Gapless = &info
+			if err := e.Write(&audio.IntBuffer{Data: make([]int, 4410), Format: &audio.Format{NumChannels: 1, SampleRate: 44100}}); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			d := NewDecoder(out)
+			if err := d.Drain(); err != nil {
+				t.Fatal(err)
+			}
+			gapless, ok := d.Gapless()
+			if !ok {
+				t.Fatalf("expected gapless info, ID3: %v", d.ID3)
+			}
+			if gapless != tc.expected {
+				t.Fatalf("expected %+v but got %+v", tc.expected, gapless)
+			}
+			if d.NumSampleFrames != 4410 {
+				t.Fatalf("expected 4410 frames but got %d", d.NumSampleFrames)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// Encoding is the 4 character ID of the compression type of an AIFC file
+// (see the Enc* variables). AIFF files don't have one (EncNotSet).
+type Encoding [4]byte
+
+// String returns the ID as text, spaces included ("raw "). An empty string is
+// returned for EncNotSet.
+func (enc Encoding) String() string {
+	if enc == EncNotSet {
+		return ""
+	}
+	return string(enc[:])
+}
+
+// EncodingFromString returns the encoding matching the passed ID such as
+// "sowt" or "fl32". IDs shorter than 4 characters are padded with spaces so
+// "raw" and "raw " are equivalent. The case is significant, "ulaw" and
+// "ULAW" are 2 different IDs.
+func EncodingFromString(s string) (Encoding, error) {
+	var enc Encoding
+	if s == "" {
+		return EncNotSet, nil
+	}
+	if len(s) > len(enc) {
+		return EncNotSet, fmt.Errorf("invalid encoding %q: more than 4 characters", s)
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] > '~' {
+			return EncNotSet, fmt.Errorf("invalid encoding %q: non printable character", s)
+		}
+	}
+	copy(enc[:], s+strings.Repeat(" ", len(enc)-len(s)))
+	return enc, nil
+}
+
+// IsFloat reports whether the encoding stores floating point samples.
+func (enc Encoding) IsFloat() bool {
+	switch enc {
+	case EncFl32, EncFL32, EncFl64, EncFL64:
+		return true
+	}
+	return false
+}
+
+// IsLittleEndian reports whether the encoding stores uncompressed samples in
+// little endian byte order (sowt, 23ni, 42n1).
+func (enc Encoding) IsLittleEndian() bool {
+	byteOrder, ok := pcmByteOrder(enc)
+	return ok && byteOrder == binary.LittleEndian
+}
+
+// IsCompressed reports whether the sound data isn't stored as plain integer
+// or float samples. Unknown encodings and the ones handled by a registered
+// codec (see RegisterCodec) are considered compressed.
+func (enc Encoding) IsCompressed() bool {
+	if _, ok := pcmByteOrder(enc); ok {
+		return false
+	}
+	switch enc {
+	case EncRaw, EncAble:
+		return false
+	}
+	return !enc.IsFloat()
+}
+
+// IsCompressed reports whether the AIFC encoding of the file is compressed,
+// see Encoding.IsCompressed. The file information is read if needed.
+func (d *Decoder) IsCompressed() bool {
+	return d.encoding().IsCompressed()
+}
+
+// IsLittleEndian reports whether the AIFC encoding of the file stores
+// little endian samples. ForceByteOrder isn't taken into account, see
+// ByteOrder.
+func (d *Decoder) IsLittleEndian() bool {
+	return d.encoding().IsLittleEndian()
+}
+
+// IsFloat reports whether the AIFC encoding of the file stores floating
+// point samples.
+func (d *Decoder) IsFloat() bool {
+	return d.encoding().IsFloat()
+}
+
+// encoding returns the encoding of the file, reading the file information if
+// needed.
+func (d *Decoder) encoding() Encoding {
+	if d == nil {
+		return EncNotSet
+	}
+	if d.NumChans == 0 {
+		d.ReadInfo()
+	}
+	return d.Encoding
+}
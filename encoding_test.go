@@ -0,0 +1,117 @@
+package aiff
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEncodingFromString(t *testing.T) {
+	testCases := []struct {
+		in      string
+		out     Encoding
+		wantErr bool
+	}{
+		{"sowt", EncSowt, false},
+		{"fl32", EncFl32, false},
+		{"FL32", EncFL32, false},
+		{"raw", EncRaw, false},
+		{"raw ", EncRaw, false},
+		{"GSM", EncGsm, false},
+		{"", EncNotSet, false},
+		{"toolong", EncNotSet, true},
+		{"a\x00b", EncNotSet, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			enc, err := EncodingFromString(tc.in)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %t, got %v", tc.wantErr, err)
+			}
+			if enc != tc.out {
+				t.Fatalf("expected %q, got %q", tc.out, enc)
+			}
+		})
+	}
+}
+
+func TestEncoding_String(t *testing.T) {
+	testCases := []struct {
+		enc Encoding
+		out string
+	}{
+		{EncSowt, "sowt"},
+		{EncRaw, "raw "},
+		{EncNotSet, ""},
+	}
+	for _, tc := range testCases {
+		if got := tc.enc.String(); got != tc.out {
+			t.Errorf("expected %q, got %q", tc.out, got)
+		}
+		if tc.enc == EncNotSet {
+			continue
+		}
+		enc, err := EncodingFromString(tc.enc.String())
+		if err != nil || enc != tc.enc {
+			t.Errorf("round trip of %q failed: %q, %v", tc.enc, enc, err)
+		}
+	}
+}
+
+func TestDecoder_Encoding(t *testing.T) {
+	f, err := os.Open("fixtures/sowt.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	d.ReadInfo()
+	if d.Encoding != EncSowt {
+		t.Fatalf("expected %s, got %s", EncSowt, d.Encoding)
+	}
+}
+
+func TestEncoding_Predicates(t *testing.T) {
+	testCases := []struct {
+		enc                         Encoding
+		compressed, little, isFloat bool
+	}{
+		{EncNotSet, false, false, false},
+		{EncNone, false, false, false},
+		{EncTwos, false, false, false},
+		{EncSowt, false, true, false},
+		{Enc23ni, false, true, false},
+		{Enc42n1, false, true, false},
+		{EncIn24, false, false, false},
+		{EncRaw, false, false, false},
+		{EncFl32, false, false, true},
+		{EncFL64, false, false, true},
+		{EncUlaw, true, false, false},
+		{EncIma4, true, false, false},
+		{Encoding{'n', 'e', 'w', '!'}, true, false, false},
+	}
+	for _, tc := range testCases {
+		if got := tc.enc.IsCompressed(); got != tc.compressed {
+			t.Errorf("expected %q compressed to be %t", tc.enc, tc.compressed)
+		}
+		if got := tc.enc.IsLittleEndian(); got != tc.little {
+			t.Errorf("expected %q little endian to be %t", tc.enc, tc.little)
+		}
+		if got := tc.enc.IsFloat(); got != tc.isFloat {
+			t.Errorf("expected %q float to be %t", tc.enc, tc.isFloat)
+		}
+	}
+
+	f, err := os.Open("fixtures/sowt.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	if !d.IsLittleEndian() || d.IsCompressed() || d.IsFloat() {
+		t.Fatal("expected the sowt file to be detected as little endian PCM")
+	}
+	var nilDecoder *Decoder
+	if nilDecoder.IsCompressed() {
+		t.Fatal("expected a nil decoder not to be compressed")
+	}
+}
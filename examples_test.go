@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 func ExampleDecoder_Duration() {
@@ -31,3 +32,22 @@ func ExampleDecoder_IsValidFile() {
 	fmt.Printf("is this file valid: %t", NewDecoder(f).IsValidFile())
 	// Output: is this file valid: true
 }
+
+func ExampleDecoder_ResetWithReader() {
+	pool := sync.Pool{New: func() interface{} { return &Decoder{} }}
+	for _, path := range []string{"fixtures/kick.aif", "fixtures/bloop.aif"} {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		d := pool.Get().(*Decoder)
+		d.ResetWithReader(f)
+		d.ReadInfo()
+		fmt.Printf("%s: %d Hz, %d channel(s)\n", path, d.SampleRate, d.NumChans)
+		pool.Put(d)
+		f.Close()
+	}
+	// Output:
+	// fixtures/kick.aif: 22050 Hz, 1 channel(s)
+	// fixtures/bloop.aif: 44100 Hz, 2 channel(s)
+}
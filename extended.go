@@ -0,0 +1,66 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Float80FromFloat64 converts a number into an 80-bit IEEE 754 extended
+// precision float (big endian), the format of the COMM sample rate. The
+// conversion is exact since the 64-bit mantissa can hold the 53 bits of a
+// float64, infinities and NaN are preserved.
+func Float80FromFloat64(f float64) [10]byte {
+	var b [10]byte
+	var sign uint16
+	if math.Signbit(f) {
+		sign = 0x8000
+		f = -f
+	}
+	switch {
+	case f == 0:
+		binary.BigEndian.PutUint16(b[:2], sign)
+		return b
+	case math.IsInf(f, 0):
+		binary.BigEndian.PutUint16(b[:2], sign|0x7FFF)
+		binary.BigEndian.PutUint64(b[2:], 1<<63)
+		return b
+	case math.IsNaN(f):
+		// quiet NaN
+		binary.BigEndian.PutUint16(b[:2], sign|0x7FFF)
+		binary.BigEndian.PutUint64(b[2:], 3<<62)
+		return b
+	}
+	// f = frac * 2^exp with frac in [0.5, 1), the exponent range of the
+	// extended format covers the float64 subnormals
+	frac, exp := math.Frexp(f)
+	binary.BigEndian.PutUint16(b[:2], sign|uint16(exp+16382))
+	binary.BigEndian.PutUint64(b[2:], uint64(math.Ldexp(frac, 64)))
+	return b
+}
+
+// Float80ToFloat64 converts an 80-bit IEEE 754 extended precision float
+// (big endian) into a float64. The 64-bit mantissa is rounded to the
+// nearest float64, values out of the float64 range become infinities or 0.
+func Float80ToFloat64(b [10]byte) float64 {
+	exp := int(binary.BigEndian.Uint16(b[:2]) & 0x7FFF)
+	mant := binary.BigEndian.Uint64(b[2:])
+	var f float64
+	switch {
+	case exp == 0x7FFF:
+		// the explicit integer bit is ignored
+		if mant<<1 == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	case exp == 0:
+		// denormals use the smallest exponent
+		f = math.Ldexp(float64(mant), 1-16383-63)
+	default:
+		f = math.Ldexp(float64(mant), exp-16383-63)
+	}
+	if b[0]&0x80 != 0 {
+		f = -f
+	}
+	return f
+}
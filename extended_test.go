@@ -0,0 +1,54 @@
+package aiff
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestFloat80FromFloat64(t *testing.T) {
+	for _, rate := range []int{8000, 11025, 22050, 44100, 48000, 88200, 96000, 192000} {
+		b := Float80FromFloat64(float64(rate))
+		if expected := audio.IntToIEEEFloat(rate); b != expected {
+			t.Errorf("%d: expected % x, got % x", rate, expected, b)
+		}
+		if f := Float80ToFloat64(b); f != float64(rate) {
+			t.Errorf("%d: round trip returned %v", rate, f)
+		}
+	}
+	for _, f := range []float64{0, 1, 0.5, 22254.545454545454, 44056, -3,
+		math.MaxFloat64, math.SmallestNonzeroFloat64, -math.SmallestNonzeroFloat64 * 3, math.Pi, math.Inf(1), math.Inf(-1)} {
+		if got := Float80ToFloat64(Float80FromFloat64(f)); got != f {
+			t.Errorf("expected %v, got %v", f, got)
+		}
+	}
+	if got := Float80ToFloat64(Float80FromFloat64(math.Copysign(0, -1))); got != 0 || !math.Signbit(got) {
+		t.Errorf("expected -0, got %v", got)
+	}
+	if got := Float80ToFloat64(Float80FromFloat64(math.NaN())); !math.IsNaN(got) {
+		t.Errorf("expected NaN, got %v", got)
+	}
+}
+
+func TestFloat80ToFloat64(t *testing.T) {
+	testCases := []struct {
+		b        [10]byte
+		expected float64
+	}{
+		// 1.0
+		{[10]byte{0x3F, 0xFF, 0x80}, 1},
+		// 2^64 - 1 rounds to 2^64
+		{[10]byte{0x40, 0x3E, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, 1 << 64},
+		// out of the float64 range
+		{[10]byte{0x7F, 0xFE, 0x80}, math.Inf(1)},
+		{[10]byte{0x80, 0x01, 0x80}, 0},
+		// unnormalized (no explicit integer bit) 0.5
+		{[10]byte{0x3F, 0xFF, 0x40}, 0.5},
+	}
+	for _, tc := range testCases {
+		if got := Float80ToFloat64(tc.b); got != tc.expected {
+			t.Errorf("% x: expected %v, got %v", tc.b, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package aiff
+
+// FadeIn returns a sample transform linearly fading in the first numFrames
+// frames going through it. The returned function is stateful and should only
+// be registered on a single Encoder.
+func FadeIn(numFrames int) func(frame []int) {
+	var pos int
+	return func(frame []int) {
+		if pos < numFrames {
+			applyGain(frame, float64(pos)/float64(numFrames))
+		}
+		pos++
+	}
+}
+
+// FadeOut returns a sample transform linearly fading out the last numFrames
+// frames of a stream totalFrames long. The returned function is stateful and
+// should only be registered on a single Encoder.
+func FadeOut(totalFrames, numFrames int) func(frame []int) {
+	var pos int
+	start := totalFrames - numFrames
+	return func(frame []int) {
+		if pos >= start {
+			remaining := totalFrames - pos - 1
+			if remaining < 0 {
+				remaining = 0
+			}
+			applyGain(frame, float64(remaining)/float64(numFrames))
+		}
+		pos++
+	}
+}
+
+func applyGain(frame []int, gain float64) {
+	for i, v := range frame {
+		frame[i] = int(float64(v) * gain)
+	}
+}
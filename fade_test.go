@@ -0,0 +1,72 @@
+package aiff
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestEncoder_WithSampleTransform(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	numFrames := 1000
+	fadeFrames := 100
+	buf := &audio.IntBuffer{
+		Format: &audio.Format{NumChannels: 2, SampleRate: 44100},
+		Data:   make([]int, numFrames*2),
+	}
+	for i := range buf.Data {
+		buf.Data[i] = 10000
+	}
+
+	out, err := os.Create("testOutput/fades.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	e := NewEncoder(out, 44100, 16, 2).
+		WithSampleTransform(FadeIn(fadeFrames)).
+		WithSampleTransform(FadeOut(numFrames, fadeFrames))
+	if err := e.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range buf.Data {
+		if v != 10000 {
+			t.Fatal("expected the source buffer to not be modified by the transforms")
+		}
+	}
+
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := NewDecoder(out).FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Data) != len(buf.Data) {
+		t.Fatalf("expected %d samples but got %d", len(buf.Data), len(decoded.Data))
+	}
+	expectations := []struct {
+		frame int
+		value int
+	}{
+		{0, 0},
+		{50, 5000},
+		{fadeFrames, 10000},
+		{500, 10000},
+		{numFrames - fadeFrames - 1, 10000},
+		{numFrames - 51, 5000},
+		{numFrames - 1, 0},
+	}
+	for _, exp := range expectations {
+		for ch := 0; ch < 2; ch++ {
+			if v := decoded.Data[exp.frame*2+ch]; v != exp.value {
+				t.Errorf("expected frame %d, channel %d to be %d but got %d", exp.frame, ch, exp.value, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// findFormsBufSize is the size of the blocks read while scanning.
+const findFormsBufSize = 64 * 1024
+
+// FindForms scans r and returns the offsets of the AIFF/AIFC FORM headers
+// found at arbitrary positions. This is useful to extract AIFF payloads
+// embedded in other files (GarageBand packages, resource forks, firmware
+// blobs...), see NewDecoderAt.
+func FindForms(r io.ReaderAt) []int64 {
+	var offsets []int64
+	if r == nil {
+		return offsets
+	}
+	buf := make([]byte, findFormsBufSize)
+	var pos int64
+	for {
+		n, err := r.ReadAt(buf, pos)
+		data := buf[:n]
+		for i := 0; i+12 <= len(data); {
+			j := bytes.Index(data[i:], formID[:])
+			if j < 0 || i+j+12 > len(data) {
+				break
+			}
+			i += j
+			if form := data[i+8 : i+12]; bytes.Equal(form, aiffID[:]) || bytes.Equal(form, aifcID[:]) {
+				offsets = append(offsets, pos+int64(i))
+			}
+			i++
+		}
+		if err != nil || n < len(buf) {
+			return offsets
+		}
+		// overlap so headers across blocks aren't missed
+		pos += int64(n - 11)
+	}
+}
+
+// NewDecoderAt returns a decoder limited to the FORM container starting at
+// the given offset of r.
+func NewDecoderAt(r io.ReaderAt, offset int64) (*Decoder, error) {
+	var header [12]byte
+	if _, err := r.ReadAt(header[:], offset); err != nil {
+		return nil, fmt.Errorf("failed to read the FORM header at offset %d - %v", offset, err)
+	}
+	if !bytes.Equal(header[:4], formID[:]) {
+		return nil, fmt.Errorf("%s - no FORM header at offset %d", ErrUnexpectedData, offset)
+	}
+	size := int64(binary.BigEndian.Uint32(header[4:8]))
+	return NewDecoder(io.NewSectionReader(r, offset, 8+size)), nil
+}
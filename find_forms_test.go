@@ -0,0 +1,67 @@
+package aiff
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestFindForms(t *testing.T) {
+	kick, err := ioutil.ReadFile("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sowt, err := ioutil.ReadFile("fixtures/sowt.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// put the second file across the boundary of two scanned blocks
+	blob := append(bytes.Repeat([]byte("x"), 37), kick...)
+	blob = append(blob, "FORMabcdWAVE"...)
+	blob = append(blob, bytes.Repeat([]byte("x"), findFormsBufSize-6-len(blob))...)
+	expected := []int64{37, int64(len(blob))}
+	blob = append(blob, sowt...)
+
+	offsets := FindForms(bytes.NewReader(blob))
+	if len(offsets) != len(expected) {
+		t.Fatalf("expected %v but got %v", expected, offsets)
+	}
+	for i, offset := range expected {
+		if offsets[i] != offset {
+			t.Fatalf("expected %v but got %v", expected, offsets)
+		}
+	}
+
+	for i, path := range []string{"fixtures/kick.aif", "fixtures/sowt.aif"} {
+		d, err := NewDecoderAt(bytes.NewReader(blob), offsets[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf, err := d.FullPCMBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ref, err := NewDecoder(f).FullPCMBuffer()
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(buf.Data) != len(ref.Data) {
+			t.Fatalf("%s: expected %d samples but got %d", path, len(ref.Data), len(buf.Data))
+		}
+		for j, v := range ref.Data {
+			if buf.Data[j] != v {
+				t.Fatalf("%s: sample %d didn't match, expected %d, got %d", path, j, v, buf.Data[j])
+			}
+		}
+	}
+
+	if _, err := NewDecoderAt(bytes.NewReader(blob), 0); err == nil {
+		t.Fatal("expected an error creating a decoder where there is no FORM header")
+	}
+}
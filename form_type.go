@@ -0,0 +1,54 @@
+package aiff
+
+import "fmt"
+
+// FormType is the type of file written by the encoder, plain AIFF or AIFC.
+type FormType int
+
+const (
+	// FormAuto writes an AIFF file when no encoding is set and an AIFC file
+	// otherwise. This is the default.
+	FormAuto FormType = iota
+	// FormAIFF writes an AIFF file, only big endian PCM can be stored.
+	FormAIFF
+	// FormAIFC writes an AIFC file (with its FVER chunk), the NONE encoding
+	// is used if no encoding is set.
+	FormAIFC
+)
+
+func (f FormType) String() string {
+	switch f {
+	case FormAuto:
+		return "auto"
+	case FormAIFF:
+		return "AIFF"
+	case FormAIFC:
+		return "AIFC"
+	}
+	return fmt.Sprintf("FormType(%d)", int(f))
+}
+
+// Form returns the type of file the encoder writes, FormAIFF or FormAIFC.
+// The AIFC form is picked automatically for any encoding, compressed,
+// little endian and float data can't be stored in an AIFF file. An error is
+// returned if FormAIFF is forced along with such an encoding.
+func (e *Encoder) Form() (FormType, error) {
+	if e == nil {
+		return FormAuto, fmt.Errorf("can't write a nil encoder")
+	}
+	switch e.FormType {
+	case FormAuto:
+		if e.Encoding == EncNotSet {
+			return FormAIFF, nil
+		}
+		return FormAIFC, nil
+	case FormAIFF:
+		if e.Encoding != EncNotSet && e.Encoding != EncNone {
+			return FormAIFF, fmt.Errorf("%s - the %q encoding requires an AIFC file", ErrFmtNotSupported, e.Encoding)
+		}
+		return FormAIFF, nil
+	case FormAIFC:
+		return FormAIFC, nil
+	}
+	return e.FormType, fmt.Errorf("invalid form type %v", e.FormType)
+}
@@ -0,0 +1,80 @@
+package aiff
+
+import (
+	"fmt"
+	"math"
+)
+
+// FwdToPCMAssuming forwards the decoder to the sound data like FwdToPCM but
+// uses the passed format instead of looking for the COMM chunk. The chunks
+// found before the SSND chunk are skipped without being parsed, which saves
+// the extra reads when the format of a batch of files is already known.
+// When info.NumSampleFrames is 0, the number of frames is derived from the
+// size of the sound data.
+func (d *Decoder) FwdToPCMAssuming(info FileInfo) error {
+	if d == nil {
+		return fmt.Errorf("can't forward a nil pointer to the PCM data")
+	}
+	if info.NumChans == 0 || info.SampleRate <= 0 {
+		return fmt.Errorf("%w - %d channels at %dHz", ErrFmtNotSupported, info.NumChans, info.SampleRate)
+	}
+	if !isSupportedEncoding(info.Encoding) {
+		return fmt.Errorf("%w - encoding %s", ErrFmtNotSupported, info.Encoding)
+	}
+	if _, ok := lookupCodec(info.Encoding); !ok {
+		switch info.BitDepth {
+		case 8, 16, 24, 32:
+		default:
+			return fmt.Errorf("%w - %d bit depth", ErrFmtNotSupported, info.BitDepth)
+		}
+	}
+	if d.err = d.readHeaders(); d.err != nil {
+		d.err = fmt.Errorf("failed to read header - %v", d.err)
+		return d.err
+	}
+
+	d.NumChans = info.NumChans
+	d.NumSampleFrames = info.NumSampleFrames
+	d.BitDepth = info.BitDepth
+	d.SampleRate = info.SampleRate
+	d.sampleRate = float64(info.SampleRate)
+	d.Encoding = info.Encoding
+	d.EncodingName = info.EncodingName
+	if byteOrder, ok := pcmByteOrder(d.Encoding); ok && d.forcedByteOrder == nil {
+		d.byteOrder = byteOrder
+	}
+	deriveFrames := d.NumSampleFrames == 0
+	if deriveFrames {
+		// don't let openSSND truncate the sound data
+		d.NumSampleFrames = math.MaxUint32
+	}
+
+	var chunk *Chunk
+	for d.err == nil {
+		chunk, d.err = d.NextChunk()
+		if d.err != nil {
+			d.err = fmt.Errorf("failed to read next chunk: %v", d.err)
+			return d.err
+		}
+		if chunk.ID != SSNDID || d.ssndSkipped < d.ssndIndex {
+			if chunk.ID == SSNDID {
+				d.ssndSkipped++
+			}
+			if d.err = d.skipChunk(chunk); d.err != nil {
+				d.err = fmt.Errorf("failed to skip the %q chunk - %v", chunk.ID, d.err)
+			}
+			continue
+		}
+		if err := d.openSSND(chunk); err != nil {
+			return err
+		}
+		if deriveFrames {
+			d.NumSampleFrames = 0
+			if frameSize := int64(bytesPerSample(int(d.BitDepth)) * int(d.NumChans)); frameSize > 0 {
+				d.NumSampleFrames = uint32(d.pcmLength / frameSize)
+			}
+		}
+		return nil
+	}
+	return d.err
+}
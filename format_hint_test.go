@@ -0,0 +1,80 @@
+package aiff
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestDecoder_FwdToPCMAssuming(t *testing.T) {
+	testCases := []struct {
+		input       string
+		knownFrames bool
+	}{
+		{"fixtures/kick.aif", true},
+		{"fixtures/kick.aif", false},
+		{"fixtures/sowt.aif", true},
+		{"fixtures/subsynth.aif", false},
+		{"fixtures/kick32b.aiff", true},
+	}
+
+	for _, tc := range testCases {
+		data, err := ioutil.ReadFile(tc.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := NewDecoder(bytes.NewReader(data))
+		want, err := d.FullPCMBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		info := FileInfo{
+			NumChans:     d.NumChans,
+			BitDepth:     d.BitDepth,
+			SampleRate:   d.SampleRate,
+			Encoding:     d.Encoding,
+			EncodingName: d.EncodingName,
+		}
+		if tc.knownFrames {
+			info.NumSampleFrames = d.NumSampleFrames
+		}
+
+		hinted := NewDecoder(bytes.NewReader(data))
+		if err := hinted.FwdToPCMAssuming(info); err != nil {
+			t.Fatalf("%s: %v", tc.input, err)
+		}
+		if hinted.NumSampleFrames != d.NumSampleFrames {
+			t.Errorf("%s: expected %d frames, got %d", tc.input, d.NumSampleFrames, hinted.NumSampleFrames)
+		}
+		if hinted.commSize != 0 {
+			t.Errorf("%s: expected the COMM chunk to be skipped", tc.input)
+		}
+		got, err := hinted.FullPCMBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got.Data, want.Data) {
+			t.Errorf("%s: the sound data doesn't match the data decoded using the COMM chunk", tc.input)
+		}
+	}
+}
+
+func TestDecoder_FwdToPCMAssuming_invalid(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, info := range []FileInfo{
+		{BitDepth: 16, SampleRate: 44100},
+		{NumChans: 1, BitDepth: 16},
+		{NumChans: 1, BitDepth: 12, SampleRate: 44100},
+		{NumChans: 1, BitDepth: 16, SampleRate: 44100, Encoding: Encoding{'?', '?', '?', '?'}},
+	} {
+		d := NewDecoder(bytes.NewReader(data))
+		if err := d.FwdToPCMAssuming(info); !errors.Is(err, ErrFmtNotSupported) {
+			t.Errorf("%+v: expected ErrFmtNotSupported, got %v", info, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// FrameIterator provides sample accurate random access to the frames of a
+// decoder. Frames are returned as small interleaved slices, the iterator
+// keeps its buffers around so scrubbing doesn't allocate.
+type FrameIterator struct {
+	d          *Decoder
+	start      int64
+	numFrames  int
+	bPerSample int
+	frameSize  int
+	pos        int
+	needsSeek  bool
+	raw        []byte
+	samples    []int
+}
+
+// NewFrameIterator creates an iterator over the frames of the passed decoder.
+// The iterator takes over the decoder's underlying reader, the decoder
+// shouldn't be used to read PCM data while iterating.
+func NewFrameIterator(d *Decoder) (*FrameIterator, error) {
+	if d == nil {
+		return nil, errors.New("can't iterate over a nil decoder")
+	}
+	start, length, err := d.PCMOffset()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := pcmByteOrder(d.Encoding); !ok {
+		return nil, fmt.Errorf("%s - %q encoding", ErrFmtNotSupported, d.Encoding)
+	}
+	it := &FrameIterator{
+		d:          d,
+		start:      start,
+		bPerSample: bytesPerSample(int(d.BitDepth)),
+		needsSeek:  true,
+	}
+	it.frameSize = it.bPerSample * int(d.NumChans)
+	if it.frameSize < 1 {
+		return nil, fmt.Errorf("%s - %d channels @ %d bits", ErrFmtNotSupported, d.NumChans, d.BitDepth)
+	}
+	it.numFrames = int(length) / it.frameSize
+	return it, nil
+}
+
+// NumFrames returns the total number of frames.
+func (it *FrameIterator) NumFrames() int {
+	return it.numFrames
+}
+
+// Frame returns the index of the next frame to be returned by Next.
+func (it *FrameIterator) Frame() int {
+	return it.pos
+}
+
+// SeekFrame moves the iterator to the frame at index i.
+func (it *FrameIterator) SeekFrame(i int) error {
+	if i < 0 || i > it.numFrames {
+		return fmt.Errorf("frame %d out of range [0, %d]", i, it.numFrames)
+	}
+	it.pos = i
+	it.needsSeek = true
+	return nil
+}
+
+// Next returns up to n interleaved frames starting at the current position
+// and moves the iterator forward. The returned slice is reused by the next
+// call. io.EOF is returned when no more frames are available.
+func (it *FrameIterator) Next(n int) ([]int, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("invalid number of frames: %d", n)
+	}
+	if it.pos >= it.numFrames {
+		return nil, io.EOF
+	}
+	if remaining := it.numFrames - it.pos; n > remaining {
+		n = remaining
+	}
+	if it.needsSeek {
+		if _, err := it.d.r.Seek(it.start+int64(it.pos*it.frameSize), io.SeekStart); err != nil {
+			return nil, err
+		}
+		it.needsSeek = false
+	}
+	size := n * it.frameSize
+	if cap(it.raw) < size {
+		it.raw = make([]byte, size)
+		it.samples = make([]int, n*int(it.d.NumChans))
+	}
+	raw := it.raw[:size]
+	m, err := io.ReadFull(it.d.r, raw)
+	m -= m % it.frameSize
+	samples := it.samples[:m/it.bPerSample]
+	for i := range samples {
+		samples[i] = decodeSample(raw[i*it.bPerSample:(i+1)*it.bPerSample], it.d.byteOrder)
+	}
+	it.pos += m / it.frameSize
+	if err != nil {
+		// the data is shorter than advertised
+		it.numFrames = it.pos
+		it.needsSeek = true
+		if len(samples) == 0 {
+			return nil, io.EOF
+		}
+	}
+	return samples, nil
+}
@@ -0,0 +1,84 @@
+package aiff
+
+import (
+	"io"
+	"os"
+	"testing"
+)
+
+func TestFrameIterator(t *testing.T) {
+	testCases := []string{
+		"fixtures/kick.aif",
+		"fixtures/sowt2.aif",
+		"fixtures/zipper24b.aiff",
+	}
+
+	for _, path := range testCases {
+		t.Run(path, func(t *testing.T) {
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			expected, err := NewDecoder(f).FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+
+			it, err := NewFrameIterator(NewDecoder(f))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if it.NumFrames() != expected.NumFrames() {
+				t.Fatalf("expected %d frames but got %d", expected.NumFrames(), it.NumFrames())
+			}
+			numChans := expected.Format.NumChannels
+			check := func(frame int, samples []int) {
+				for i, v := range samples {
+					if exp := expected.Data[frame*numChans+i]; v != exp {
+						t.Fatalf("sample %d of frame %d didn't match, expected %d, got %d", i, frame, exp, v)
+					}
+				}
+			}
+
+			// read everything in small steps
+			var read int
+			for {
+				frame := it.Frame()
+				samples, err := it.Next(100)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				check(frame, samples)
+				read += len(samples) / numChans
+			}
+			if read != it.NumFrames() {
+				t.Fatalf("expected to iterate over %d frames but got %d", it.NumFrames(), read)
+			}
+
+			// scrub around
+			for _, frame := range []int{it.NumFrames() - 3, 0, it.NumFrames() / 2, 1} {
+				if err := it.SeekFrame(frame); err != nil {
+					t.Fatal(err)
+				}
+				samples, err := it.Next(3)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(samples) != 3*numChans {
+					t.Fatalf("expected 3 frames at %d but got %d samples", frame, len(samples))
+				}
+				check(frame, samples)
+			}
+			if err := it.SeekFrame(it.NumFrames() + 1); err == nil {
+				t.Fatal("expected an error when seeking out of range")
+			}
+		})
+	}
+}
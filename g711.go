@@ -0,0 +1,170 @@
+package aiff
+
+import (
+	"bufio"
+	"io"
+)
+
+// g711Codec implements the ITU-T G.711 µ-law and A-law companding.
+// Each sample is stored in a single byte and decoded as a 16-bit value.
+type g711Codec struct {
+	encode func(v int16) byte
+	decode func(b byte) int16
+}
+
+func init() {
+	ulaw := g711Codec{encode: linearToUlaw, decode: ulawToLinear}
+	alaw := g711Codec{encode: linearToAlaw, decode: alawToLinear}
+	RegisterCodec(EncUlaw, ulaw)
+	RegisterCodec(EncULAW, ulaw)
+	RegisterCodec(EncAlaw, alaw)
+	RegisterCodec(EncALAW, alaw)
+}
+
+func (c g711Codec) NewSampleDecoder(r io.Reader, numChans, bitDepth int) (SampleDecoder, error) {
+	if bitDepth == 0 {
+		bitDepth = 16
+	}
+	return &g711Decoder{r: bufio.NewReader(r), decode: c.decode, bitDepth: bitDepth}, nil
+}
+
+func (c g711Codec) NewSampleEncoder(w io.Writer, numChans, bitDepth int) (SampleEncoder, error) {
+	return &g711Encoder{w: bufio.NewWriter(w), encode: c.encode, bitDepth: bitDepth}, nil
+}
+
+type g711Decoder struct {
+	r        *bufio.Reader
+	decode   func(b byte) int16
+	bitDepth int
+}
+
+func (d *g711Decoder) DecodeSamples(buf []int) (n int, err error) {
+	var b byte
+	for ; n < len(buf); n++ {
+		if b, err = d.r.ReadByte(); err != nil {
+			break
+		}
+		buf[n] = int(d.decode(b))
+	}
+	ConvertSamples(buf[:n], buf[:n], 16, d.bitDepth)
+	return n, err
+}
+
+type g711Encoder struct {
+	w        *bufio.Writer
+	encode   func(v int16) byte
+	bitDepth int
+}
+
+func (e *g711Encoder) EncodeSamples(samples []int) error {
+	for _, v := range samples {
+		v = scaleSample(v, e.bitDepth, 16)
+		if v > 32767 {
+			v = 32767
+		} else if v < -32768 {
+			v = -32768
+		}
+		if err := e.w.WriteByte(e.encode(int16(v))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *g711Encoder) Flush() error {
+	return e.w.Flush()
+}
+
+// scaleSample converts a signed sample from one bit depth to another.
+// A bit depth of 0 is treated as 16 bits.
+func scaleSample(v, from, to int) int {
+	if from == 0 {
+		from = 16
+	}
+	if to == 0 {
+		to = 16
+	}
+	if from > to {
+		return v >> uint(from-to)
+	}
+	return v << uint(to-from)
+}
+
+const (
+	ulawBias = 0x84
+	ulawClip = 32635
+)
+
+// linearToUlaw compresses a 16-bit sample using the µ-law algorithm.
+func linearToUlaw(v int16) byte {
+	s := int(v)
+	sign := 0
+	if s < 0 {
+		s = -s
+		sign = 0x80
+	}
+	if s > ulawClip {
+		s = ulawClip
+	}
+	s += ulawBias
+	exponent := 7
+	for mask := 0x4000; s&mask == 0 && exponent > 0; mask >>= 1 {
+		exponent--
+	}
+	mantissa := (s >> uint(exponent+3)) & 0x0F
+	return ^byte(sign | exponent<<4 | mantissa)
+}
+
+// ulawToLinear expands a µ-law byte into a 16-bit sample.
+func ulawToLinear(b byte) int16 {
+	b = ^b
+	exponent := int(b>>4) & 0x07
+	mantissa := int(b & 0x0F)
+	s := ((mantissa << 3) + ulawBias) << uint(exponent)
+	s -= ulawBias
+	if b&0x80 != 0 {
+		return int16(-s)
+	}
+	return int16(s)
+}
+
+// linearToAlaw compresses a 16-bit sample using the A-law algorithm.
+func linearToAlaw(v int16) byte {
+	s := int(v)
+	sign := 0x80
+	if s < 0 {
+		s = -s - 1
+		sign = 0
+	}
+	if s > 0x7FFF {
+		s = 0x7FFF
+	}
+	var b int
+	if s < 256 {
+		b = s >> 4
+	} else {
+		exponent := 7
+		for mask := 0x4000; s&mask == 0 && exponent > 1; mask >>= 1 {
+			exponent--
+		}
+		b = exponent<<4 | (s>>uint(exponent+3))&0x0F
+	}
+	return byte(b|sign) ^ 0x55
+}
+
+// alawToLinear expands an A-law byte into a 16-bit sample.
+func alawToLinear(b byte) int16 {
+	b ^= 0x55
+	exponent := int(b>>4) & 0x07
+	mantissa := int(b & 0x0F)
+	var s int
+	if exponent == 0 {
+		s = mantissa<<4 + 8
+	} else {
+		s = (mantissa<<4 + 0x108) << uint(exponent-1)
+	}
+	if b&0x80 == 0 {
+		return int16(-s)
+	}
+	return int16(s)
+}
@@ -0,0 +1,170 @@
+package aiff
+
+import (
+	"errors"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestG711(t *testing.T) {
+	testCases := []struct {
+		v    int16
+		ulaw byte
+		alaw byte
+	}{
+		{0, 0xFF, 0xD5},
+		{-1, 0x7F, 0x55},
+		{32767, 0x80, 0xAA},
+		{-32768, 0x00, 0x2A},
+		{1000, 0xCE, 0xFA},
+	}
+	for _, tc := range testCases {
+		if b := linearToUlaw(tc.v); b != tc.ulaw {
+			t.Errorf("expected %d to be µ-law encoded as %#x but got %#x", tc.v, tc.ulaw, b)
+		}
+		if b := linearToAlaw(tc.v); b != tc.alaw {
+			t.Errorf("expected %d to be A-law encoded as %#x but got %#x", tc.v, tc.alaw, b)
+		}
+	}
+
+	// all the code points survive a round trip
+	for i := 0; i < 256; i++ {
+		if b := linearToUlaw(ulawToLinear(byte(i))); ulawToLinear(b) != ulawToLinear(byte(i)) {
+			t.Fatalf("µ-law code %#x didn't survive a round trip, got %#x", i, b)
+		}
+		if b := linearToAlaw(alawToLinear(byte(i))); b != byte(i) {
+			t.Fatalf("A-law code %#x didn't survive a round trip, got %#x", i, b)
+		}
+	}
+}
+
+func TestEncoderG711(t *testing.T) {
+	in, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	d := NewDecoder(in)
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Mkdir("testOutput", 0777)
+	for _, encoding := range [][4]byte{EncUlaw, EncAlaw} {
+		t.Run(string(encoding[:]), func(t *testing.T) {
+			out, err := os.Create("testOutput/g711.aif")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(out.Name())
+			defer out.Close()
+			e := NewEncoder(out, d.SampleRate, 16, int(d.NumChans))
+			e.Encoding = encoding
+			if err := e.Write(buf); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			// one byte per sample
+			stat, err := out.Stat()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			if issues := Lint(out); len(issues) > 0 {
+				t.Fatalf("expected the encoded file to be spec compliant but got %v", issues)
+			}
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			d2 := NewDecoder(out)
+			buf2, err := d2.FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d2.Encoding != encoding {
+				t.Fatalf("expected the %q encoding but got %q", encoding, d2.Encoding)
+			}
+			if d2.NumSampleFrames != d.NumSampleFrames {
+				t.Fatalf("expected %d frames but got %d", d.NumSampleFrames, d2.NumSampleFrames)
+			}
+			if _, length, err := d2.PCMOffset(); err != nil || length != int64(len(buf.Data)) {
+				t.Fatalf("expected %d bytes of sound data but got %d (%v)", len(buf.Data), length, err)
+			}
+			if stat.Size() >= int64(len(buf.Data)*2) {
+				t.Fatalf("expected the encoded file to be smaller than the 16-bit PCM data, got %d bytes", stat.Size())
+			}
+			if len(buf2.Data) != len(buf.Data) {
+				t.Fatalf("expected %d samples but got %d", len(buf.Data), len(buf2.Data))
+			}
+			if err := d2.Rewind(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := d2.FullPCMBufferLimit(len(buf.Data) - 1); !errors.Is(err, ErrTooManySamples) {
+				t.Fatalf("expected ErrTooManySamples but got %v", err)
+			}
+			for i, v := range buf.Data {
+				// the quantization step is proportional to the magnitude
+				if diff := math.Abs(float64(v - buf2.Data[i])); diff > math.Abs(float64(v))/16+16 {
+					t.Fatalf("sample %d: expected a value close to %d but got %d", i, v, buf2.Data[i])
+				}
+			}
+		})
+	}
+
+	// samples of higher bit depths are scaled down
+	out, err := os.Create("testOutput/g711_24b.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	e := NewEncoder(out, 8000, 24, 1)
+	e.Encoding = EncUlaw
+	if err := e.Write(&audio.IntBuffer{Data: []int{1000 << 8}, Format: &audio.Format{NumChannels: 1, SampleRate: 8000}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	buf3, err := NewDecoder(out).FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf3.Data) != 1 || buf3.Data[0] != int(ulawToLinear(0xCE))<<8 {
+		t.Fatalf("expected the 24-bit sample to be scaled, got %v", buf3.Data)
+	}
+}
+
+// ulawTestFile writes a 16-bit mono µ-law file holding the passed samples
+// and returns its path, the file is removed at the end of the test.
+func ulawTestFile(t *testing.T, samples []int) string {
+	t.Helper()
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/" + t.Name() + "_ulaw.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(out.Name()) })
+	defer out.Close()
+	e := NewEncoder(out, 8000, 16, 1)
+	e.Encoding = EncUlaw
+	if err := e.Write(&audio.IntBuffer{Data: samples, Format: &audio.Format{NumChannels: 1, SampleRate: 8000}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Name()
+}
@@ -0,0 +1,121 @@
+package aiff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// iTunSMPBKey is the key of the iTunes gapless comment in Decoder.ID3.
+const iTunSMPBKey = "COMM:iTunSMPB"
+
+// GaplessInfo describes the frames added by a lossy encoder around the
+// original audio, as stored by iTunes in the iTunSMPB comment of the ID3
+// tag. Players skip the priming and remainder frames to play albums without
+// gaps.
+type GaplessInfo struct {
+	// Priming is the number of frames (encoder delay) at the start.
+	Priming uint32
+	// Remainder is the number of padding frames at the end.
+	Remainder uint32
+	// OriginalFrames is the number of frames of the original audio.
+	OriginalFrames uint64
+}
+
+// Gapless returns the gapless information stored in the iTunSMPB comment
+// of the ID3 chunk, false is returned if the file doesn't have one.
+// The ID3 chunk is parsed by Drain.
+func (d *Decoder) Gapless() (GaplessInfo, bool) {
+	var info GaplessInfo
+	if d == nil {
+		return info, false
+	}
+	v, ok := d.ID3[iTunSMPBKey]
+	if !ok {
+		return info, false
+	}
+	// " 00000000 <priming> <remainder> <original frames> ..." in hexadecimal
+	fields := strings.Fields(v)
+	if len(fields) < 4 {
+		return info, false
+	}
+	priming, err := strconv.ParseUint(fields[1], 16, 32)
+	if err != nil {
+		return info, false
+	}
+	remainder, err := strconv.ParseUint(fields[2], 16, 32)
+	if err != nil {
+		return info, false
+	}
+	if info.OriginalFrames, err = strconv.ParseUint(fields[3], 16, 64); err != nil {
+		return info, false
+	}
+	info.Priming, info.Remainder = uint32(priming), uint32(remainder)
+	return info, true
+}
+
+// itunSMPB formats the iTunSMPB comment, the original number of frames
+// starts at character 28.
+func itunSMPB(info GaplessInfo) string {
+	return fmt.Sprintf(" 00000000 %08X %08X %016X 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000",
+		info.Priming, info.Remainder, info.OriginalFrames)
+}
+
+// writeGapless writes an ID3 chunk holding the iTunSMPB comment. If the
+// original number of frames isn't set, it is computed when the encoder is
+// closed.
+func (e *Encoder) writeGapless() error {
+	text := itunSMPB(*e.Gapless)
+	// text encoding, language, description and text
+	frame := append([]byte("\x00eng"+"iTunSMPB\x00"), text...)
+	size := 10 + 10 + len(frame)
+	if err := e.AddBE(id3ID); err != nil {
+		return fmt.Errorf("%v when writing ID3 chunk ID header", err)
+	}
+	if err := e.AddBE(uint32(size)); err != nil {
+		return fmt.Errorf("%v when writing ID3 chunk size header", err)
+	}
+	// ID3v2.3 header, the size is a syncsafe integer
+	tagSize := 10 + len(frame)
+	header := []byte{'I', 'D', '3', 3, 0, 0,
+		byte(tagSize >> 21 & 0x7f), byte(tagSize >> 14 & 0x7f), byte(tagSize >> 7 & 0x7f), byte(tagSize & 0x7f)}
+	if err := e.AddBE(header); err != nil {
+		return fmt.Errorf("%v when writing the ID3 header", err)
+	}
+	if err := e.AddBE([]byte("COMM")); err != nil {
+		return fmt.Errorf("%v when writing the ID3 COMM frame ID", err)
+	}
+	if err := e.AddBE(uint32(len(frame))); err != nil {
+		return fmt.Errorf("%v when writing the ID3 COMM frame size", err)
+	}
+	if err := e.AddBE(uint16(0)); err != nil {
+		return fmt.Errorf("%v when writing the ID3 COMM frame flags", err)
+	}
+	e.gaplessPos = e.WrittenBytes + len(frame) - len(text) + 28
+	if size%2 != 0 {
+		frame = append(frame, 0)
+	}
+	if err := e.AddBE(frame); err != nil {
+		return fmt.Errorf("%v when writing the ID3 COMM frame", err)
+	}
+	return nil
+}
+
+// updateGapless writes the original number of frames in the iTunSMPB
+// comment when it wasn't set.
+func (e *Encoder) updateGapless() error {
+	if e.gaplessPos < 1 || e.Gapless.OriginalFrames > 0 {
+		return nil
+	}
+	frames := int64(e.frames) - int64(e.Gapless.Priming) - int64(e.Gapless.Remainder)
+	if frames < 0 {
+		frames = 0
+	}
+	if _, err := e.w.Seek(int64(e.gaplessPos), 0); err != nil {
+		return err
+	}
+	if err := e.AddBE([]byte(fmt.Sprintf("%016X", frames))); err != nil {
+		return fmt.Errorf("%v when writing the original number of frames", err)
+	}
+	return nil
+}
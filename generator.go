@@ -0,0 +1,94 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/go-audio/audio"
+)
+
+// generatorAmplitude is the amplitude (ratio of the full scale) of the
+// generated signals: -6 dBFS.
+const generatorAmplitude = 0.5
+
+// WriteSine writes an AIFF file containing a sine wave at the given frequency
+// (in Hz) lasting dur. The same signal is written to all the channels.
+// Note that the underlying writer is NOT being closed.
+func WriteSine(w io.WriteSeeker, freq float64, dur time.Duration, format *audio.Format, bitDepth int) error {
+	return writeSignal(w, dur, format, bitDepth, func(t float64) float64 {
+		return math.Sin(2 * math.Pi * freq * t)
+	})
+}
+
+// WriteSilence writes an AIFF file containing dur of digital silence.
+// Note that the underlying writer is NOT being closed.
+func WriteSilence(w io.WriteSeeker, dur time.Duration, format *audio.Format, bitDepth int) error {
+	return writeSignal(w, dur, format, bitDepth, func(t float64) float64 {
+		return 0
+	})
+}
+
+// WriteSweep writes an AIFF file containing an exponential sine sweep going
+// from startFreq to endFreq (in Hz) over dur. The same signal is written to
+// all the channels.
+// Note that the underlying writer is NOT being closed.
+func WriteSweep(w io.WriteSeeker, startFreq, endFreq float64, dur time.Duration, format *audio.Format, bitDepth int) error {
+	if startFreq <= 0 || endFreq <= 0 {
+		return fmt.Errorf("invalid sweep frequencies: %v -> %v", startFreq, endFreq)
+	}
+	if startFreq == endFreq {
+		return WriteSine(w, startFreq, dur, format, bitDepth)
+	}
+	length := dur.Seconds()
+	rate := math.Log(endFreq / startFreq)
+	return writeSignal(w, dur, format, bitDepth, func(t float64) float64 {
+		return math.Sin(2 * math.Pi * startFreq * length / rate * (math.Exp(t/length*rate) - 1))
+	})
+}
+
+// writeSignal encodes the signal returned by fn (in the -1, 1 range) for each
+// point in time (in seconds).
+func writeSignal(w io.WriteSeeker, dur time.Duration, format *audio.Format, bitDepth int, fn func(t float64) float64) error {
+	if format == nil {
+		return errors.New("can't generate a signal without a format")
+	}
+	if format.NumChannels < 1 || format.SampleRate < 1 {
+		return fmt.Errorf("invalid format: %d channels @ %d", format.NumChannels, format.SampleRate)
+	}
+	switch bitDepth {
+	case 8, 16, 24, 32:
+	default:
+		return fmt.Errorf("%v bit depth not supported", bitDepth)
+	}
+	if dur < 0 {
+		return fmt.Errorf("invalid duration: %v", dur)
+	}
+
+	numFrames := int(framesFor(dur, format.SampleRate))
+	amplitude := generatorAmplitude * float64(int(1)<<uint(bitDepth-1)-1)
+	e := NewEncoder(w, format.SampleRate, bitDepth, format.NumChannels)
+	if err := e.startPCMChunk(); err != nil {
+		return err
+	}
+	framesPerBuf := 4096
+	buf := &audio.IntBuffer{Format: format, Data: make([]int, framesPerBuf*format.NumChannels)}
+	for start := 0; start < numFrames; start += framesPerBuf {
+		n := numFrames - start
+		if n > framesPerBuf {
+			n = framesPerBuf
+		}
+		for i := 0; i < n; i++ {
+			v := int(math.Round(fn(float64(start+i)/float64(format.SampleRate)) * amplitude))
+			for j := 0; j < format.NumChannels; j++ {
+				buf.Data[i*format.NumChannels+j] = v
+			}
+		}
+		if err := e.Write(&audio.IntBuffer{Format: format, Data: buf.Data[:n*format.NumChannels]}); err != nil {
+			return err
+		}
+	}
+	return e.Close()
+}
@@ -0,0 +1,72 @@
+package aiff
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-audio/audio"
+)
+
+func TestGenerators(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	format := &audio.Format{NumChannels: 2, SampleRate: 44100}
+	testCases := []struct {
+		name     string
+		bitDepth int
+		write    func(f *os.File, bitDepth int) error
+		frames   int
+		// expected values of the first channel at given frames
+		samples map[int]int
+	}{
+		{"sine", 16, func(f *os.File, bitDepth int) error {
+			return WriteSine(f, 441, 100*time.Millisecond, format, bitDepth)
+		}, 4410, map[int]int{0: 0, 25: 16384, 50: 0, 75: -16384}},
+		{"silence", 24, func(f *os.File, bitDepth int) error {
+			// 30870 frames, float math gives 30869
+			return WriteSilence(f, 700*time.Millisecond, format, bitDepth)
+		}, 30870, map[int]int{0: 0, 1000: 0, 30869: 0}},
+		{"sweep", 16, func(f *os.File, bitDepth int) error {
+			return WriteSweep(f, 20, 20000, 100*time.Millisecond, format, bitDepth)
+		}, 4410, map[int]int{0: 0}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := os.Create("testOutput/generated.aif")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(f.Name())
+			defer f.Close()
+			if err := tc.write(f, tc.bitDepth); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			d := NewDecoder(f)
+			buf, err := d.FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int(d.NumSampleFrames) != tc.frames || buf.NumFrames() != tc.frames {
+				t.Fatalf("expected %d frames but got %d (header: %d)", tc.frames, buf.NumFrames(), d.NumSampleFrames)
+			}
+			if int(d.BitDepth) != tc.bitDepth {
+				t.Fatalf("expected a bit depth of %d but got %d", tc.bitDepth, d.BitDepth)
+			}
+			for frame, v := range tc.samples {
+				if buf.Data[frame*2] != v || buf.Data[frame*2+1] != v {
+					t.Errorf("expected frame %d to be %d but got %v", frame, v, buf.Data[frame*2:frame*2+2])
+				}
+			}
+			max := int(generatorAmplitude*float64(int(1)<<uint(tc.bitDepth-1)-1)) + 1
+			for i, v := range buf.Data {
+				if v > max || v < -max {
+					t.Fatalf("sample %d (%d) is louder than the generator amplitude", i, v)
+				}
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 module github.com/go-audio/aiff
 
-go 1.13
+go 1.18
 
 require (
 	github.com/go-audio/audio v1.0.0
 	github.com/go-audio/wav v1.0.0
 )
+
+require github.com/go-audio/riff v1.0.0 // indirect
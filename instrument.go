@@ -0,0 +1,87 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+)
+
+var instID = [4]byte{'I', 'N', 'S', 'T'}
+
+// LoopMode is the way a loop of the instrument chunk is played.
+type LoopMode int16
+
+const (
+	// LoopOff disables the loop.
+	LoopOff LoopMode = iota
+	// LoopForward plays the loop from its beginning to its end repeatedly.
+	LoopForward
+	// LoopForwardBackward plays the loop forward then backward repeatedly.
+	LoopForwardBackward
+)
+
+// Loop is a section of the sound data delimited by 2 markers.
+type Loop struct {
+	PlayMode LoopMode
+	// Begin and End are the IDs of the markers delimiting the loop, see
+	// Decoder.Markers.
+	Begin int16
+	End   int16
+}
+
+// Instrument is the content of the INST chunk describing how the sound
+// should be played by a sampler.
+type Instrument struct {
+	// BaseNote is the MIDI note at which the sound plays at its original
+	// pitch.
+	BaseNote uint8
+	// Detune is the pitch shift in cents (-50 to +50).
+	Detune int8
+	// LowNote and HighNote are the range of MIDI notes the sound is meant
+	// to be played at.
+	LowNote  uint8
+	HighNote uint8
+	// LowVelocity and HighVelocity are the range of MIDI velocities the
+	// sound is meant to be played at.
+	LowVelocity  uint8
+	HighVelocity uint8
+	// Gain in dB.
+	Gain int16
+	// SustainLoop is played while the note is held.
+	SustainLoop Loop
+	// ReleaseLoop is played once the note is released.
+	ReleaseLoop Loop
+}
+
+// parseInstChunk processes the INST chunk and stores it on the decoder.
+func (d *Decoder) parseInstChunk(chunk *Chunk) error {
+	if chunk.ID != instID {
+		return fmt.Errorf("unexpected INST chunk ID: %q", chunk.ID)
+	}
+	data, err := ioutil.ReadAll(chunk)
+	if err != nil {
+		return err
+	}
+	if len(data) < 20 {
+		return fmt.Errorf("INST chunk too short: %d bytes", len(data))
+	}
+	loop := func(b []byte) Loop {
+		return Loop{
+			PlayMode: LoopMode(binary.BigEndian.Uint16(b)),
+			Begin:    int16(binary.BigEndian.Uint16(b[2:])),
+			End:      int16(binary.BigEndian.Uint16(b[4:])),
+		}
+	}
+	d.Instrument = &Instrument{
+		BaseNote:     data[0],
+		Detune:       int8(data[1]),
+		LowNote:      data[2],
+		HighNote:     data[3],
+		LowVelocity:  data[4],
+		HighVelocity: data[5],
+		Gain:         int16(binary.BigEndian.Uint16(data[6:])),
+		SustainLoop:  loop(data[8:]),
+		ReleaseLoop:  loop(data[14:]),
+	}
+	return nil
+}
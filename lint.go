@@ -0,0 +1,229 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+var (
+	fverID = [4]byte{'F', 'V', 'E', 'R'}
+	markID = [4]byte{'M', 'A', 'R', 'K'}
+)
+
+// aifcVersion1 is the only timestamp allowed in the FVER chunk.
+const aifcVersion1 = 0xA2805140
+
+// Lint checks the passed file against the AIFF/AIFC specifications and
+// returns the list of compliance issues found. Lint is stricter than Validate
+// and is aimed at verifying files generated by encoders: pad bytes after odd
+// sized chunks, pascal string padding, chunk ordering, presence of the AIFC
+// FVER chunk and the size of the COMM chunk are checked.
+func Lint(r io.ReadSeeker) []ValidationIssue {
+	d := NewDecoder(r)
+	issues := []ValidationIssue{}
+	report := func(check string, severity Severity, offset int64, format string, args ...interface{}) {
+		issues = append(issues, ValidationIssue{
+			Check:    check,
+			Severity: severity,
+			Offset:   offset,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+	if r == nil {
+		report("reader", SeverityError, -1, "nil reader")
+		return issues
+	}
+	fileSize, err := d.fileSize()
+	if err != nil {
+		report("reader", SeverityError, -1, "failed to get the size of the file - %v", err)
+		return issues
+	}
+	var header [12]byte
+	if n, _ := d.ra.ReadAt(header[:], 0); n < len(header) {
+		report("header", SeverityError, 0, "file too short to contain a FORM header (%d bytes)", fileSize)
+		return issues
+	}
+	form := [4]byte{header[8], header[9], header[10], header[11]}
+	if [4]byte{header[0], header[1], header[2], header[3]} != formID || (form != aiffID && form != aifcID) {
+		report("header", SeverityError, 0, "not an AIFF/AIFC file")
+		return issues
+	}
+	if formSize := int64(binary.BigEndian.Uint32(header[4:8])); formSize+8 != fileSize {
+		report("form-size", SeverityError, 4, "FORM size (%d) doesn't match the file size (%d - 8 bytes)", formSize, fileSize)
+	}
+
+	var (
+		offset  int64 = 12
+		seen          = map[[4]byte]int{}
+		hasFVER bool
+	)
+	for offset+8 <= fileSize {
+		id, size, err := d.iDnSizeAt(offset)
+		if err != nil || !isChunkID(id) {
+			report("chunk", SeverityError, offset, "invalid chunk header")
+			return issues
+		}
+		end := offset + 8 + int64(size)
+		if end > fileSize {
+			report("chunk-size", SeverityError, offset, "%s chunk size (%d) goes past the end of the file", id[:], size)
+			return issues
+		}
+		if size%2 != 0 {
+			if end == fileSize {
+				report("pad-byte", SeverityError, offset, "odd sized %s chunk (%d) isn't followed by a pad byte", id[:], size)
+			} else if pad, _ := d.idAt(end); isChunkID(pad) {
+				report("pad-byte", SeverityError, offset, "odd sized %s chunk (%d) isn't followed by a pad byte", id[:], size)
+			} else {
+				if pad[0] != 0 {
+					report("pad-byte", SeverityWarning, end, "pad byte after the %s chunk isn't 0", id[:])
+				}
+				end++
+			}
+		}
+		seen[id]++
+		if seen[id] > 1 && (id == COMMID || id == SSNDID || id == fverID || id == markID || id == COMTID) {
+			report("chunk-order", SeverityError, offset, "only one %s chunk is allowed", id[:])
+		}
+
+		var data []byte
+		switch id {
+		case COMMID, fverID, markID, COMTID:
+			data = make([]byte, size)
+			if n, _ := d.ra.ReadAt(data, offset+8); n < len(data) {
+				report("chunk-size", SeverityError, offset, "failed to read the %s chunk", id[:])
+				data = nil
+			}
+		}
+		switch id {
+		case COMMID:
+			if data != nil {
+				lintCommChunk(data, form, offset, report)
+			}
+		case SSNDID:
+			if seen[COMMID] == 0 {
+				report("chunk-order", SeverityWarning, offset, "the SSND chunk should come after the COMM chunk")
+			}
+		case fverID:
+			hasFVER = true
+			if form != aifcID {
+				report("fver", SeverityWarning, offset, "FVER chunk found in an AIFF file")
+			}
+			if len(data) != 4 {
+				report("fver", SeverityError, offset, "expected a 4 byte FVER chunk but got %d bytes", size)
+			} else if v := binary.BigEndian.Uint32(data); v != aifcVersion1 {
+				report("fver", SeverityWarning, offset, "unexpected AIFC version timestamp: %#x", v)
+			}
+		case markID:
+			if data != nil {
+				lintMarkChunk(data, offset, report)
+			}
+		case COMTID:
+			if data != nil {
+				lintCommentsChunk(data, offset, report)
+			}
+		}
+		offset = end
+	}
+	if offset != fileSize {
+		report("chunk", SeverityError, offset, "%d trailing bytes after the last chunk", fileSize-offset)
+	}
+	if seen[COMMID] == 0 {
+		report("comm", SeverityError, -1, "missing COMM chunk")
+	}
+	if form == aifcID && !hasFVER {
+		report("fver", SeverityError, -1, "missing FVER chunk, required in AIFC files")
+	}
+
+	return issues
+}
+
+func lintCommChunk(data []byte, form [4]byte, offset int64, report func(string, Severity, int64, string, ...interface{})) {
+	if form == aiffID {
+		if len(data) != 18 {
+			report("comm", SeverityError, offset, "AIFF COMM chunk should be 18 bytes but is %d", len(data))
+		}
+		return
+	}
+	if len(data) < 23 {
+		report("comm", SeverityError, offset, "AIFC COMM chunk should be at least 23 bytes but is %d", len(data))
+		return
+	}
+	nameLen := int(data[22])
+	expected := 22 + pstringSize(nameLen)
+	if len(data) != expected {
+		report("comm", SeverityError, offset, "AIFC COMM chunk with a %d char compression name should be %d bytes but is %d", nameLen, expected, len(data))
+		return
+	}
+	lintPstringPad(data[22:], offset+8+22, "compression name", report)
+}
+
+func lintMarkChunk(data []byte, offset int64, report func(string, Severity, int64, string, ...interface{})) {
+	if len(data) < 2 {
+		report("mark", SeverityError, offset, "MARK chunk too short")
+		return
+	}
+	numMarkers := int(binary.BigEndian.Uint16(data))
+	pos := 2
+	for i := 0; i < numMarkers; i++ {
+		// ID (2) + position (4) + pstring name
+		if pos+7 > len(data) {
+			report("mark", SeverityError, offset, "marker %d goes past the end of the MARK chunk", i)
+			return
+		}
+		pos += 6
+		size := pstringSize(int(data[pos]))
+		if pos+size > len(data) {
+			report("mark", SeverityError, offset, "name of marker %d goes past the end of the MARK chunk", i)
+			return
+		}
+		lintPstringPad(data[pos:pos+size], offset+8+int64(pos), fmt.Sprintf("name of marker %d", i), report)
+		pos += size
+	}
+	if pos != len(data) {
+		report("mark", SeverityWarning, offset, "%d unused bytes at the end of the MARK chunk", len(data)-pos)
+	}
+}
+
+func lintCommentsChunk(data []byte, offset int64, report func(string, Severity, int64, string, ...interface{})) {
+	if len(data) < 2 {
+		report("comt", SeverityError, offset, "COMT chunk too short")
+		return
+	}
+	numComments := int(binary.BigEndian.Uint16(data))
+	pos := 2
+	for i := 0; i < numComments; i++ {
+		// timestamp (4) + marker ID (2) + count (2) + text
+		if pos+8 > len(data) {
+			report("comt", SeverityError, offset, "comment %d goes past the end of the COMT chunk", i)
+			return
+		}
+		count := int(binary.BigEndian.Uint16(data[pos+6:]))
+		pos += 8 + count
+		// the text of the last comment can rely on the chunk pad byte
+		if count%2 != 0 && pos < len(data) {
+			if data[pos] != 0 {
+				report("pstring", SeverityWarning, offset+8+int64(pos), "pad byte after comment %d isn't 0", i)
+			}
+			pos++
+		}
+		if pos > len(data) {
+			report("comt", SeverityError, offset, "comment %d goes past the end of the COMT chunk", i)
+			return
+		}
+	}
+	if pos != len(data) {
+		report("comt", SeverityWarning, offset, "%d unused bytes at the end of the COMT chunk", len(data)-pos)
+	}
+}
+
+func lintPstringPad(p []byte, offset int64, name string, report func(string, Severity, int64, string, ...interface{})) {
+	n := int(p[0])
+	if pstringSize(n) > len(p) {
+		report("pstring", SeverityError, offset, "%s isn't padded to an even size", name)
+		return
+	}
+	if (1+n)%2 != 0 && p[1+n] != 0 {
+		report("pstring", SeverityWarning, offset+int64(1+n), "pad byte after the %s isn't 0", name)
+	}
+}
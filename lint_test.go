@@ -0,0 +1,70 @@
+package aiff
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	testCases := []struct {
+		input  string
+		checks []string
+	}{
+		{"fixtures/kick.aif", nil},
+		{"fixtures/ableton.aif", nil},
+		{"fixtures/ring.aif", []string{"comt"}},
+		{"fixtures/sowt.aif", []string{"fver"}},
+		{"fixtures/padded24b.aif", []string{"pad-byte", "pad-byte"}},
+		{"fixtures/say.aif", []string{"comm"}},
+		{"fixtures/kick.wav", []string{"header"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			f, err := os.Open(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			issues := Lint(f)
+			if len(issues) != len(tc.checks) {
+				t.Fatalf("expected %d issues but got %d: %v", len(tc.checks), len(issues), issues)
+			}
+			for i, issue := range issues {
+				if issue.Check != tc.checks[i] {
+					t.Fatalf("expected issue %d to be reported by %s but got %s", i, tc.checks[i], issue)
+				}
+			}
+		})
+	}
+}
+
+func TestLint_encoderOutput(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	in, err := os.Open("fixtures/bloop.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	d := NewDecoder(in)
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.Create("testOutput/lint.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	e := NewEncoder(out, d.SampleRate, int(d.BitDepth), int(d.NumChans))
+	if err := e.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if issues := Lint(out); len(issues) > 0 {
+		t.Fatalf("expected the encoded file to be spec compliant but got %v", issues)
+	}
+}
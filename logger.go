@@ -0,0 +1,16 @@
+package aiff
+
+// Logger receives the diagnostic messages of the decoder, such as the
+// metadata chunks that failed to parse and were skipped. *log.Logger
+// implements it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// logf sends a message to the logger of the decoder if one is set.
+func (d *Decoder) logf(format string, v ...interface{}) {
+	if d == nil || d.Logger == nil {
+		return
+	}
+	d.Logger.Printf(format, v...)
+}
@@ -0,0 +1,97 @@
+package aiff
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testLogger struct {
+	messages []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func TestDecoder_Logger(t *testing.T) {
+	// the ID3 chunk is invalid, it's skipped and logged
+	data := withChunks(t, "fixtures/kick.aif", testChunk("ID3 ", []byte("not a tag")))
+
+	d := NewDecoder(bytes.NewReader(data))
+	if err := d.Drain(); err != nil {
+		t.Fatalf("expected the broken chunk to be ignored but got %v", err)
+	}
+
+	logger := &testLogger{}
+	d = NewDecoder(bytes.NewReader(data))
+	d.Logger = logger
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if len(logger.messages) != 1 || !strings.Contains(logger.messages[0], "ID3") {
+		t.Fatalf("expected the ID3 chunk failure to be logged but got %q", logger.messages)
+	}
+}
+
+func TestDecoder_errors(t *testing.T) {
+	invalid := []byte("RIFF\x00\x00\x00\x04WAVE")
+	d := NewDecoder(bytes.NewReader(invalid))
+	if err := d.ReadInfo(); err == nil {
+		t.Fatal("expected ReadInfo to fail")
+	}
+	d = NewDecoder(bytes.NewReader(invalid))
+	if err := d.FwdToPCM(); err == nil {
+		t.Fatal("expected FwdToPCM to fail")
+	}
+	if d.Err() == nil {
+		t.Fatal("expected the error to be reported by Err")
+	}
+	if err := d.Rewind(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Err() != nil {
+		t.Fatalf("expected the error to be cleared by Rewind but got %v", d.Err())
+	}
+}
+
+func TestDecoder_NoProcessOutput(t *testing.T) {
+	stdout, stderr := os.Stdout, os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout, os.Stderr = w, w
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+	output := make(chan []byte)
+	go func() {
+		out, _ := ioutil.ReadAll(r)
+		output <- out
+	}()
+
+	Debug = true
+	defer func() { Debug = false }()
+	paths, err := filepath.Glob("fixtures/*.aif*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := NewDecoder(f)
+		d.FullPCMBuffer()
+		d.Drain()
+		f.Close()
+	}
+	os.Stdout, os.Stderr = stdout, stderr
+	w.Close()
+	if out := <-output; len(out) > 0 {
+		t.Fatalf("expected the decoder not to write to stdout or stderr but got %q", out)
+	}
+}
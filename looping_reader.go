@@ -0,0 +1,165 @@
+package aiff
+
+import (
+	"fmt"
+	"io"
+)
+
+// LoopingReader reads the frames of an instrument file (see Instrument)
+// like Reader but plays its sustain loop: once the end of the loop is
+// reached, the reading continues from the start of the loop (or backward
+// for LoopForwardBackward loops). The rest of the file is read once the loop
+// was repeated the requested number of times or after Release.
+// Files without a sustain loop are read straight.
+type LoopingReader struct {
+	r *Reader
+	// mode is the play mode of the sustain loop, LoopOff when there's
+	// nothing to loop.
+	mode LoopMode
+	// start and end delimit the loop, end is excluded.
+	start int64
+	end   int64
+	// loops is the number of repetitions left, negative to loop forever.
+	loops    int
+	backward bool
+	pos      int64
+}
+
+// NewLoopingReader reads the file information, including the INST and MARK
+// chunks, and returns a reader positioned on the first frame. The sustain
+// loop is repeated loops times, a negative value repeats it until Release is
+// called. An error is returned if the file doesn't have an INST chunk.
+func NewLoopingReader(r io.ReadSeeker, loops int) (*LoopingReader, error) {
+	// the INST and MARK chunks might be stored after the sound data
+	meta := NewDecoder(r)
+	region, err := meta.SamplerRegion("")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	fr, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	d := fr.Decoder()
+	if d.Instrument == nil {
+		d.Instrument, d.Markers = meta.Instrument, meta.Markers
+	}
+	l := &LoopingReader{r: fr, loops: loops}
+	if region.LoopMode == LoopOff {
+		return l, nil
+	}
+	if _, ok := pcmByteOrder(d.Encoding); !ok {
+		return nil, fmt.Errorf("%w - can't loop %q encoded data", ErrFmtNotSupported, d.Encoding)
+	}
+	l.start, l.end = int64(region.LoopStart), int64(region.LoopEnd)+1
+	if numFrames := fr.Format().NumFrames; l.end > numFrames {
+		l.end = numFrames
+	}
+	if l.start < l.end {
+		l.mode = region.LoopMode
+	}
+	return l, nil
+}
+
+// Format returns the format of the frames.
+func (l *LoopingReader) Format() FrameFormat {
+	return l.r.Format()
+}
+
+// Decoder returns the decoder used to read the file, giving access to its
+// metadata. It shouldn't be used to read the sound data.
+func (l *LoopingReader) Decoder() *Decoder {
+	return l.r.Decoder()
+}
+
+// Loop returns the play mode of the sustain loop and the frames delimiting
+// it, end being excluded. The mode is LoopOff if the file doesn't have a
+// sustain loop.
+func (l *LoopingReader) Loop() (mode LoopMode, start, end int64) {
+	return l.mode, l.start, l.end
+}
+
+// Frame returns the index of the next frame to be read.
+func (l *LoopingReader) Frame() int64 {
+	return l.pos
+}
+
+// Release stops the looping, like a sampler does once the note is released:
+// the current repetition of the loop is completed and the rest of the file
+// is read.
+func (l *LoopingReader) Release() {
+	l.loops = 0
+}
+
+func (l *LoopingReader) looping() bool {
+	return l.mode != LoopOff && l.loops != 0
+}
+
+// ReadFrames reads up to len(dst)/NumChans interleaved frames into dst and
+// returns the number of frames read. A read never crosses the boundaries of
+// the loop. io.EOF is returned when no more frames are available.
+func (l *LoopingReader) ReadFrames(dst []int) (frames int, err error) {
+	numChans := l.r.Format().NumChans
+	if len(dst) < numChans {
+		return 0, fmt.Errorf("a buffer of %d samples can't hold a frame of %d channels", len(dst), numChans)
+	}
+	if l.backward {
+		return l.readBackward(dst)
+	}
+	n := int64(len(dst) / numChans)
+	if l.mode != LoopOff && l.pos < l.end && l.pos+n > l.end {
+		n = l.end - l.pos
+	}
+	frames, err = l.r.ReadFrames(dst[:n*int64(numChans)])
+	l.pos += int64(frames)
+	if err != nil || l.pos != l.end || !l.looping() {
+		return frames, err
+	}
+	if l.loops > 0 {
+		l.loops--
+	}
+	if l.mode == LoopForwardBackward {
+		l.backward = true
+		return frames, nil
+	}
+	if err := l.r.SeekFrame(l.start); err != nil {
+		return frames, err
+	}
+	l.pos = l.start
+	return frames, nil
+}
+
+// readBackward reads the frames preceding the position in reverse order,
+// down to the start of the loop.
+func (l *LoopingReader) readBackward(dst []int) (int, error) {
+	numChans := l.r.Format().NumChans
+	n := int64(len(dst) / numChans)
+	if l.pos-n < l.start {
+		n = l.pos - l.start
+	}
+	from := l.pos - n
+	if err := l.r.SeekFrame(from); err != nil {
+		return 0, err
+	}
+	frames, err := l.r.ReadFrames(dst[:n*int64(numChans)])
+	if err != nil {
+		return frames, err
+	}
+	for i, j := 0, frames-1; i < j; i, j = i+1, j-1 {
+		a, b := dst[i*numChans:(i+1)*numChans], dst[j*numChans:(j+1)*numChans]
+		for c := range a {
+			a[c], b[c] = b[c], a[c]
+		}
+	}
+	l.pos = from
+	if l.pos == l.start {
+		l.backward = false
+	}
+	if err := l.r.SeekFrame(l.pos); err != nil {
+		return frames, err
+	}
+	return frames, nil
+}
@@ -0,0 +1,116 @@
+package aiff
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// readAllFrames reads the frames of r in small buffers until io.EOF or
+// until max samples were read.
+func readAllFrames(t *testing.T, r *LoopingReader, max int) []int {
+	var out []int
+	buf := make([]int, 300)
+	for len(out) < max {
+		n, err := r.ReadFrames(buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, buf[:n]...)
+	}
+	return out
+}
+
+func TestLoopingReader(t *testing.T) {
+	inst := []byte{60, 0, 0, 0, 0, 0, 0, 0,
+		0, 1, 0, 1, 0, 2, // sustain loop
+		0, 0, 0, 0, 0, 0} // release loop
+	// 2 markers: #1 @ frame 100 and #2 @ frame 1100
+	mark := []byte{0, 2,
+		0, 1, 0, 0, 0, 100, 0, 0,
+		0, 2, 0, 0, 0x04, 0x4c, 0, 0}
+	forward := withChunks(t, "fixtures/kick.aif", testChunk("INST", inst), testChunk("MARK", mark))
+	inst[9] = byte(LoopForwardBackward)
+	pingPong := withChunks(t, "fixtures/kick.aif", testChunk("INST", inst), testChunk("MARK", mark))
+
+	f, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	buf, err := NewDecoder(f).FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pcm := buf.Data
+	loop := pcm[100:1100]
+	reversed := make([]int, len(loop))
+	for i, v := range loop {
+		reversed[len(loop)-1-i] = v
+	}
+	concat := func(parts ...[]int) []int {
+		var out []int
+		for _, p := range parts {
+			out = append(out, p...)
+		}
+		return out
+	}
+
+	testCases := []struct {
+		name     string
+		data     []byte
+		loops    int
+		expected []int
+	}{
+		{"no repetition", forward, 0, pcm},
+		{"forward", forward, 2, concat(pcm[:1100], loop, loop, pcm[1100:])},
+		{"forward backward", pingPong, 2, concat(pcm[:1100], reversed, loop, reversed, loop, pcm[1100:])},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewLoopingReader(bytes.NewReader(tc.data), tc.loops)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mode, start, end := r.Loop(); mode == LoopOff || start != 100 || end != 1100 {
+				t.Fatalf("unexpected loop %v [%d, %d)", mode, start, end)
+			}
+			got := readAllFrames(t, r, len(tc.expected)+1)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("expected %d samples, got %d not matching the expected sequence", len(tc.expected), len(got))
+			}
+		})
+	}
+
+	t.Run("release", func(t *testing.T) {
+		r, err := NewLoopingReader(bytes.NewReader(forward), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 10 repetitions
+		got := readAllFrames(t, r, 1100+10*len(loop))
+		if r.Frame() != 100 {
+			t.Fatalf("expected to be back at the start of the loop, got frame %d", r.Frame())
+		}
+		// the repetition started is completed
+		r.Release()
+		got = append(got, readAllFrames(t, r, len(pcm))...)
+		expected := pcm[:1100:1100]
+		for i := 0; i < 11; i++ {
+			expected = append(expected, loop...)
+		}
+		expected = append(expected, pcm[1100:]...)
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("expected %d samples, got %d not matching the expected sequence", len(expected), len(got))
+		}
+	})
+
+	if _, err := NewLoopingReader(bytes.NewReader(withChunks(t, "fixtures/kick.aif")), -1); err == nil {
+		t.Fatal("expected an error when the file doesn't have an INST chunk")
+	}
+}
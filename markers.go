@@ -0,0 +1,84 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// Marker points to a position in the sound data.
+type Marker struct {
+	// ID is a positive number identifying the marker, unique in the file.
+	ID int16
+	// Position is the frame the marker points to, markers are placed
+	// before the frame.
+	Position uint32
+	Name     string
+}
+
+// parseMarkChunk processes the MARK chunk and stores the markers on the
+// decoder.
+func (d *Decoder) parseMarkChunk(chunk *Chunk) error {
+	if chunk.ID != markID {
+		return fmt.Errorf("unexpected MARK chunk ID: %q", chunk.ID)
+	}
+	data, err := ioutil.ReadAll(chunk)
+	if err != nil {
+		return err
+	}
+	if len(data) < 2 {
+		return errors.New("MARK chunk too short")
+	}
+	numMarkers := int(binary.BigEndian.Uint16(data))
+	pos := 2
+	for i := 0; i < numMarkers; i++ {
+		if pos+6 > len(data) {
+			return fmt.Errorf("marker %d goes past the end of the MARK chunk", i)
+		}
+		m := Marker{
+			ID:       int16(binary.BigEndian.Uint16(data[pos:])),
+			Position: binary.BigEndian.Uint32(data[pos+2:]),
+		}
+		name, n, err := parsePstring(data[pos+6:])
+		if err != nil {
+			return fmt.Errorf("failed to read the name of marker %d - %v", i, err)
+		}
+		m.Name = d.decodeText([]byte(name))
+		pos += 6 + n
+		d.Markers = append(d.Markers, m)
+	}
+	return nil
+}
+
+// writeMarkers writes the MARK chunk.
+func (e *Encoder) writeMarkers() error {
+	size := 2
+	for i, m := range e.Markers {
+		if m.ID < 1 {
+			return fmt.Errorf("invalid ID for marker %d: %d, IDs must be positive", i, m.ID)
+		}
+		size += 6 + len(pstring(m.Name))
+	}
+	if err := e.AddBE(markID); err != nil {
+		return fmt.Errorf("%v when writing MARK chunk ID header", err)
+	}
+	if err := e.AddBE(uint32(size)); err != nil {
+		return fmt.Errorf("%v when writing MARK chunk size header", err)
+	}
+	if err := e.AddBE(uint16(len(e.Markers))); err != nil {
+		return fmt.Errorf("%v when writing the number of markers", err)
+	}
+	for _, m := range e.Markers {
+		if err := e.AddBE(m.ID); err != nil {
+			return fmt.Errorf("%v when writing marker ID", err)
+		}
+		if err := e.AddBE(m.Position); err != nil {
+			return fmt.Errorf("%v when writing marker position", err)
+		}
+		if err := e.AddBE(pstring(m.Name)); err != nil {
+			return fmt.Errorf("%v when writing marker name", err)
+		}
+	}
+	return nil
+}
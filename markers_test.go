@@ -0,0 +1,100 @@
+package aiff
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDecoder_Markers(t *testing.T) {
+	testCases := []struct {
+		input   string
+		markers []Marker
+	}{
+		{"fixtures/sowt.aif", []Marker{{ID: 1}, {ID: 2, Position: 1}}},
+		{"fixtures/ring.aif", []Marker{{ID: 1, Name: "Tempo: 98.0"}, {ID: 2, Name: "Timestamp: 158848064"}}},
+		{"fixtures/kick.aif", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			f, err := os.Open(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			d := NewDecoder(f)
+			if err := d.Drain(); err != nil {
+				t.Fatal(err)
+			}
+			if len(d.Markers) != len(tc.markers) {
+				t.Fatalf("expected %d markers but got %v", len(tc.markers), d.Markers)
+			}
+			for i, m := range tc.markers {
+				if d.Markers[i] != m {
+					t.Fatalf("expected marker %d to be %+v but got %+v", i, m, d.Markers[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEncoderMarkers(t *testing.T) {
+	in, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	buf, err := NewDecoder(in).FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	markers := []Marker{
+		{ID: 1, Position: 0, Name: "start"},
+		{ID: 2, Position: 100, Name: ""},
+		{ID: 3, Position: 4000, Name: "odd"},
+		{ID: 4, Position: 4484, Name: "even"},
+	}
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/markers.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	e := NewEncoder(out, 22050, 16, 1)
+	e.Markers = markers
+	if err := e.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if issues := Lint(out); len(issues) > 0 {
+		t.Fatalf("expected the encoded file to be spec compliant but got %v", issues)
+	}
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(out)
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Markers) != len(markers) {
+		t.Fatalf("expected %d markers but got %v", len(markers), d.Markers)
+	}
+	for i, m := range markers {
+		if d.Markers[i] != m {
+			t.Fatalf("expected marker %d to be %+v but got %+v", i, m, d.Markers[i])
+		}
+	}
+
+	e = NewEncoder(out, 22050, 16, 1)
+	e.Markers = []Marker{{ID: 0}}
+	if err := e.Write(buf); err == nil {
+		t.Fatal("expected an error writing a marker without a valid ID")
+	}
+}
@@ -0,0 +1,222 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Generic metadata keys used by Metadata.
+const (
+	MetadataTitle     = "title"
+	MetadataArtist    = "artist"
+	MetadataAlbum     = "album"
+	MetadataGenre     = "genre"
+	MetadataTrack     = "track"
+	MetadataDate      = "date"
+	MetadataComposer  = "composer"
+	MetadataCopyright = "copyright"
+	MetadataComment   = "comment"
+)
+
+// id3Keys maps the ID3v2 frames to the generic metadata keys.
+var id3Keys = map[string]string{
+	"TIT2": MetadataTitle,
+	"TPE1": MetadataArtist,
+	"TALB": MetadataAlbum,
+	"TCON": MetadataGenre,
+	"TRCK": MetadataTrack,
+	"TYER": MetadataDate,
+	"TDRC": MetadataDate,
+	"TCOM": MetadataComposer,
+	"TCOP": MetadataCopyright,
+	"COMM": MetadataComment,
+}
+
+// Metadata returns the metadata of the file using generic keys (see the
+// Metadata* constants) so it can be passed to the tagging API of other
+// encoders (mp3, aac...). The values of the ID3 tag take precedence, the
+// NAME, AUTH, (c) , ANNO and COMT chunks are used for the missing keys.
+// The metadata chunks are parsed by Drain.
+func (d *Decoder) Metadata() map[string]string {
+	m := map[string]string{}
+	if d == nil {
+		return m
+	}
+	for id, v := range d.ID3 {
+		if key, ok := id3Keys[id]; ok && v != "" {
+			m[key] = v
+		}
+	}
+	set := func(key, v string) {
+		if _, ok := m[key]; !ok && v != "" {
+			m[key] = v
+		}
+	}
+	set(MetadataTitle, d.Name)
+	set(MetadataArtist, d.Author)
+	set(MetadataCopyright, d.Copyright)
+	set(MetadataComment, strings.Join(d.Annotations, "\n"))
+	set(MetadataComment, strings.Join(d.Comments, "\n"))
+	return m
+}
+
+// parseTextChunk processes the NAME, AUTH, (c)  and ANNO chunks.
+func (d *Decoder) parseTextChunk(chunk *Chunk) error {
+	b, err := ioutil.ReadAll(chunk)
+	if err != nil {
+		return fmt.Errorf("failed to read the %q chunk - %v", chunk.ID, err)
+	}
+	text := d.decodeText(bytes.TrimRight(b, "\x00"))
+	switch chunk.ID {
+	case nameID:
+		d.Name = text
+	case authID:
+		d.Author = text
+	case copyrightID:
+		d.Copyright = text
+	case annoID:
+		d.Annotations = append(d.Annotations, text)
+	}
+	return nil
+}
+
+// parseID3Chunk extracts the text frames of the ID3v2.3/2.4 tag contained
+// in the chunk.
+func (d *Decoder) parseID3Chunk(chunk *Chunk) error {
+	b, err := ioutil.ReadAll(chunk)
+	if err != nil {
+		return err
+	}
+	if len(b) < 10 || string(b[:3]) != "ID3" {
+		return errors.New("invalid ID3 header")
+	}
+	version := b[3]
+	if version != 3 && version != 4 {
+		return fmt.Errorf("ID3v2.%d not supported", version)
+	}
+	flags := b[5]
+	size := int(syncsafe(b[6:10]))
+	b = b[10:]
+	if size < len(b) {
+		b = b[:size]
+	}
+	// unsynchronisation
+	if flags&0x80 != 0 {
+		b = bytes.Replace(b, []byte{0xFF, 0x00}, []byte{0xFF}, -1)
+	}
+	// extended header
+	if flags&0x40 != 0 && len(b) >= 4 {
+		extSize := int(binary.BigEndian.Uint32(b[:4])) + 4
+		if version == 4 {
+			extSize = int(syncsafe(b[:4]))
+		}
+		if extSize > len(b) {
+			return errors.New("invalid ID3 extended header")
+		}
+		b = b[extSize:]
+	}
+
+	if d.ID3 == nil {
+		d.ID3 = map[string]string{}
+	}
+	for len(b) >= 10 && b[0] != 0 {
+		id := string(b[:4])
+		frameSize := int(binary.BigEndian.Uint32(b[4:8]))
+		if version == 4 {
+			frameSize = int(syncsafe(b[4:8]))
+		}
+		b = b[10:]
+		if frameSize > len(b) {
+			return fmt.Errorf("ID3 frame %s is truncated", id)
+		}
+		data := b[:frameSize]
+		b = b[frameSize:]
+		if len(data) < 1 {
+			continue
+		}
+
+		switch {
+		case id == "TXXX":
+			desc, value := splitID3Text(data[0], data[1:])
+			d.ID3[id+":"+desc] = value
+		case id == "COMM":
+			if len(data) < 4 {
+				continue
+			}
+			// skip the language
+			desc, text := splitID3Text(data[0], data[4:])
+			// iTunes stores technical data (iTunSMPB, iTunNORM...) in
+			// comments, they are kept apart from the actual comment
+			if strings.HasPrefix(desc, "iTun") {
+				d.ID3[id+":"+desc] = text
+				continue
+			}
+			d.ID3[id] = text
+		case id[0] == 'T':
+			d.ID3[id] = decodeID3Text(data[0], data[1:])
+		}
+	}
+	return nil
+}
+
+// syncsafe decodes a 28-bit integer stored using 7 bits per byte.
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7F)<<21 | uint32(b[1]&0x7F)<<14 | uint32(b[2]&0x7F)<<7 | uint32(b[3]&0x7F)
+}
+
+// splitID3Text splits a description and a value separated by the null
+// terminator of the encoding.
+func splitID3Text(encoding byte, b []byte) (string, string) {
+	if encoding == 1 || encoding == 2 {
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				desc := decodeID3Text(encoding, b[:i])
+				// the value has its own BOM
+				return desc, decodeID3Text(encoding, b[i+2:])
+			}
+		}
+		return "", decodeID3Text(encoding, b)
+	}
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		return decodeID3Text(encoding, b[:i]), decodeID3Text(encoding, b[i+1:])
+	}
+	return "", decodeID3Text(encoding, b)
+}
+
+// decodeID3Text converts text stored in one of the ID3 encodings into a Go
+// string: 0 is ISO-8859-1, 1 UTF-16 with BOM, 2 UTF-16BE and 3 UTF-8.
+// Multiple values are separated by a slash.
+func decodeID3Text(encoding byte, b []byte) string {
+	var text string
+	switch encoding {
+	case 1, 2:
+		var byteOrder binary.ByteOrder = binary.BigEndian
+		if len(b) >= 2 && b[0] == 0xFF && b[1] == 0xFE {
+			byteOrder = binary.LittleEndian
+			b = b[2:]
+		} else if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+			b = b[2:]
+		}
+		u := make([]uint16, len(b)/2)
+		for i := range u {
+			u[i] = byteOrder.Uint16(b[i*2:])
+		}
+		text = string(utf16.Decode(u))
+	case 3:
+		text = strings.ToValidUTF8(string(b), string(utf8.RuneError))
+	default:
+		r := make([]rune, len(b))
+		for i, c := range b {
+			r[i] = rune(c)
+		}
+		text = string(r)
+	}
+	text = strings.TrimRight(text, "\x00")
+	return strings.Replace(text, "\x00", "/", -1)
+}
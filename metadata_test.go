@@ -0,0 +1,104 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// withChunks returns the content of the passed file with extra chunks
+// appended at the end.
+func withChunks(t *testing.T, path string, chunks ...[]byte) []byte {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range chunks {
+		data = append(data, c...)
+		if len(c)%2 != 0 {
+			data = append(data, 0)
+		}
+	}
+	binary.BigEndian.PutUint32(data[4:], uint32(len(data)-8))
+	return data
+}
+
+func testChunk(id string, data []byte) []byte {
+	c := make([]byte, 8, 8+len(data))
+	copy(c, id)
+	binary.BigEndian.PutUint32(c[4:], uint32(len(data)))
+	return append(c, data...)
+}
+
+func id3Frame(id string, data []byte) []byte {
+	f := make([]byte, 10, 10+len(data))
+	copy(f, id)
+	binary.BigEndian.PutUint32(f[4:], uint32(len(data)))
+	return append(f, data...)
+}
+
+func TestDecoder_Metadata(t *testing.T) {
+	f, err := os.Open("fixtures/sowt2.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Copyright != "(c) 2009 mutekki-media.de" {
+		t.Fatalf("unexpected copyright: %q", d.Copyright)
+	}
+	if m := d.Metadata(); m[MetadataCopyright] != d.Copyright {
+		t.Fatalf("expected the copyright to be mapped, got %v", m)
+	}
+
+	var frames []byte
+	frames = append(frames, id3Frame("TIT2", []byte("\x03Título"))...)
+	// UTF-16 with BOM
+	frames = append(frames, id3Frame("TPE1", []byte("\x01\xff\xfeA\x00r\x00t\x00"))...)
+	frames = append(frames, id3Frame("TALB", []byte("\x00Alb\xfcm"))...)
+	frames = append(frames, id3Frame("COMM", []byte("\x00engdesc\x00A comment"))...)
+	frames = append(frames, id3Frame("TXXX", []byte("\x00BPM\x00120"))...)
+	tag := append([]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, byte(len(frames))}, frames...)
+
+	data := withChunks(t, "fixtures/kick.aif",
+		testChunk("NAME", []byte("Kick")),
+		testChunk("AUTH", []byte("Someone\x00")),
+		testChunk("ANNO", []byte("first")),
+		testChunk("ANNO", []byte("second")),
+		testChunk("ID3 ", tag),
+	)
+	d = NewDecoder(bytes.NewReader(data))
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Name != "Kick" || d.Author != "Someone" {
+		t.Fatalf("unexpected name/author: %q / %q", d.Name, d.Author)
+	}
+	if len(d.Annotations) != 2 || d.Annotations[1] != "second" {
+		t.Fatalf("unexpected annotations: %v", d.Annotations)
+	}
+	if d.ID3["TXXX:BPM"] != "120" {
+		t.Fatalf("unexpected user defined frame: %v", d.ID3)
+	}
+
+	expected := map[string]string{
+		MetadataTitle:   "Título",
+		MetadataArtist:  "Art",
+		MetadataAlbum:   "Albüm",
+		MetadataComment: "A comment",
+	}
+	m := d.Metadata()
+	if len(m) != len(expected) {
+		t.Fatalf("expected %v but got %v", expected, m)
+	}
+	for k, v := range expected {
+		if m[k] != v {
+			t.Fatalf("expected %s to be %q but got %q", k, v, m[k])
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package aiff
+
+import (
+	"io"
+	"time"
+)
+
+// Metrics receives the events of a decoder so they can be exported to a
+// monitoring system (Prometheus counters, histograms...). The methods are
+// called synchronously from the decoder and must be cheap. See MetricsFuncs
+// to only handle some of the events.
+type Metrics interface {
+	// ChunkParsed is called for each chunk processed by the decoder.
+	ChunkParsed(id [4]byte, size int)
+	// BytesDecoded is called with the number of bytes of sound data read.
+	BytesDecoded(n int)
+	// DecodeDuration is called with the time spent in each call decoding
+	// samples (PCMBuffer, FullPCMBuffer).
+	DecodeDuration(d time.Duration)
+	// UnsupportedEncoding is called when the file uses an AIFC encoding
+	// the decoder doesn't know how to decode.
+	UnsupportedEncoding(enc Encoding)
+}
+
+// MetricsFuncs implements Metrics using optional functions, the events
+// without a function are ignored.
+type MetricsFuncs struct {
+	OnChunkParsed         func(id [4]byte, size int)
+	OnBytesDecoded        func(n int)
+	OnDecodeDuration      func(d time.Duration)
+	OnUnsupportedEncoding func(enc Encoding)
+}
+
+// ChunkParsed implements Metrics.
+func (m MetricsFuncs) ChunkParsed(id [4]byte, size int) {
+	if m.OnChunkParsed != nil {
+		m.OnChunkParsed(id, size)
+	}
+}
+
+// BytesDecoded implements Metrics.
+func (m MetricsFuncs) BytesDecoded(n int) {
+	if m.OnBytesDecoded != nil {
+		m.OnBytesDecoded(n)
+	}
+}
+
+// DecodeDuration implements Metrics.
+func (m MetricsFuncs) DecodeDuration(d time.Duration) {
+	if m.OnDecodeDuration != nil {
+		m.OnDecodeDuration(d)
+	}
+}
+
+// UnsupportedEncoding implements Metrics.
+func (m MetricsFuncs) UnsupportedEncoding(enc Encoding) {
+	if m.OnUnsupportedEncoding != nil {
+		m.OnUnsupportedEncoding(enc)
+	}
+}
+
+// chunkParsed reports a processed chunk to the metrics if set.
+func (d *Decoder) chunkParsed(chunk *Chunk) {
+	if d.Metrics != nil && chunk != nil {
+		d.Metrics.ChunkParsed(chunk.ID, chunk.Size)
+	}
+}
+
+// decodeTimer returns a function reporting the time elapsed since the call
+// to the metrics if set.
+func (d *Decoder) decodeTimer() func() {
+	if d == nil || d.Metrics == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		d.Metrics.DecodeDuration(time.Since(start))
+	}
+}
+
+// meteredReader reports the bytes read from the sound data.
+type meteredReader struct {
+	r io.Reader
+	m Metrics
+}
+
+func (r *meteredReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.m.BytesDecoded(n)
+	}
+	return n, err
+}
+
+// meterPCM makes the reads of the sound data reported to the metrics if set.
+func (d *Decoder) meterPCM(chunk *Chunk) {
+	if d.Metrics != nil && chunk != nil && chunk.R != nil {
+		chunk.R = &meteredReader{r: chunk.R, m: d.Metrics}
+	}
+}
@@ -0,0 +1,75 @@
+package aiff
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDecoder_Metrics(t *testing.T) {
+	f, err := os.Open("fixtures/sowt2.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var (
+		chunks    []string
+		decoded   int
+		durations int
+	)
+	d := NewDecoder(f)
+	d.Metrics = MetricsFuncs{
+		OnChunkParsed:  func(id [4]byte, size int) { chunks = append(chunks, string(id[:])) },
+		OnBytesDecoded: func(n int) { decoded += n },
+		OnDecodeDuration: func(d time.Duration) {
+			if d < 0 {
+				t.Errorf("invalid duration %v", d)
+			}
+			durations++
+		},
+	}
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"COMM", "(c) ", "COMT", "AFmd", "SSND", "AFAn"}
+	if len(chunks) != len(expected) {
+		t.Fatalf("expected the chunks %q but got %q", expected, chunks)
+	}
+	for i, id := range expected {
+		if chunks[i] != id {
+			t.Fatalf("expected the chunks %q but got %q", expected, chunks)
+		}
+	}
+	if decoded != len(buf.Data)*2 {
+		t.Fatalf("expected %d bytes to be decoded but got %d", len(buf.Data)*2, decoded)
+	}
+	if durations != 1 {
+		t.Fatalf("expected a single decode duration but got %d", durations)
+	}
+}
+
+func TestDecoder_MetricsUnsupportedEncoding(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/sowt.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := bytes.Index(data, []byte("sowt"))
+	copy(data[i:], "GSM ")
+
+	var unsupported []Encoding
+	d := NewDecoder(bytes.NewReader(data))
+	d.Metrics = MetricsFuncs{
+		OnUnsupportedEncoding: func(enc Encoding) { unsupported = append(unsupported, enc) },
+	}
+	d.ReadInfo()
+	if len(unsupported) != 1 || unsupported[0] != EncGsm {
+		t.Fatalf("expected the GSM encoding to be reported but got %q", unsupported)
+	}
+}
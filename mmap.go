@@ -0,0 +1,156 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/go-audio/audio"
+)
+
+// MmapDecoder is a decoder backed by a memory mapped file.
+// The PCM data is accessed directly from memory without any read syscalls
+// which makes random access (by frame index) cheap.
+// The parsed information is available via the embedded Decoder.
+type MmapDecoder struct {
+	*Decoder
+
+	f          *os.File
+	data       []byte
+	pcm        []byte
+	bPerSample int
+	frameSize  int
+}
+
+// OpenMmap memory maps the file at the given path and parses its headers.
+// Don't forget to call Close when done.
+// An error is returned on platforms not supporting memory mapped files.
+func OpenMmap(path string) (*MmapDecoder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() <= 0 {
+		f.Close()
+		return nil, fmt.Errorf("%s - empty file", ErrFmtNotSupported)
+	}
+	data, err := mmap(f, int(info.Size()))
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to memory map %s - %v", path, err)
+	}
+	m := &MmapDecoder{Decoder: NewDecoder(bytes.NewReader(data)), f: f, data: data}
+	if err := m.init(); err != nil {
+		m.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *MmapDecoder) init() error {
+	start, length, err := m.PCMOffset()
+	if err != nil {
+		return err
+	}
+	if _, ok := pcmByteOrder(m.Encoding); !ok {
+		return fmt.Errorf("%s - %q encoding", ErrFmtNotSupported, m.Encoding)
+	}
+	m.bPerSample = bytesPerSample(int(m.BitDepth))
+	m.frameSize = m.bPerSample * int(m.NumChans)
+	if m.frameSize < 1 {
+		return fmt.Errorf("%s - %d channels @ %d bits", ErrFmtNotSupported, m.NumChans, m.BitDepth)
+	}
+	if end := start + length; end > int64(len(m.data)) {
+		length = int64(len(m.data)) - start
+	}
+	length -= length % int64(m.frameSize)
+	m.pcm = m.data[start : start+length]
+	return nil
+}
+
+// NumFrames returns the number of frames available in the PCM data.
+func (m *MmapDecoder) NumFrames() int {
+	if m == nil || m.frameSize < 1 {
+		return 0
+	}
+	return len(m.pcm) / m.frameSize
+}
+
+// Sample returns the value of the sample of the given channel at the given
+// frame index.
+func (m *MmapDecoder) Sample(frame, channel int) (int, error) {
+	if m == nil || m.pcm == nil {
+		return 0, errors.New("can't access the samples of a closed decoder")
+	}
+	if frame < 0 || frame >= m.NumFrames() {
+		return 0, fmt.Errorf("frame %d out of range [0, %d)", frame, m.NumFrames())
+	}
+	if channel < 0 || channel >= int(m.NumChans) {
+		return 0, fmt.Errorf("channel %d out of range [0, %d)", channel, m.NumChans)
+	}
+	pos := frame*m.frameSize + channel*m.bPerSample
+	return decodeSample(m.pcm[pos:pos+m.bPerSample], m.byteOrder), nil
+}
+
+// Frames populates buf with the samples starting at the given frame index and
+// returns the number of samples copied.
+func (m *MmapDecoder) Frames(start int, buf *audio.IntBuffer) (int, error) {
+	if buf == nil {
+		return 0, nil
+	}
+	if m == nil || m.pcm == nil {
+		return 0, errors.New("can't access the samples of a closed decoder")
+	}
+	if start < 0 || start > m.NumFrames() {
+		return 0, fmt.Errorf("frame %d out of range [0, %d]", start, m.NumFrames())
+	}
+	buf.Format = m.Format()
+	buf.SourceBitDepth = int(m.BitDepth)
+	pos := start * m.frameSize
+	var n int
+	for ; n < len(buf.Data) && pos+m.bPerSample <= len(m.pcm); n++ {
+		buf.Data[n] = decodeSample(m.pcm[pos:pos+m.bPerSample], m.byteOrder)
+		pos += m.bPerSample
+	}
+	return n, nil
+}
+
+// Close unmaps the file and closes it.
+func (m *MmapDecoder) Close() error {
+	if m == nil || m.data == nil {
+		return nil
+	}
+	err := munmap(m.data)
+	m.data = nil
+	m.pcm = nil
+	if cerr := m.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// decodeSample decodes a single sample stored in b (1 to 4 bytes).
+func decodeSample(b []byte, byteOrder binary.ByteOrder) int {
+	switch len(b) {
+	case 1:
+		// 8bit values are unsigned
+		return int(b[0])
+	case 2:
+		return int(int16(byteOrder.Uint16(b)))
+	case 3:
+		if byteOrder == binary.BigEndian {
+			return int(audio.Int24BETo32(b))
+		}
+		return int(audio.Int24LETo32(b))
+	case 4:
+		return int(int32(byteOrder.Uint32(b)))
+	}
+	return 0
+}
@@ -0,0 +1,19 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package aiff
+
+import (
+	"errors"
+	"os"
+)
+
+var errMmapNotSupported = errors.New("memory mapped files aren't supported on this platform")
+
+func mmap(f *os.File, size int) ([]byte, error) {
+	return nil, errMmapNotSupported
+}
+
+func munmap(data []byte) error {
+	return errMmapNotSupported
+}
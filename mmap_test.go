@@ -0,0 +1,71 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package aiff
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestOpenMmap(t *testing.T) {
+	testCases := []string{
+		"fixtures/kick.aif",
+		"fixtures/sowt.aif",
+		"fixtures/zipper24b.aiff",
+	}
+
+	for _, path := range testCases {
+		t.Run(path, func(t *testing.T) {
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			expected, err := NewDecoder(f).FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m, err := OpenMmap(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer m.Close()
+			if m.NumFrames() != expected.NumFrames() {
+				t.Fatalf("expected %d frames but got %d", expected.NumFrames(), m.NumFrames())
+			}
+			numChans := int(m.NumChans)
+			// access the samples backwards to exercise random access
+			for i := len(expected.Data) - 1; i >= 0; i-- {
+				v, err := m.Sample(i/numChans, i%numChans)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if v != expected.Data[i] {
+					t.Fatalf("sample %d didn't match, expected %d, got %d", i, expected.Data[i], v)
+				}
+			}
+			if _, err := m.Sample(m.NumFrames(), 0); err == nil {
+				t.Fatal("expected an error when accessing a frame out of range")
+			}
+
+			buf := &audio.IntBuffer{Data: make([]int, 64)}
+			start := m.NumFrames() / 2
+			n, err := m.Frames(start, buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(buf.Data) {
+				t.Fatalf("expected to read %d samples but got %d", len(buf.Data), n)
+			}
+			for i, v := range buf.Data {
+				if exp := expected.Data[start*numChans+i]; v != exp {
+					t.Fatalf("sample %d didn't match, expected %d, got %d", i, exp, v)
+				}
+			}
+		})
+	}
+}
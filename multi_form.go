@@ -0,0 +1,61 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MultiDecoder iterates over the FORM containers of a stream made of
+// several concatenated AIFF/AIFC files, as written by some capture tools.
+//
+//	m := NewMultiDecoder(r)
+//	for {
+//		d, err := m.NextForm()
+//		if err == io.EOF {
+//			break
+//		}
+//		if err != nil {
+//			// handle the error
+//		}
+//		// use the decoder
+//	}
+type MultiDecoder struct {
+	ra io.ReaderAt
+	// offset of the next FORM header
+	offset int64
+}
+
+// NewMultiDecoder creates a decoder iterating over the FORM containers of
+// the given reader.
+func NewMultiDecoder(r io.ReadSeeker) *MultiDecoder {
+	return &MultiDecoder{ra: newReaderAt(r)}
+}
+
+// NextForm returns a decoder limited to the next FORM container of the
+// stream. io.EOF is returned when no more containers are available.
+// Decoders returned by previous calls remain usable.
+func (m *MultiDecoder) NextForm() (*Decoder, error) {
+	if m == nil || m.ra == nil {
+		return nil, errors.New("can't iterate over the containers of a nil decoder")
+	}
+	var header [12]byte
+	n, err := m.ra.ReadAt(header[:], m.offset)
+	if n == 0 && (err == io.EOF || err == nil) {
+		return nil, io.EOF
+	}
+	if n < len(header) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("failed to read the container header at offset %d - %v", m.offset, err)
+	}
+	if string(header[:4]) != string(formID[:]) {
+		return nil, fmt.Errorf("%s - expected a FORM header at offset %d but got %q", ErrUnexpectedData, m.offset, header[:4])
+	}
+	size := int64(binary.BigEndian.Uint32(header[4:8]))
+	d := NewDecoder(io.NewSectionReader(m.ra, m.offset, 8+size))
+	m.offset += 8 + size + size%2
+	return d, nil
+}
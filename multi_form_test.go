@@ -0,0 +1,64 @@
+package aiff
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestMultiDecoder(t *testing.T) {
+	inputs := []string{"fixtures/kick.aif", "fixtures/sowt.aif", "fixtures/zipper24b.aiff"}
+	var stream []byte
+	for _, path := range inputs {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream = append(stream, data...)
+	}
+
+	m := NewMultiDecoder(bytes.NewReader(stream))
+	for i, path := range inputs {
+		d, err := m.NextForm()
+		if err != nil {
+			t.Fatalf("form %d: %v", i, err)
+		}
+		buf, err := d.FullPCMBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := NewDecoder(f).FullPCMBuffer()
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(buf.Data) != len(expected.Data) {
+			t.Fatalf("form %d: expected %d samples but got %d", i, len(expected.Data), len(buf.Data))
+		}
+		for j, v := range expected.Data {
+			if buf.Data[j] != v {
+				t.Fatalf("form %d: sample %d didn't match, expected %d, got %d", i, j, v, buf.Data[j])
+			}
+		}
+	}
+	if _, err := m.NextForm(); err != io.EOF {
+		t.Fatalf("expected io.EOF after the last container but got %v", err)
+	}
+
+	m = NewMultiDecoder(bytes.NewReader(append(stream, "garbage!!!!!"...)))
+	for i := range inputs {
+		if _, err := m.NextForm(); err != nil {
+			t.Fatalf("form %d: %v", i, err)
+		}
+	}
+	if _, err := m.NextForm(); err == nil || err == io.EOF {
+		t.Fatalf("expected an error reading trailing garbage but got %v", err)
+	}
+}
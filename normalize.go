@@ -0,0 +1,113 @@
+package aiff
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-audio/audio"
+)
+
+// Normalize applies a gain to the PCM data of in so its peak reaches
+// targetPeak (a ratio of the full scale, between 0 and 1) and writes the
+// result to out as an AIFF file. The input is read twice: once to find its
+// peak and once to apply the gain. Samples are clipped to the range allowed
+// by the bit depth.
+// Note that the underlying writer is NOT being closed.
+func Normalize(in io.ReadSeeker, out io.WriteSeeker, targetPeak float64) error {
+	if targetPeak <= 0 || targetPeak > 1 {
+		return fmt.Errorf("invalid target peak: %v, should be within (0, 1]", targetPeak)
+	}
+
+	// first pass, find the peak
+	d := NewDecoder(in)
+	peak, err := d.absPeak()
+	if err != nil {
+		return fmt.Errorf("failed to scan the peak - %v", err)
+	}
+	bitDepth := int(d.BitDepth)
+	max := 1<<uint(bitDepth-1) - 1
+	gain := 1.0
+	if peak > 0 {
+		gain = targetPeak * float64(max) / float64(peak)
+	}
+
+	// second pass, apply the gain
+	if _, err := in.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	d = NewDecoder(in)
+	d.ReadInfo()
+	if err := d.Err(); err != nil {
+		return err
+	}
+	e := NewEncoder(out, d.SampleRate, bitDepth, int(d.NumChans))
+	e.WithSampleTransform(func(frame []int) {
+		for i, v := range frame {
+			v = int(float64(signedSample(v, bitDepth)) * gain)
+			if v > max {
+				v = max
+			} else if v < -max-1 {
+				v = -max - 1
+			}
+			frame[i] = v
+		}
+	})
+	buf := &audio.IntBuffer{Format: d.Format(), Data: make([]int, 4096*int(d.NumChans))}
+	for {
+		n, err := d.PCMBuffer(buf)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+		if err := e.Write(&audio.IntBuffer{Format: buf.Format, Data: buf.Data[:n]}); err != nil {
+			return err
+		}
+	}
+	return e.Close()
+}
+
+// absPeak streams the PCM data and returns the highest absolute value of its
+// signed samples. Unlike Peaks, 8 bit samples are converted before being
+// compared.
+func (d *Decoder) absPeak() (int, error) {
+	if !d.WasPCMAccessed() {
+		if err := d.FwdToPCM(); err != nil {
+			return 0, fmt.Errorf("failed to forward to PCM: %v", err)
+		}
+	}
+	if d.PCMChunk == nil {
+		return 0, fmt.Errorf("PCM chunk not found - %v", d.Err())
+	}
+	bitDepth := int(d.BitDepth)
+	buf := &audio.IntBuffer{Data: make([]int, 4096*int(d.NumChans))}
+	var peak int
+	for {
+		n, err := d.PCMBuffer(buf)
+		if err != nil {
+			return 0, err
+		}
+		if n == 0 {
+			return peak, nil
+		}
+		for _, v := range buf.Data[:n] {
+			v = signedSample(v, bitDepth)
+			if v < 0 {
+				v = -v
+			}
+			if v > peak {
+				peak = v
+			}
+		}
+	}
+}
+
+// signedSample converts 8 bit samples (decoded as unsigned bytes) to their
+// signed values, other bit depths are returned as is.
+func signedSample(v int, bitDepth int) int {
+	if bitDepth == 8 {
+		return int(int8(v))
+	}
+	return v
+}
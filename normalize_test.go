@@ -0,0 +1,109 @@
+package aiff
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestNormalize(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	testCases := []struct {
+		input      string
+		targetPeak float64
+		peak       int
+	}{
+		{"fixtures/kick.aif", 1, 32767},
+		{"fixtures/bloop.aif", 0.5, 16383},
+		{"fixtures/zipper24b.aiff", 0.25, 2097151},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			in, err := os.Open(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer in.Close()
+			out, err := os.Create("testOutput/normalized.aif")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(out.Name())
+			defer out.Close()
+
+			if err := Normalize(in, out, tc.targetPeak); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			buf, err := NewDecoder(out).FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var peak int
+			for _, v := range buf.Data {
+				if v < 0 {
+					v = -v
+				}
+				if v > peak {
+					peak = v
+				}
+			}
+			// allow for rounding errors
+			if diff := peak - tc.peak; diff > 1 || diff < -1 {
+				t.Fatalf("expected a peak of %d but got %d", tc.peak, peak)
+			}
+		})
+	}
+
+	if err := Normalize(nil, nil, 2); err == nil {
+		t.Fatal("expected an invalid target peak to be rejected")
+	}
+}
+
+func TestNormalize_8bit(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	in, err := os.Create("testOutput/normalize_8bit_in.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(in.Name())
+	defer in.Close()
+	e := NewEncoder(in, 44100, 8, 1)
+	if err := e.Write(&audio.IntBuffer{Data: []int{50, -1, -100}, Format: &audio.Format{NumChannels: 1, SampleRate: 44100}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := os.Create("testOutput/normalize_8bit_out.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	if err := Normalize(in, out, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := NewDecoder(out).FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the peak is -100, not the 255 unsigned byte of -1
+	expected := []int{63, -1, -127}
+	for i, v := range buf.Data {
+		if v = signedSample(v, 8); v != expected[i] {
+			t.Fatalf("expected %v but got sample %d at position %d", expected, v, i)
+		}
+	}
+}
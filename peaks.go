@@ -0,0 +1,70 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-audio/audio"
+)
+
+// Peaks streams the PCM data and returns the min/max sample values found in
+// each bucket of samplesPerPixel frames. All channels are folded into the
+// same bucket. The last bucket might cover less frames than the others.
+// This is useful to draw waveform overviews without holding the entire PCM
+// data in memory.
+func (d *Decoder) Peaks(samplesPerPixel int) ([][2]int, error) {
+	if d == nil {
+		return nil, errors.New("can't compute the peaks of a nil pointer")
+	}
+	if samplesPerPixel < 1 {
+		return nil, fmt.Errorf("invalid number of samples per pixel: %d", samplesPerPixel)
+	}
+	if !d.WasPCMAccessed() {
+		if err := d.FwdToPCM(); err != nil {
+			return nil, fmt.Errorf("failed to forward to PCM: %v", err)
+		}
+	}
+	if d.PCMChunk == nil {
+		return nil, fmt.Errorf("PCM chunk not found - %v", d.Err())
+	}
+	numChans := int(d.NumChans)
+	if numChans < 1 {
+		return nil, fmt.Errorf("invalid number of channels: %d", numChans)
+	}
+
+	bucketSize := samplesPerPixel * numChans
+	buf := &audio.IntBuffer{Data: make([]int, 4096*numChans)}
+	peaks := [][2]int{}
+	var (
+		peak  [2]int
+		count int
+	)
+	for {
+		n, err := d.PCMBuffer(buf)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			break
+		}
+		for _, v := range buf.Data[:n] {
+			if count == 0 {
+				peak = [2]int{v, v}
+			} else if v < peak[0] {
+				peak[0] = v
+			} else if v > peak[1] {
+				peak[1] = v
+			}
+			count++
+			if count == bucketSize {
+				peaks = append(peaks, peak)
+				count = 0
+			}
+		}
+	}
+	if count > 0 {
+		peaks = append(peaks, peak)
+	}
+
+	return peaks, nil
+}
@@ -0,0 +1,70 @@
+package aiff
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDecoder_Peaks(t *testing.T) {
+	testCases := []struct {
+		input           string
+		samplesPerPixel int
+		numPeaks        int
+	}{
+		{"fixtures/kick.aif", 1000, 5},
+		{"fixtures/bloop.aif", 512, 0},
+		{"fixtures/zipper24b.aiff", 2048, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			f, err := os.Open(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			d := NewDecoder(f)
+			buf, err := d.FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			peaks, err := NewDecoder(f).Peaks(tc.samplesPerPixel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bucketSize := tc.samplesPerPixel * buf.Format.NumChannels
+			expectedPeaks := (len(buf.Data) + bucketSize - 1) / bucketSize
+			if tc.numPeaks > 0 && expectedPeaks != tc.numPeaks {
+				t.Fatalf("expected %d peaks to be computed but the data covers %d", tc.numPeaks, expectedPeaks)
+			}
+			if len(peaks) != expectedPeaks {
+				t.Fatalf("expected %d peaks but got %d", expectedPeaks, len(peaks))
+			}
+			for i, peak := range peaks {
+				end := (i + 1) * bucketSize
+				if end > len(buf.Data) {
+					end = len(buf.Data)
+				}
+				min, max := buf.Data[i*bucketSize], buf.Data[i*bucketSize]
+				for _, v := range buf.Data[i*bucketSize : end] {
+					if v < min {
+						min = v
+					}
+					if v > max {
+						max = v
+					}
+				}
+				if peak[0] != min || peak[1] != max {
+					t.Fatalf("expected peak %d to be [%d %d] but got %v", i, min, max, peak)
+				}
+			}
+		})
+	}
+
+	if _, err := (&Decoder{}).Peaks(0); err == nil {
+		t.Fatal("expected an error when passing an invalid number of samples per pixel")
+	}
+}
@@ -0,0 +1,71 @@
+package aiff
+
+import (
+	"errors"
+	"io"
+)
+
+// Pascal style strings (pstring) are made of a count byte followed by the
+// text. A pad byte is added when needed so the total size is even. They are
+// used by the COMM (compression name) and MARK (marker names) chunks.
+
+// pstringSize returns the number of bytes used by a pascal style string of
+// the given length: count byte + text + pad byte to keep an even size.
+func pstringSize(n int) int {
+	size := 1 + n
+	if size%2 != 0 {
+		size++
+	}
+	return size
+}
+
+// pstring converts the passed string into a pascal style string.
+// Strings are truncated to 255 bytes.
+func pstring(str string) []byte {
+	if len(str) > 255 {
+		str = str[:255]
+	}
+	b := make([]byte, pstringSize(len(str)))
+	b[0] = byte(len(str))
+	copy(b[1:], str)
+	return b
+}
+
+// readPstring reads a pascal style string, including its pad byte, and
+// returns the text and the number of bytes read.
+func readPstring(r io.Reader) (string, int, error) {
+	var count [1]byte
+	if _, err := io.ReadFull(r, count[:]); err != nil {
+		return "", 0, err
+	}
+	b := make([]byte, pstringSize(int(count[0]))-1)
+	n, err := io.ReadFull(r, b)
+	if err == io.ErrUnexpectedEOF && n >= int(count[0]) {
+		// the pad byte of the last string might be missing
+		err = nil
+	}
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", 1 + n, err
+	}
+	return string(b[:count[0]]), 1 + n, nil
+}
+
+// parsePstring parses the pascal style string at the start of b and returns
+// the text and the number of bytes used, pad byte included.
+func parsePstring(b []byte) (string, int, error) {
+	if len(b) < 1 {
+		return "", 0, errors.New("missing pstring count")
+	}
+	n := int(b[0])
+	if 1+n > len(b) {
+		return "", 0, errors.New("pstring goes past the end of the data")
+	}
+	size := pstringSize(n)
+	if size > len(b) {
+		size = len(b)
+	}
+	return string(b[1 : 1+n]), size, nil
+}
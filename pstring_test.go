@@ -0,0 +1,66 @@
+package aiff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPstring(t *testing.T) {
+	testCases := []struct {
+		str     string
+		encoded []byte
+	}{
+		{"", []byte{0, 0}},
+		{"a", []byte{1, 'a'}},
+		{"ab", []byte{2, 'a', 'b', 0}},
+		{"not compressed", append([]byte{14}, "not compressed\x00"...)},
+		{strings.Repeat("x", 255), append([]byte{255}, strings.Repeat("x", 255)...)},
+	}
+	for _, tc := range testCases {
+		b := pstring(tc.str)
+		if !bytes.Equal(b, tc.encoded) {
+			t.Fatalf("expected %q to be encoded as %v but got %v", tc.str, tc.encoded, b)
+		}
+		if len(b) != pstringSize(len(tc.str)) {
+			t.Fatalf("expected the size of %q to be %d but got %d", tc.str, len(b), pstringSize(len(tc.str)))
+		}
+
+		// followed by other data
+		r := bytes.NewReader(append(b, 0xFF))
+		str, n, err := readPstring(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if str != tc.str || n != len(b) {
+			t.Fatalf("expected to read %q (%d bytes) but got %q (%d bytes)", tc.str, len(b), str, n)
+		}
+		if next, _ := r.ReadByte(); next != 0xFF {
+			t.Fatalf("expected the pad byte of %q to be consumed", tc.str)
+		}
+		str, n, err = parsePstring(append(b, 0xFF))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if str != tc.str || n != len(b) {
+			t.Fatalf("expected to parse %q (%d bytes) but got %q (%d bytes)", tc.str, len(b), str, n)
+		}
+	}
+
+	if str := pstring(strings.Repeat("x", 300)); len(str) != 256 || str[0] != 255 {
+		t.Fatal("expected long strings to be truncated to 255 bytes")
+	}
+	// missing pad byte at the end of the data
+	if str, n, err := readPstring(bytes.NewReader([]byte{2, 'a', 'b'})); err != nil || str != "ab" || n != 3 {
+		t.Fatalf("expected a missing final pad byte to be tolerated, got %q %d %v", str, n, err)
+	}
+	if str, n, err := parsePstring([]byte{2, 'a', 'b'}); err != nil || str != "ab" || n != 3 {
+		t.Fatalf("expected a missing final pad byte to be tolerated, got %q %d %v", str, n, err)
+	}
+	if _, _, err := readPstring(bytes.NewReader([]byte{5, 'a'})); err == nil {
+		t.Fatal("expected an error reading a truncated pstring")
+	}
+	if _, _, err := parsePstring([]byte{5, 'a'}); err == nil {
+		t.Fatal("expected an error parsing a truncated pstring")
+	}
+}
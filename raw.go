@@ -0,0 +1,64 @@
+package aiff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// rawCodec implements the AIFC 'raw ' encoding used by old Sound Designer
+// and QuickTime transfers: 8-bit samples stored as offset binary (0x80 is
+// silence) instead of two's complement.
+// Like the other 8-bit files, the samples are decoded as the unsigned byte
+// of their two's complement value, see PCMBuffer.
+type rawCodec struct{}
+
+func init() {
+	RegisterCodec(EncRaw, rawCodec{})
+}
+
+func (rawCodec) NewSampleDecoder(r io.Reader, numChans, bitDepth int) (SampleDecoder, error) {
+	if bitDepth != 8 {
+		return nil, fmt.Errorf("%s - 'raw ' samples are 8-bit, not %d-bit", ErrFmtNotSupported, bitDepth)
+	}
+	return &rawDecoder{r: bufio.NewReader(r)}, nil
+}
+
+func (rawCodec) NewSampleEncoder(w io.Writer, numChans, bitDepth int) (SampleEncoder, error) {
+	if bitDepth != 8 {
+		return nil, fmt.Errorf("%s - 'raw ' samples are 8-bit, not %d-bit", ErrFmtNotSupported, bitDepth)
+	}
+	return &rawEncoder{w: bufio.NewWriter(w)}, nil
+}
+
+type rawDecoder struct {
+	r *bufio.Reader
+}
+
+func (d *rawDecoder) DecodeSamples(buf []int) (int, error) {
+	for i := range buf {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return i, err
+		}
+		buf[i] = int(b ^ 0x80)
+	}
+	return len(buf), nil
+}
+
+type rawEncoder struct {
+	w *bufio.Writer
+}
+
+func (e *rawEncoder) EncodeSamples(samples []int) error {
+	for _, v := range samples {
+		if err := e.w.WriteByte(byte(v) ^ 0x80); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *rawEncoder) Flush() error {
+	return e.w.Flush()
+}
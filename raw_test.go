@@ -0,0 +1,71 @@
+package aiff
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func TestRawEncoding(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/raw.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	samples := []int{0, 1, -1, 127, -128}
+	e := NewEncoder(out, 22050, 8, 1)
+	e.Encoding = EncRaw
+	if err := e.Write(&audio.IntBuffer{Data: samples, Format: &audio.Format{NumChannels: 1, SampleRate: 22050}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(out)
+	start, length, err := d.PCMOffset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := make([]byte, length)
+	if _, err := out.ReadAt(raw, start); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []byte{0x80, 0x81, 0x7f, 0xff, 0x00}; !bytes.Equal(raw, expected) {
+		t.Fatalf("expected the samples to be stored as offset binary %x but got %x", expected, raw)
+	}
+
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d = NewDecoder(out)
+	if !d.IsValidFile() {
+		t.Fatalf("expected the file to be valid - %v", d.Err())
+	}
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf.Data) != len(samples) {
+		t.Fatalf("expected %d samples but got %d", len(samples), len(buf.Data))
+	}
+	for i, v := range samples {
+		if got := signedSample(buf.Data[i], 8); got != v {
+			t.Fatalf("expected sample %d to be %d but got %d", i, v, got)
+		}
+	}
+
+	e = NewEncoder(out, 22050, 16, 1)
+	e.Encoding = EncRaw
+	if err := e.Write(&audio.IntBuffer{Data: samples, Format: &audio.Format{NumChannels: 1, SampleRate: 22050}}); err == nil {
+		t.Fatal("expected an error encoding 16-bit 'raw ' samples")
+	}
+}
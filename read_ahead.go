@@ -0,0 +1,152 @@
+package aiff
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// WithReadAhead makes the decoder read its source by blocks of at least n
+// bytes kept in a cache. Probing a file means a lot of tiny reads (chunk
+// headers, COMM chunk...) which is painfully slow when the source is a
+// network-backed reader (HTTP range requests, cloud storage...), with a
+// read-ahead most of them are served from memory. Large reads, such as the
+// decoding of the sound data, bypass the cache.
+// It must be called before anything is read, the setting is kept when the
+// decoder is reset.
+func (d *Decoder) WithReadAhead(n int) *Decoder {
+	if d == nil || n < 1 {
+		return d
+	}
+	if r, ok := d.r.(*readAheadReader); ok {
+		r.mu.Lock()
+		r.size = n
+		r.mu.Unlock()
+		return d
+	}
+	r := &readAheadReader{src: d.r, size: n, srcPos: -1}
+	r.srcAt, _ = d.r.(io.ReaderAt)
+	if pos, err := d.r.Seek(0, io.SeekCurrent); err == nil {
+		r.pos, r.srcPos = pos, pos
+	}
+	d.r, d.ra = r, r
+	return d
+}
+
+// readAheadReader caches a window of its source, it implements io.Reader,
+// io.Seeker and io.ReaderAt.
+type readAheadReader struct {
+	mu    sync.Mutex
+	src   io.ReadSeeker
+	srcAt io.ReaderAt
+	// size of the cached window
+	size int
+	// position of the reader
+	pos int64
+	// position of the source, -1 if unknown
+	srcPos int64
+	// cached data and its offset in the source
+	buf    []byte
+	bufOff int64
+}
+
+func (r *readAheadReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, err := r.readAt(p, r.pos)
+	r.pos += int64(n)
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+func (r *readAheadReader) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var read int
+	for read < len(p) {
+		n, err := r.readAt(p[read:], off+int64(read))
+		read += n
+		if err != nil {
+			return read, err
+		}
+		if n == 0 {
+			return read, io.EOF
+		}
+	}
+	return read, nil
+}
+
+func (r *readAheadReader) Seek(offset int64, whence int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var pos int64
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = r.pos + offset
+	case io.SeekEnd:
+		end, err := r.src.Seek(offset, io.SeekEnd)
+		r.srcPos = end
+		if err != nil {
+			r.srcPos = -1
+			return r.pos, err
+		}
+		pos = end
+	default:
+		return r.pos, errors.New("invalid whence")
+	}
+	if pos < 0 {
+		return r.pos, errors.New("negative position")
+	}
+	r.pos = pos
+	return pos, nil
+}
+
+// readAt reads up to len(p) bytes at the passed offset, from the cache if
+// possible.
+func (r *readAheadReader) readAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if off < r.bufOff || off >= r.bufOff+int64(len(r.buf)) {
+		if len(p) >= r.size {
+			return r.fetch(p, off)
+		}
+		if cap(r.buf) < r.size {
+			r.buf = make([]byte, r.size)
+		}
+		n, err := r.fetch(r.buf[:r.size], off)
+		r.buf, r.bufOff = r.buf[:n], off
+		if n == 0 {
+			return 0, err
+		}
+	}
+	return copy(p, r.buf[off-r.bufOff:]), nil
+}
+
+// fetch reads as many bytes as possible at the passed offset from the
+// source.
+func (r *readAheadReader) fetch(p []byte, off int64) (int, error) {
+	if r.srcAt != nil {
+		n, err := r.srcAt.ReadAt(p, off)
+		if err == io.EOF && n > 0 {
+			err = nil
+		}
+		return n, err
+	}
+	if r.srcPos != off {
+		if _, err := r.src.Seek(off, io.SeekStart); err != nil {
+			r.srcPos = -1
+			return 0, err
+		}
+	}
+	n, err := io.ReadFull(r.src, p)
+	r.srcPos = off + int64(n)
+	if err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return n, err
+}
@@ -0,0 +1,78 @@
+package aiff
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+// countingReadSeeker counts the reads made on the wrapped reader, it
+// doesn't implement io.ReaderAt.
+type countingReadSeeker struct {
+	io.ReadSeeker
+	reads int
+}
+
+func (r *countingReadSeeker) Read(p []byte) (int, error) {
+	r.reads++
+	return r.ReadSeeker.Read(p)
+}
+
+func TestDecoder_WithReadAhead(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/ring.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decode := func(readAhead int) (*Decoder, []int, int) {
+		r := &countingReadSeeker{ReadSeeker: bytes.NewReader(data)}
+		d := NewDecoder(r)
+		if readAhead > 0 {
+			d.WithReadAhead(readAhead)
+		}
+		if err := d.ReadInfo(); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Drain(); err != nil {
+			t.Fatal(err)
+		}
+		probeReads := r.reads
+		if err := d.Rewind(); err != nil {
+			t.Fatal(err)
+		}
+		buf, err := d.FullPCMBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d, buf.Data, probeReads
+	}
+
+	d, expected, reads := decode(0)
+	dRA, samples, readsRA := decode(4096)
+	if readsRA >= reads {
+		t.Fatalf("expected fewer reads with a read-ahead but got %d (vs %d)", readsRA, reads)
+	}
+	if !reflect.DeepEqual(samples, expected) {
+		t.Fatal("expected the same samples with a read-ahead")
+	}
+	if !reflect.DeepEqual(dRA.Markers, d.Markers) || !reflect.DeepEqual(dRA.Comments, d.Comments) || !reflect.DeepEqual(dRA.AppleInfo, d.AppleInfo) {
+		t.Fatal("expected the same metadata with a read-ahead")
+	}
+
+	// ReadAt doesn't move the reader
+	r := &readAheadReader{src: bytes.NewReader(data), size: 16, srcPos: -1}
+	b := make([]byte, 40)
+	if n, err := r.ReadAt(b, 4); n != len(b) || err != nil || !bytes.Equal(b, data[4:44]) {
+		t.Fatalf("unexpected ReadAt result %d, %v", n, err)
+	}
+	if n, err := r.ReadAt(b, int64(len(data)-10)); n != 10 || err != io.EOF {
+		t.Fatalf("expected a short read at the end of the data but got %d, %v", n, err)
+	}
+	if pos, _ := r.Seek(0, io.SeekCurrent); pos != 0 {
+		t.Fatalf("expected the reader to be at 0 but it's at %d", pos)
+	}
+	if end, _ := r.Seek(0, io.SeekEnd); end != int64(len(data)) {
+		t.Fatalf("expected the end to be at %d but got %d", len(data), end)
+	}
+}
@@ -0,0 +1,117 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-audio/audio"
+)
+
+// FrameFormat describes the frames returned by a Reader.
+type FrameFormat struct {
+	NumChans int
+	// BitDepth is the resolution of the samples, they are signed whatever
+	// the bit depth (8-bit samples included).
+	BitDepth int
+	// SampleRate is the exact sample rate declared by the file.
+	SampleRate float64
+	// NumFrames is the number of frames of the file.
+	NumFrames int64
+	Encoding  Encoding
+}
+
+// Reader reads the frames of a file. Unlike Decoder, it only deals with the
+// sound data: reads always end on frame boundaries, samples are always
+// signed and the position is expressed in frames.
+// The metadata of the file is available via the underlying decoder, see
+// Decoder.
+type Reader struct {
+	d      *Decoder
+	format FrameFormat
+	pos    int64
+}
+
+// NewReader reads the file information and returns a reader positioned on
+// the first frame.
+func NewReader(r io.ReadSeeker) (*Reader, error) {
+	if r == nil {
+		return nil, errors.New("can't read from a nil reader")
+	}
+	d := NewDecoder(r)
+	d.FrameAligned = true
+	if err := d.FwdToPCM(); err != nil {
+		return nil, err
+	}
+	if d.NumChans < 1 {
+		return nil, fmt.Errorf("invalid number of channels: %d", d.NumChans)
+	}
+	numFrames := int64(d.NumSampleFrames)
+	if d.actualFrames > 0 {
+		numFrames = d.actualFrames
+	}
+	return &Reader{
+		d: d,
+		format: FrameFormat{
+			NumChans:   int(d.NumChans),
+			BitDepth:   int(d.BitDepth),
+			SampleRate: d.ExactSampleRate(),
+			NumFrames:  numFrames,
+			Encoding:   d.Encoding,
+		},
+	}, nil
+}
+
+// Format returns the format of the frames.
+func (r *Reader) Format() FrameFormat {
+	return r.format
+}
+
+// Decoder returns the decoder used to read the file, giving access to its
+// metadata. It shouldn't be used to read the sound data.
+func (r *Reader) Decoder() *Decoder {
+	return r.d
+}
+
+// Frame returns the index of the next frame to be read.
+func (r *Reader) Frame() int64 {
+	return r.pos
+}
+
+// ReadFrames reads up to len(dst)/NumChans interleaved frames into dst and
+// returns the number of frames read. io.EOF is returned when no more frames
+// are available.
+func (r *Reader) ReadFrames(dst []int) (frames int, err error) {
+	if len(dst) < r.format.NumChans {
+		return 0, fmt.Errorf("a buffer of %d samples can't hold a frame of %d channels", len(dst), r.format.NumChans)
+	}
+	n, err := r.d.PCMBuffer(&audio.IntBuffer{Data: dst})
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, io.EOF
+	}
+	if r.format.BitDepth == 8 {
+		for i, v := range dst[:n] {
+			dst[i] = signedSample(v, 8)
+		}
+	}
+	frames = n / r.format.NumChans
+	r.pos += int64(frames)
+	return frames, nil
+}
+
+// SeekFrame moves the reader to the frame at index frame. Only the files
+// storing uncompressed PCM data can be sought anywhere, the others can only
+// be rewound (frame 0).
+func (r *Reader) SeekFrame(frame int64) error {
+	if frame < 0 || frame > r.format.NumFrames {
+		return fmt.Errorf("frame %d out of range [0, %d]", frame, r.format.NumFrames)
+	}
+	if err := r.d.seekFrame(frame); err != nil {
+		return err
+	}
+	r.pos = frame
+	return nil
+}
@@ -0,0 +1,40 @@
+package aiff
+
+import "io"
+
+// newReaderAt returns the io.ReaderAt implementation of the passed reader
+// or an adapter seeking back and forth if it doesn't implement it.
+func newReaderAt(r io.ReadSeeker) io.ReaderAt {
+	if ra, ok := r.(io.ReaderAt); ok {
+		return ra
+	}
+	return &seekReaderAt{rs: r}
+}
+
+// seekReaderAt implements io.ReaderAt on top of an io.ReadSeeker by
+// restoring the position of the reader after each read.
+// It isn't safe for concurrent use.
+type seekReaderAt struct {
+	rs io.ReadSeeker
+}
+
+func (r *seekReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	if r.rs == nil {
+		return 0, io.EOF
+	}
+	pos, err := r.rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = r.rs.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err = io.ReadFull(r.rs, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	if _, serr := r.rs.Seek(pos, io.SeekStart); serr != nil && err == nil {
+		err = serr
+	}
+	return n, err
+}
@@ -0,0 +1,87 @@
+package aiff
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	testCases := []struct {
+		input  string
+		format FrameFormat
+	}{
+		{"fixtures/ring.aif", FrameFormat{NumChans: 2, BitDepth: 16, SampleRate: 44100, NumFrames: 88064}},
+		{"fixtures/kick8b.aiff", FrameFormat{NumChans: 1, BitDepth: 8, SampleRate: 22050, NumFrames: 4484}},
+		{"fixtures/sowt.aif", FrameFormat{NumChans: 2, BitDepth: 16, SampleRate: 44100, NumFrames: 4064, Encoding: EncSowt}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			f, err := os.Open(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			r, err := NewReader(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Format() != tc.format {
+				t.Fatalf("expected the format to be %+v but got %+v", tc.format, r.Format())
+			}
+
+			// 7 samples hold 3 stereo frames
+			var data []int
+			dst := make([]int, 7)
+			for {
+				frames, err := r.ReadFrames(dst)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				data = append(data, dst[:frames*tc.format.NumChans]...)
+			}
+			if int64(len(data)) != tc.format.NumFrames*int64(tc.format.NumChans) {
+				t.Fatalf("expected %d frames but got %d samples", tc.format.NumFrames, len(data))
+			}
+			if r.Frame() != tc.format.NumFrames {
+				t.Fatalf("expected the reader to be at frame %d but got %d", tc.format.NumFrames, r.Frame())
+			}
+
+			// the samples are signed, whatever the bit depth
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+			d := NewDecoder(f)
+			full, err := d.FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, v := range full.Data {
+				full.Data[i] = signedSample(v, tc.format.BitDepth)
+			}
+			if !reflect.DeepEqual(data, full.Data) {
+				t.Fatal("expected the frames to match the decoded samples")
+			}
+
+			frame := tc.format.NumFrames / 2
+			if err := r.SeekFrame(frame); err != nil {
+				t.Fatal(err)
+			}
+			frames, err := r.ReadFrames(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			start := int(frame) * tc.format.NumChans
+			if !reflect.DeepEqual(dst[:frames*tc.format.NumChans], data[start:start+frames*tc.format.NumChans]) {
+				t.Fatalf("expected to read frame %d after seeking", frame)
+			}
+			if err := r.SeekFrame(tc.format.NumFrames + 1); err == nil {
+				t.Fatal("expected an error seeking past the end")
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package aiff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// String implements the Stringer interface, see Summary.
+func (d *Decoder) String() string {
+	return d.Summary()
+}
+
+// Summary returns a single line description of the file made of space
+// separated key=value pairs: form, encoding (AIFC only), sample rate, bit
+// depth, number of channels and duration in seconds.
+func (d *Decoder) Summary() string {
+	if d == nil {
+		return ""
+	}
+	fields := []string{"form=" + strings.TrimSpace(string(d.Form[:]))}
+	if d.Form == aifcID {
+		fields = append(fields, fmt.Sprintf("encoding=%q", string(d.Encoding[:])))
+	}
+	if d.SampleRate != 0 {
+		dur, _ := d.Duration()
+		fields = append(fields,
+			fmt.Sprintf("sample_rate=%d", d.SampleRate),
+			fmt.Sprintf("bit_depth=%d", d.BitDepth),
+			fmt.Sprintf("channels=%d", d.NumChans),
+			fmt.Sprintf("duration=%f", dur.Seconds()),
+		)
+	}
+	return strings.Join(fields, " ")
+}
+
+// Report returns a multi-line, column aligned, description of the file
+// including its metadata, markers and Apple specific information.
+// Call Drain first to make sure all the chunks were parsed.
+func (d *Decoder) Report() string {
+	if d == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	line := func(label string, format string, args ...interface{}) {
+		fmt.Fprintf(w, "%s:\t%s\n", label, fmt.Sprintf(format, args...))
+	}
+
+	line("Format", "%s", d.Form[:])
+	if d.Form == aifcID {
+		if d.EncodingName != "" {
+			line("Encoding", "%s (%s)", d.Encoding[:], d.EncodingName)
+		} else {
+			line("Encoding", "%s", d.Encoding[:])
+		}
+	}
+	if d.SampleRate != 0 {
+		dur, _ := d.Duration()
+		line("Channels", "%d", d.NumChans)
+		line("Sample rate", "%d Hz", d.SampleRate)
+		line("Bit depth", "%d", d.BitDepth)
+		line("Frames", "%d", d.NumSampleFrames)
+		line("Duration", "%f seconds", dur.Seconds())
+	}
+	if d.SSNDOffset != 0 || d.SSNDBlockSize != 0 {
+		line("SSND alignment", "offset %d, block size %d", d.SSNDOffset, d.SSNDBlockSize)
+	}
+	if d.TrailingBytes > 0 {
+		line("Trailing bytes", "%d (incomplete frame ignored)", d.TrailingBytes)
+	}
+	if d.Name != "" {
+		line("Name", "%s", d.Name)
+	}
+	if d.Author != "" {
+		line("Author", "%s", d.Author)
+	}
+	if d.Copyright != "" {
+		line("Copyright", "%s", d.Copyright)
+	}
+	for _, anno := range d.Annotations {
+		line("Annotation", "%s", anno)
+	}
+	for _, comment := range d.Comments {
+		line("Comment", "%s", comment)
+	}
+	for _, m := range d.Markers {
+		line("Marker", "#%d @ frame %d %s", m.ID, m.Position, m.Name)
+	}
+	if d.HasAppleInfo {
+		line("Key note", "%s", AppleNoteToPitch(d.AppleInfo.Note))
+		line("Scale", "%s", AppleScaleToString(d.AppleInfo.Scale))
+		line("Tempo", "%.2f BPM", d.Tempo())
+		line("Number of beats", "%d", d.AppleInfo.Beats)
+		line("Time signature", "%d/%d", d.AppleInfo.Numerator, d.AppleInfo.Denominator)
+		format := "one-shot"
+		if d.AppleInfo.IsLooping {
+			format = "loop"
+		}
+		line("Sample format", "%s", format)
+		if len(d.AppleInfo.Tags) > 0 {
+			line("Tags", "%s", strings.Join(d.AppleInfo.Tags, ", "))
+		}
+	}
+	w.Flush()
+	return buf.String()
+}
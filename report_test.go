@@ -0,0 +1,66 @@
+package aiff
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDecoder_Summary(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"fixtures/kick.aif", "form=AIFF sample_rate=22050 bit_depth=16 channels=1 duration=0.203356"},
+		{"fixtures/sowt2.aif", `form=AIFC encoding="sowt" sample_rate=44100 bit_depth=16 channels=2 duration=3.779524`},
+	}
+	for _, tc := range testCases {
+		f, err := os.Open(tc.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		d := NewDecoder(f)
+		d.ReadInfo()
+		if s := d.Summary(); s != tc.expected {
+			t.Fatalf("expected the summary of %s to be %q but got %q", tc.input, tc.expected, s)
+		}
+		if d.String() != d.Summary() {
+			t.Fatal("expected String to return the summary")
+		}
+	}
+}
+
+func TestDecoder_Report(t *testing.T) {
+	f, err := os.Open("fixtures/ring.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"Format:           AIFF",
+		"Sample rate:      44100 Hz",
+		"Frames:           88064",
+		"Marker:           #1 @ frame 0 Tempo: 98.0",
+		"Time signature:   4/4",
+		"Tags:             Sound Effect, Mech/Tech, Single",
+	}
+	report := d.Report()
+	lines := strings.Split(report, "\n")
+	for _, exp := range expected {
+		found := false
+		for _, l := range lines {
+			if l == exp {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("expected the report to contain %q, got:\n%s", exp, report)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package aiff
+
+// Resync describes stray bytes found between two chunks and skipped by the
+// decoder, see Decoder.ResyncWindow.
+type Resync struct {
+	// Offset is the position of the first skipped byte, where a chunk header
+	// was expected.
+	Offset int64
+	// Skipped is the number of bytes skipped to reach the next chunk.
+	Skipped int64
+}
+
+// resync looks for the next plausible chunk header within ResyncWindow bytes
+// after the invalid one found at offset. A header is plausible if its ID is
+// made of printable characters (not starting with a space) and the chunk
+// fits in the file. The offset of the header is returned, ok is false if
+// none was found or resyncing is disabled.
+func (d *Decoder) resync(offset int64) (next int64, ok bool) {
+	if d.ResyncWindow <= 0 {
+		return 0, false
+	}
+	fileSize, err := d.fileSize()
+	if err != nil {
+		return 0, false
+	}
+	buf := make([]byte, d.ResyncWindow+8)
+	n, _ := d.ra.ReadAt(buf, offset+1)
+	for i := 0; i+8 <= n; i++ {
+		var id [4]byte
+		copy(id[:], buf[i:i+4])
+		if id[0] == ' ' || !isChunkID(id) {
+			continue
+		}
+		size := int64(buf[i+4])<<24 | int64(buf[i+5])<<16 | int64(buf[i+6])<<8 | int64(buf[i+7])
+		next = offset + 1 + int64(i)
+		if next+8+size > fileSize {
+			continue
+		}
+		d.recordResync(Resync{Offset: offset, Skipped: next - offset})
+		return next, true
+	}
+	return 0, false
+}
+
+// recordResync adds the skipped region to Resyncs unless it was already
+// reported by a previous scan.
+func (d *Decoder) recordResync(r Resync) {
+	for _, known := range d.Resyncs {
+		if known == r {
+			return
+		}
+	}
+	d.logf("skipped %d stray bytes at %d", r.Skipped, r.Offset)
+	d.Resyncs = append(d.Resyncs, r)
+}
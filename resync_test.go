@@ -0,0 +1,51 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+)
+
+func TestDecoder_ResyncWindow(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// stray bytes between the COMM and SSND chunks
+	const commEnd = 38
+	broken := append(append(append([]byte(nil), data[:commEnd]...), 0, 0, 0xff), data[commEnd:]...)
+	binary.BigEndian.PutUint32(broken[4:], uint32(len(broken)-8))
+
+	if _, err := NewDecoder(bytes.NewReader(broken)).FullPCMBuffer(); err == nil {
+		t.Fatal("expected the stray bytes to break the parsing without resyncing")
+	}
+
+	d := NewDecoder(bytes.NewReader(broken))
+	d.ResyncWindow = 64
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.NumFrames() != 4484 {
+		t.Fatalf("expected 4484 frames but got %d", buf.NumFrames())
+	}
+	expected := []Resync{{Offset: commEnd, Skipped: 3}}
+	if len(d.Resyncs) != 1 || d.Resyncs[0] != expected[0] {
+		t.Fatalf("expected %v to be skipped but got %v", expected, d.Resyncs)
+	}
+
+	d = NewDecoder(bytes.NewReader(broken))
+	d.ResyncWindow = 64
+	issues := d.Validate()
+	if len(issues) != 1 || issues[0].Check != "garbage" || issues[0].Offset != commEnd {
+		t.Fatalf("expected the stray bytes to be reported but got %v", issues)
+	}
+
+	// the window is too small to reach the next chunk
+	d = NewDecoder(bytes.NewReader(broken))
+	d.ResyncWindow = 1
+	if _, err := d.FullPCMBuffer(); err == nil {
+		t.Fatal("expected the parsing to fail when the next chunk is out of the window")
+	}
+}
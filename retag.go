@@ -0,0 +1,46 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// RetagSampleRate rewrites the sample rate declared in the COMM chunk of the
+// passed file in place, for instance to fix a 48kHz recording labeled as
+// 44.1kHz. The sound data isn't resampled and the rest of the file is left
+// untouched, the duration and pitch at playback change accordingly.
+func RetagSampleRate(rw io.ReadWriteSeeker, sampleRate float64) error {
+	if rw == nil {
+		return errors.New("can't retag a nil pointer")
+	}
+	if sampleRate <= 0 || math.IsInf(sampleRate, 0) || math.IsNaN(sampleRate) {
+		return fmt.Errorf("invalid sample rate: %v", sampleRate)
+	}
+	d := NewDecoder(rw)
+	if err := d.readHeaders(); err != nil {
+		return fmt.Errorf("failed to read header - %v", err)
+	}
+	offset := int64(12)
+	for {
+		id, size, err := d.iDnSizeAt(offset)
+		if err != nil {
+			return fmt.Errorf("COMM chunk not found - %v", err)
+		}
+		if id == COMMID {
+			if size < 18 {
+				return fmt.Errorf("COMM chunk too short: %d bytes", size)
+			}
+			// the sample rate follows the number of channels (2 bytes), the
+			// number of frames (4 bytes) and the bit depth (2 bytes)
+			if _, err := rw.Seek(offset+8+8, io.SeekStart); err != nil {
+				return err
+			}
+			b := Float80FromFloat64(sampleRate)
+			_, err := rw.Write(b[:])
+			return err
+		}
+		offset += 8 + int64(size) + int64(size%2)
+	}
+}
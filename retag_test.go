@@ -0,0 +1,61 @@
+package aiff
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestRetagSampleRate(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	for _, path := range []string{"fixtures/kick.aif", "fixtures/sowt2.aif"} {
+		t.Run(path, func(t *testing.T) {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := "testOutput/retag.aif"
+			if err := ioutil.WriteFile(out, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(out)
+			f, err := os.OpenFile(out, os.O_RDWR, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			if err := RetagSampleRate(f, 0); err == nil {
+				t.Fatal("expected an invalid sample rate to be rejected")
+			}
+			if err := RetagSampleRate(f, 48000); err != nil {
+				t.Fatal(err)
+			}
+			retagged, err := ioutil.ReadFile(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(retagged) != len(data) {
+				t.Fatalf("expected the size to stay %d but got %d", len(data), len(retagged))
+			}
+			d := NewDecoder(bytes.NewReader(retagged))
+			if err := d.ReadInfo(); err != nil {
+				t.Fatal(err)
+			}
+			if d.SampleRate != 48000 {
+				t.Fatalf("expected the sample rate to be 48000 but got %d", d.SampleRate)
+			}
+			// only the 10 bytes of the sample rate changed
+			var changed int
+			for i := range data {
+				if data[i] != retagged[i] {
+					changed++
+				}
+			}
+			if changed > 10 {
+				t.Fatalf("expected only the sample rate to change but %d bytes differ", changed)
+			}
+		})
+	}
+}
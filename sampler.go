@@ -0,0 +1,121 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SamplerRegion describes how a sampler should play the file, it is built
+// from the instrument (INST) chunk and the markers delimiting its loops.
+// It can be exported as an SFZ region (see SFZ) or serialized in any other
+// sampler format.
+type SamplerRegion struct {
+	// Sample is the path of the sound file as referenced by the sampler.
+	Sample string
+	// RootKey is the MIDI note playing the sound at its original pitch.
+	RootKey int
+	// Tune is the pitch shift in cents.
+	Tune int
+	// LowKey, HighKey, LowVelocity and HighVelocity are the ranges the
+	// region responds to, 0 when not set.
+	LowKey       int
+	HighKey      int
+	LowVelocity  int
+	HighVelocity int
+	// Volume is the gain in dB.
+	Volume int
+	// LoopMode is the play mode of the sustain loop, LoopOff if the file
+	// doesn't have one.
+	LoopMode LoopMode
+	// LoopStart and LoopEnd are the first and last frames of the sustain
+	// loop (both included).
+	LoopStart uint32
+	LoopEnd   uint32
+}
+
+// SamplerRegion builds the sampler region of the file from its INST and
+// MARK chunks. The sample path is used as is in the region. The chunks are
+// parsed if needed (see Drain), an error is returned if the file doesn't
+// have an INST chunk or if the markers of the sustain loop are missing.
+func (d *Decoder) SamplerRegion(sample string) (*SamplerRegion, error) {
+	if d == nil {
+		return nil, errors.New("can't export a nil decoder")
+	}
+	if d.Instrument == nil {
+		if err := d.Drain(); err != nil {
+			return nil, err
+		}
+	}
+	inst := d.Instrument
+	if inst == nil {
+		return nil, errors.New("INST chunk not found")
+	}
+	r := &SamplerRegion{
+		Sample:  sample,
+		RootKey: int(inst.BaseNote),
+		Tune:    int(inst.Detune),
+		Volume:  int(inst.Gain),
+	}
+	// some applications leave the ranges zeroed
+	if inst.HighNote > 0 {
+		r.LowKey, r.HighKey = int(inst.LowNote), int(inst.HighNote)
+	}
+	if inst.HighVelocity > 0 {
+		r.LowVelocity, r.HighVelocity = int(inst.LowVelocity), int(inst.HighVelocity)
+	}
+	if loop := inst.SustainLoop; loop.PlayMode != LoopOff {
+		start, ok := d.markerPosition(loop.Begin)
+		if !ok {
+			return nil, fmt.Errorf("marker %d starting the sustain loop not found", loop.Begin)
+		}
+		end, ok := d.markerPosition(loop.End)
+		if !ok {
+			return nil, fmt.Errorf("marker %d ending the sustain loop not found", loop.End)
+		}
+		// markers are placed before the frame they point to
+		if end > start {
+			r.LoopMode = loop.PlayMode
+			r.LoopStart, r.LoopEnd = start, end-1
+		}
+	}
+	return r, nil
+}
+
+// markerPosition returns the position of the marker matching the ID.
+func (d *Decoder) markerPosition(id int16) (uint32, bool) {
+	for _, m := range d.Markers {
+		if m.ID == id {
+			return m.Position, true
+		}
+	}
+	return 0, false
+}
+
+// SFZ returns the region as an SFZ <region> definition.
+func (r *SamplerRegion) SFZ() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<region>\nsample=%s\npitch_keycenter=%d\n", r.Sample, r.RootKey)
+	if r.HighKey > 0 {
+		fmt.Fprintf(&b, "lokey=%d\nhikey=%d\n", r.LowKey, r.HighKey)
+	}
+	if r.HighVelocity > 0 {
+		fmt.Fprintf(&b, "lovel=%d\nhivel=%d\n", r.LowVelocity, r.HighVelocity)
+	}
+	if r.Tune != 0 {
+		fmt.Fprintf(&b, "tune=%d\n", r.Tune)
+	}
+	if r.Volume != 0 {
+		fmt.Fprintf(&b, "volume=%d\n", r.Volume)
+	}
+	switch r.LoopMode {
+	case LoopOff:
+		b.WriteString("loop_mode=no_loop\n")
+	case LoopForwardBackward:
+		b.WriteString("loop_type=alternate\n")
+		fallthrough
+	default:
+		fmt.Fprintf(&b, "loop_mode=loop_sustain\nloop_start=%d\nloop_end=%d\n", r.LoopStart, r.LoopEnd)
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package aiff
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestDecoder_SamplerRegion(t *testing.T) {
+	inst := []byte{60, 0xf6, 48, 72, 1, 127, 0xff, 0xfa,
+		0, 1, 0, 1, 0, 2, // sustain loop
+		0, 0, 0, 0, 0, 0} // release loop
+	// 2 markers: #1 @ frame 100 and #2 @ frame 1100
+	mark := []byte{0, 2,
+		0, 1, 0, 0, 0, 100, 0, 0,
+		0, 2, 0, 0, 0x04, 0x4c, 0, 0}
+	data := withChunks(t, "fixtures/kick.aif", testChunk("INST", inst), testChunk("MARK", mark))
+
+	d := NewDecoder(bytes.NewReader(data))
+	r, err := d.SamplerRegion("kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := SamplerRegion{
+		Sample: "kick.aif", RootKey: 60, Tune: -10,
+		LowKey: 48, HighKey: 72, LowVelocity: 1, HighVelocity: 127, Volume: -6,
+		LoopMode: LoopForward, LoopStart: 100, LoopEnd: 1099,
+	}
+	if *r != expected {
+		t.Fatalf("expected %+v but got %+v", expected, *r)
+	}
+	sfz := "<region>\nsample=kick.aif\npitch_keycenter=60\nlokey=48\nhikey=72\nlovel=1\nhivel=127\n" +
+		"tune=-10\nvolume=-6\nloop_mode=loop_sustain\nloop_start=100\nloop_end=1099\n"
+	if r.SFZ() != sfz {
+		t.Fatalf("expected:\n%s\ngot:\n%s", sfz, r.SFZ())
+	}
+
+	// the markers of the loop are missing
+	d = NewDecoder(bytes.NewReader(withChunks(t, "fixtures/kick.aif", testChunk("INST", inst))))
+	if _, err := d.SamplerRegion("kick.aif"); err == nil {
+		t.Fatal("expected an error when the loop markers are missing")
+	}
+
+	f, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := NewDecoder(f).SamplerRegion("kick.aif"); err == nil {
+		t.Fatal("expected an error when the file doesn't have an INST chunk")
+	}
+}
+
+func TestDecoder_Instrument(t *testing.T) {
+	f, err := os.Open("fixtures/sowt.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Instrument == nil {
+		t.Fatal("expected the INST chunk to be parsed")
+	}
+	expected := Loop{PlayMode: LoopForward, Begin: 1, End: 2}
+	if d.Instrument.SustainLoop != expected || d.Instrument.ReleaseLoop != (Loop{}) {
+		t.Fatalf("unexpected loops %+v, %+v", d.Instrument.SustainLoop, d.Instrument.ReleaseLoop)
+	}
+}
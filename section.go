@@ -0,0 +1,97 @@
+package aiff
+
+import (
+	"fmt"
+	"io"
+)
+
+// Section gives access to the raw PCM data of a file as an io.ReadSeeker
+// and io.ReaderAt. Offsets are relative to the first byte of the sound data
+// and the data ends on the last full frame, so seeking to a multiple of
+// FrameSize always lands on a frame boundary, see SeekFrame.
+// A section reads at absolute offsets of the underlying reader and doesn't
+// move the decoder. Several sections of the same file can only be read
+// concurrently if the reader passed to NewDecoder implements io.ReaderAt,
+// the other readers are shared by the sections and the decoder.
+type Section struct {
+	sr        *io.SectionReader
+	frameSize int
+}
+
+// PCMSection returns the sound data of the file as a Section. The decoder is
+// forwarded to the PCM data if needed. nil is returned if the sound data
+// can't be located (see Err for the details) or isn't uncompressed PCM.
+func (d *Decoder) PCMSection() *Section {
+	if d == nil {
+		return nil
+	}
+	start, length, err := d.PCMOffset()
+	if err != nil {
+		if d.err == nil {
+			d.err = err
+		}
+		return nil
+	}
+	// the decoder can still be used when the section is refused
+	if _, ok := pcmByteOrder(d.Encoding); !ok {
+		return nil
+	}
+	frameSize := bytesPerSample(int(d.BitDepth)) * int(d.NumChans)
+	if frameSize < 1 {
+		return nil
+	}
+	length -= length % int64(frameSize)
+	return &Section{
+		sr:        io.NewSectionReader(d.ra, start, length),
+		frameSize: frameSize,
+	}
+}
+
+// Read implements io.Reader.
+func (s *Section) Read(p []byte) (int, error) {
+	return s.sr.Read(p)
+}
+
+// ReadAt implements io.ReaderAt, off is relative to the start of the sound
+// data.
+func (s *Section) ReadAt(p []byte, off int64) (int, error) {
+	return s.sr.ReadAt(p, off)
+}
+
+// Seek implements io.Seeker, io.SeekStart is the start of the sound data and
+// io.SeekEnd the end of its last full frame.
+func (s *Section) Seek(offset int64, whence int) (int64, error) {
+	return s.sr.Seek(offset, whence)
+}
+
+// Size returns the size of the sound data in bytes.
+func (s *Section) Size() int64 {
+	return s.sr.Size()
+}
+
+// FrameSize returns the size of a frame (a sample of each channel) in bytes.
+func (s *Section) FrameSize() int {
+	return s.frameSize
+}
+
+// NumFrames returns the number of frames of the section.
+func (s *Section) NumFrames() int64 {
+	return s.sr.Size() / int64(s.frameSize)
+}
+
+// Frame returns the index of the frame at the current position, the frame
+// being read if the position isn't on a frame boundary.
+func (s *Section) Frame() int64 {
+	pos, _ := s.sr.Seek(0, io.SeekCurrent)
+	return pos / int64(s.frameSize)
+}
+
+// SeekFrame moves to the first byte of the frame at index frame. Seeking to
+// NumFrames positions the section at the end of the sound data.
+func (s *Section) SeekFrame(frame int64) error {
+	if frame < 0 || frame > s.NumFrames() {
+		return fmt.Errorf("frame %d out of range [0, %d]", frame, s.NumFrames())
+	}
+	_, err := s.sr.Seek(frame*int64(s.frameSize), io.SeekStart)
+	return err
+}
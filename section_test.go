@@ -0,0 +1,94 @@
+package aiff
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDecoder_PCMSection(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(bytes.NewReader(data))
+	s := d.PCMSection()
+	if s == nil {
+		t.Fatal(d.Err())
+	}
+	start, length, err := d.PCMOffset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.FrameSize() != 2 {
+		t.Fatalf("expected 2 bytes frames, got %d", s.FrameSize())
+	}
+	if s.Size() != length || s.NumFrames() != int64(d.NumSampleFrames) {
+		t.Fatalf("expected %d bytes / %d frames, got %d / %d", length, d.NumSampleFrames, s.Size(), s.NumFrames())
+	}
+
+	all, err := ioutil.ReadAll(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(all, data[start:start+length]) {
+		t.Fatal("the section doesn't match the sound data")
+	}
+
+	// loop a region
+	if err := s.SeekFrame(100); err != nil {
+		t.Fatal(err)
+	}
+	region := make([]byte, 10*s.FrameSize())
+	for i := 0; i < 2; i++ {
+		if _, err := io.ReadFull(s, region); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(region, all[200:220]) {
+			t.Fatalf("pass %d: unexpected region content", i)
+		}
+		if got := s.Frame(); got != 110 {
+			t.Fatalf("expected to be at frame 110, got %d", got)
+		}
+		if _, err := s.Seek(-int64(len(region)), io.SeekCurrent); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if pos, err := s.Seek(-int64(s.FrameSize()), io.SeekEnd); err != nil || pos != length-2 {
+		t.Fatalf("expected to seek to %d, got %d, %v", length-2, pos, err)
+	}
+	if err := s.SeekFrame(s.NumFrames() + 1); err == nil {
+		t.Fatal("expected an error when seeking past the end")
+	}
+
+	// the section doesn't move the decoder
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf.Data) != int(d.NumSampleFrames) {
+		t.Fatalf("expected %d samples, got %d", d.NumSampleFrames, len(buf.Data))
+	}
+}
+
+func TestDecoder_PCMSection_notPCM(t *testing.T) {
+	f, err := os.Open("fixtures/ableton.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+	if s := d.PCMSection(); s != nil {
+		t.Fatal("expected no section for compressed data")
+	}
+	// the decoder isn't affected
+	if err := d.Err(); err != nil {
+		t.Fatalf("expected no decoder error, got %v", err)
+	}
+	if _, err := d.FullPCMBuffer(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,64 @@
+package aiff
+
+import "encoding/binary"
+
+// checkSizes compares the sizes declared in the FORM and SSND headers with
+// the actual size of the underlying data and sets SizeTrusted accordingly.
+func (d *Decoder) checkSizes() {
+	d.SizeTrusted = true
+	fileSize, err := d.fileSize()
+	if err != nil {
+		return
+	}
+	if d.Size == 0 || int64(d.Size)+8 < fileSize {
+		d.SizeTrusted = false
+	}
+
+	ssnd, ok := d.ssndInfo()
+	if !ok || ssnd.Offset+16 > fileSize {
+		return
+	}
+	offset, size := ssnd.Offset, ssnd.Size
+	end := offset + 8 + int64(size) + int64(size%2)
+	// the declared size is right if it leads to another valid chunk,
+	// some encoders don't write the pad byte of odd sized chunks.
+	if d.isChunkAt(end, fileSize) || (size%2 != 0 && d.isChunkAt(end-1, fileSize)) {
+		return
+	}
+	actual := fileSize - offset - 8
+	if actual <= int64(size)+int64(size%2) {
+		return
+	}
+	d.SizeTrusted = false
+	d.ssndSize = actual
+
+	var dataOffset [4]byte
+	if _, err := d.ra.ReadAt(dataOffset[:], offset+8); err != nil {
+		return
+	}
+	frameSize := int64(bytesPerSample(int(d.BitDepth)) * int(d.NumChans))
+	if _, ok := pcmByteOrder(d.Encoding); ok && frameSize > 0 {
+		d.actualFrames = (actual - 8 - int64(binary.BigEndian.Uint32(dataOffset[:]))) / frameSize
+	}
+}
+
+// ssndInfo returns the first SSND chunk of the chunk table, see scanChunks.
+func (d *Decoder) ssndInfo() (ChunkInfo, bool) {
+	table, _ := d.scanChunks()
+	for _, entry := range table {
+		if entry.ID == SSNDID {
+			return entry, true
+		}
+	}
+	return ChunkInfo{}, false
+}
+
+// isChunkAt reports whether a valid chunk header, fitting in the file, is
+// found at the given offset.
+func (d *Decoder) isChunkAt(offset, fileSize int64) bool {
+	if offset+8 > fileSize {
+		return false
+	}
+	id, size, err := d.iDnSizeAt(offset)
+	return err == nil && isChunkID(id) && offset+8+int64(size) <= fileSize
+}
@@ -0,0 +1,86 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDecoder_SizeTrusted(t *testing.T) {
+	in, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	d := NewDecoder(in)
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.SizeTrusted {
+		t.Fatal("expected the sizes of kick.aif to be trusted")
+	}
+	expectedDuration, err := d.Duration()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Mkdir("testOutput", 0777)
+	path := "testOutput/wrapped_sizes.aif"
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(path)
+	e := NewEncoder(out, d.SampleRate, int(d.BitDepth), int(d.NumChans))
+	if err := e.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	out.Close()
+
+	// simulate the headers of a recording that went over 4GB:
+	// zeroed FORM size and wrapped SSND size/frame count.
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary.BigEndian.PutUint32(data[4:], 0)
+	binary.BigEndian.PutUint32(data[22:], 100)
+	binary.BigEndian.PutUint32(data[42:], 1000)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d2 := NewDecoder(f)
+	duration, err := d2.Duration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d2.SizeTrusted {
+		t.Fatal("expected the sizes of the file not to be trusted")
+	}
+	if duration != expectedDuration {
+		t.Fatalf("expected the duration to be computed from the actual data: %s but got %s", expectedDuration, duration)
+	}
+	buf2, err := d2.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf2.Data) != len(buf.Data) {
+		t.Fatalf("expected %d samples but got %d", len(buf.Data), len(buf2.Data))
+	}
+	for i, v := range buf.Data {
+		if buf2.Data[i] != v {
+			t.Fatalf("sample at position %d didn't match, expected %d, got %d", i, v, buf2.Data[i])
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package aiff
+
+import (
+	"bytes"
+	"io"
+)
+
+// Confidence indicates how confident Sniff is that the data is AIFF/AIFC.
+type Confidence int
+
+const (
+	// ConfidenceNone means the data doesn't look like AIFF/AIFC at all.
+	ConfidenceNone Confidence = iota
+	// ConfidenceLow means an AIFF/AIFC signature was found but not where expected.
+	ConfidenceLow
+	// ConfidenceMedium means the magic bytes match but the COMM chunk couldn't be parsed.
+	ConfidenceMedium
+	// ConfidenceHigh means the magic bytes match and the COMM chunk is sane.
+	ConfidenceHigh
+)
+
+func (c Confidence) String() string {
+	switch c {
+	case ConfidenceNone:
+		return "none"
+	case ConfidenceLow:
+		return "low"
+	case ConfidenceMedium:
+		return "medium"
+	case ConfidenceHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// FileInfo is the information collected when sniffing a file, see Sniff and
+// DecodeAll.
+type FileInfo struct {
+	// Offset is the position of the FORM header, 0 unless it was found
+	// further in the data.
+	Offset          int64
+	Form            [4]byte
+	NumChans        uint16
+	NumSampleFrames uint32
+	BitDepth        uint16
+	SampleRate      int
+	Encoding        Encoding
+	EncodingName    string
+}
+
+const (
+	// sniffLen is how far Sniff looks for a signature.
+	sniffLen = 4096
+	// sniffMaxChunks is the number of chunks Sniff inspects to find the COMM chunk.
+	sniffMaxChunks = 64
+)
+
+// Sniff inspects the magic bytes and the minimal structure of the passed data
+// to tell if it's an AIFF/AIFC file. It doesn't assume the file is well
+// formed and only reads a few bytes so it can be used to classify a lot of
+// files quickly. The returned info is nil when no signature was found.
+// An error is only returned when the reader fails.
+func Sniff(r io.ReaderAt) (Confidence, *FileInfo, error) {
+	head := make([]byte, sniffLen)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return ConfidenceNone, nil, err
+	}
+	head = head[:n]
+
+	offset := int64(-1)
+	if len(head) >= 12 && bytes.Equal(head[:4], formID[:]) && isAIFFForm(head[8:12]) {
+		offset = 0
+	} else {
+		// look for an embedded signature
+		for i := bytes.Index(head, formID[:]); i >= 0; {
+			if i+12 <= len(head) && isAIFFForm(head[i+8:i+12]) {
+				offset = int64(i)
+				break
+			}
+			next := bytes.Index(head[i+1:], formID[:])
+			if next < 0 {
+				break
+			}
+			i += next + 1
+		}
+		if offset < 0 {
+			return ConfidenceNone, nil, nil
+		}
+	}
+
+	info := &FileInfo{Offset: offset}
+	var hdr [12]byte
+	if _, err := r.ReadAt(hdr[:], offset); err != nil && err != io.EOF {
+		return ConfidenceNone, nil, err
+	}
+	copy(info.Form[:], hdr[8:12])
+	confidence := ConfidenceMedium
+	if offset > 0 {
+		confidence = ConfidenceLow
+	}
+
+	d := &Decoder{ra: r, Form: info.Form}
+	chunkOffset := offset + 12
+	for i := 0; i < sniffMaxChunks; i++ {
+		id, size, err := d.iDnSizeAt(chunkOffset)
+		if err != nil || !isChunkID(id) {
+			break
+		}
+		if id == COMMID {
+			if err := d.parseCommChunk(io.NewSectionReader(r, chunkOffset+8, int64(size)), size); err != nil {
+				break
+			}
+			info.NumChans = d.NumChans
+			info.NumSampleFrames = d.NumSampleFrames
+			info.BitDepth = d.BitDepth
+			info.SampleRate = d.SampleRate
+			info.Encoding = d.Encoding
+			info.EncodingName = d.EncodingName
+			if offset == 0 && d.NumChans > 0 && d.BitDepth > 0 && d.BitDepth <= 64 && d.SampleRate > 0 {
+				confidence = ConfidenceHigh
+			}
+			break
+		}
+		chunkOffset += 8 + int64(size) + int64(size%2)
+	}
+
+	return confidence, info, nil
+}
+
+func isAIFFForm(b []byte) bool {
+	return bytes.Equal(b, aiffID[:]) || bytes.Equal(b, aifcID[:])
+}
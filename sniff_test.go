@@ -0,0 +1,87 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestSniff(t *testing.T) {
+	testCases := []struct {
+		input      string
+		confidence Confidence
+		sampleRate int
+	}{
+		{"fixtures/kick.aif", ConfidenceHigh, 22050},
+		{"fixtures/sowt.aif", ConfidenceHigh, 44100},
+		{"fixtures/ring.aif", ConfidenceHigh, 44100},
+		{"fixtures/kick.wav", ConfidenceNone, 0},
+		{"fixtures/sample.avi", ConfidenceNone, 0},
+	}
+
+	for _, tc := range testCases {
+		f, err := os.Open(tc.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		confidence, info, err := Sniff(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if confidence != tc.confidence {
+			t.Fatalf("expected %s to be sniffed with a %s confidence but got %s", tc.input, tc.confidence, confidence)
+		}
+		if tc.sampleRate == 0 {
+			if info != nil {
+				t.Fatalf("expected no info for %s but got %+v", tc.input, info)
+			}
+			continue
+		}
+		if info.SampleRate != tc.sampleRate {
+			t.Fatalf("expected %s to have a sample rate of %d but got %d", tc.input, tc.sampleRate, info.SampleRate)
+		}
+	}
+
+	data, err := ioutil.ReadFile("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// embedded in another container
+	embedded := append([]byte("garbage header."), data...)
+	confidence, info, err := Sniff(bytes.NewReader(embedded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if confidence != ConfidenceLow || info.Offset != 15 || info.NumChans != 1 {
+		t.Fatalf("expected to find the embedded file at 15 with a low confidence, got %s %+v", confidence, info)
+	}
+	// truncated before the COMM chunk is complete
+	confidence, _, err = Sniff(bytes.NewReader(data[:20]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if confidence != ConfidenceMedium {
+		t.Fatalf("expected a truncated file to be sniffed with a medium confidence but got %s", confidence)
+	}
+
+	// the declared COMM size isn't used to allocate memory
+	bogus := append([]byte(nil), data...)
+	binary.BigEndian.PutUint32(bogus[16:], 0xFFFFFFF0)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, info, err = Sniff(bytes.NewReader(bogus))
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.SampleRate != 22050 {
+		t.Fatalf("expected a sample rate of 22050 but got %d", info.SampleRate)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Fatalf("expected a bogus COMM size not to be allocated, %d bytes were", allocated)
+	}
+}
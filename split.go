@@ -0,0 +1,98 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// headerSize is the size of the headers written by the Encoder before the
+// PCM data: FORM header (12) + COMM chunk (8+18) + SSND header (8+8).
+const headerSize = 54
+
+// Split cuts the sound data of the passed input into sample accurate segments
+// lasting at most maxDur. emit is called for each segment and must return the
+// writer the segment will be written to as an AIFF file. The PCM data isn't
+// decoded but copied as is and each segment gets its own headers.
+// Note that the returned writers are NOT being closed.
+func Split(r io.ReadSeeker, maxDur time.Duration, emit func(i int) io.WriteSeeker) error {
+	if maxDur <= 0 {
+		return fmt.Errorf("invalid segment duration: %v", maxDur)
+	}
+	return split(r, emit, func(d *Decoder, i int) int {
+		return int(framesFor(maxDur, d.SampleRate))
+	})
+}
+
+// SplitSize works like Split but cuts the input in segments so that each
+// written file is at most maxBytes long (headers included).
+func SplitSize(r io.ReadSeeker, maxBytes int64, emit func(i int) io.WriteSeeker) error {
+	return split(r, emit, func(d *Decoder, i int) int {
+		frameSize := int64(bytesPerSample(int(d.BitDepth)) * int(d.NumChans))
+		return int((maxBytes - headerSize) / frameSize)
+	})
+}
+
+// SplitAt works like Split but cuts the input at the passed frame positions,
+// which must be in increasing order. The last segment holds the frames
+// following the last cut point.
+func SplitAt(r io.ReadSeeker, cuts []int64, emit func(i int) io.WriteSeeker) error {
+	for i, cut := range cuts {
+		if cut < 1 || (i > 0 && cut <= cuts[i-1]) {
+			return fmt.Errorf("invalid cut point %d at frame %d, the cut points must be positive and increasing", i, cut)
+		}
+	}
+	return split(r, emit, func(d *Decoder, i int) int {
+		switch {
+		case i == 0 && len(cuts) > 0:
+			return int(cuts[0])
+		case i < len(cuts):
+			return int(cuts[i] - cuts[i-1])
+		default:
+			// the rest of the data
+			return math.MaxInt32
+		}
+	})
+}
+
+// split copies the PCM data of the input to the segments returned by emit,
+// segmentFrames returns the number of frames of the i-th segment.
+func split(r io.ReadSeeker, emit func(i int) io.WriteSeeker, segmentFrames func(d *Decoder, i int) int) error {
+	if emit == nil {
+		return errors.New("can't split without a segment emitter")
+	}
+	d := NewDecoder(r)
+	numFrames, err := d.fwdToRawPCM()
+	if err != nil {
+		return err
+	}
+	for i := 0; numFrames > 0; i++ {
+		toCopy := segmentFrames(d, i)
+		if toCopy < 1 {
+			return errors.New("segments are too small to contain a single frame")
+		}
+		w := emit(i)
+		if w == nil {
+			return fmt.Errorf("segment %d - nil writer", i)
+		}
+		if numFrames < toCopy {
+			toCopy = numFrames
+		}
+		e := NewEncoder(w, d.SampleRate, int(d.BitDepth), int(d.NumChans))
+		n, err := copyRawFrames(e, d, toCopy)
+		if err != nil {
+			return fmt.Errorf("segment %d - failed to copy the PCM data - %v", i, err)
+		}
+		if err := e.Close(); err != nil {
+			return fmt.Errorf("segment %d - %v", i, err)
+		}
+		if n < toCopy {
+			// the PCM data was shorter than advertised
+			break
+		}
+		numFrames -= n
+	}
+	return nil
+}
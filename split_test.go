@@ -0,0 +1,156 @@
+package aiff
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-audio/audio"
+)
+
+func TestSplit(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	testCases := []struct {
+		input       string
+		maxDur      time.Duration
+		maxBytes    int64
+		cuts        []int64
+		numSegments int
+	}{
+		// 4484 frames @ 22050
+		{"fixtures/kick.aif", 50 * time.Millisecond, 0, nil, 5},
+		// 4064 stereo frames @ 44100 - little endian
+		{"fixtures/sowt.aif", 0, 4000*4 + headerSize, nil, 2},
+		{"fixtures/kick.aif", 0, 0, []int64{1000, 1001, 4000}, 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			in, err := os.Open(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer in.Close()
+			expected, err := NewDecoder(in).FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := in.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			segments := []*os.File{}
+			emit := func(i int) io.WriteSeeker {
+				f, err := os.Create(fmt.Sprintf("testOutput/split_%d.aif", i))
+				if err != nil {
+					t.Fatal(err)
+				}
+				segments = append(segments, f)
+				return f
+			}
+			switch {
+			case tc.maxDur > 0:
+				err = Split(in, tc.maxDur, emit)
+			case tc.cuts != nil:
+				err = SplitAt(in, tc.cuts, emit)
+			default:
+				err = SplitSize(in, tc.maxBytes, emit)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(segments) != tc.numSegments {
+				t.Fatalf("expected %d segments but got %d", tc.numSegments, len(segments))
+			}
+
+			samples := []int{}
+			for _, f := range segments {
+				defer os.Remove(f.Name())
+				defer f.Close()
+				info, err := f.Stat()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tc.maxBytes > 0 && info.Size() > tc.maxBytes {
+					t.Fatalf("%s is %d bytes long, more than the max of %d", f.Name(), info.Size(), tc.maxBytes)
+				}
+				if _, err := f.Seek(0, 0); err != nil {
+					t.Fatal(err)
+				}
+				d := NewDecoder(f)
+				buf, err := d.FullPCMBuffer()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if int64(d.Size) != info.Size()-8 {
+					t.Fatalf("wrong header size data, expected %d, got %d", info.Size()-8, d.Size)
+				}
+				if d.NumSampleFrames != uint32(buf.NumFrames()) {
+					t.Fatalf("%s header reports %d frames but contains %d", f.Name(), d.NumSampleFrames, buf.NumFrames())
+				}
+				samples = append(samples, buf.Data...)
+			}
+			if len(samples) != len(expected.Data) {
+				t.Fatalf("expected %d samples but got %d", len(expected.Data), len(samples))
+			}
+			for i, v := range expected.Data {
+				if samples[i] != v {
+					t.Fatalf("sample at position %d didn't match, expected %d, got %d", i, v, samples[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplit_sampleAccurate(t *testing.T) {
+	os.Mkdir("testOutput", 0777)
+	in, err := os.Create("testOutput/split_accurate.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(in.Name())
+	defer in.Close()
+	// 700ms @ 44100 is 30870 frames, float math gives 30869
+	e := NewEncoder(in, 44100, 16, 1)
+	if err := e.Write(&audio.IntBuffer{Data: make([]int, 2*30870), Format: &audio.Format{NumChannels: 1, SampleRate: 44100}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	segments := []*os.File{}
+	emit := func(i int) io.WriteSeeker {
+		f, err := os.Create(fmt.Sprintf("testOutput/split_accurate_%d.aif", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		segments = append(segments, f)
+		return f
+	}
+	if err := Split(in, 700*time.Millisecond, emit); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range segments {
+		defer os.Remove(f.Name())
+		defer f.Close()
+	}
+	if len(segments) != 2 {
+		t.Fatalf("expected 2 segments but got %d", len(segments))
+	}
+	for _, f := range segments {
+		if _, err := f.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+		d := NewDecoder(f)
+		d.ReadInfo()
+		if d.NumSampleFrames != 30870 {
+			t.Fatalf("expected %s to hold 30870 frames but got %d", f.Name(), d.NumSampleFrames)
+		}
+	}
+}
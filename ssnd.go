@@ -0,0 +1,43 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FindAll returns the positions in the index of all the chunks matching the
+// ID. Files should only hold one SSND chunk but some, badly merged, contain
+// several of them.
+func (idx *ChunkIndex) FindAll(id [4]byte) []int {
+	var found []int
+	for i := 0; i < idx.Len(); i++ {
+		if idx.Chunks[i].ID == id {
+			found = append(found, i)
+		}
+	}
+	return found
+}
+
+// SelectSSND picks the i-th SSND chunk (starting at 0) as the sound data of
+// the file, the first one is decoded by default. The spec only allows one
+// SSND chunk but recovery tools can extract the data of the others from
+// badly merged files. The other SSND chunks are skipped.
+// The selection must be done before the sound data is accessed, it is kept
+// when the decoder is reset or rewound.
+func (d *Decoder) SelectSSND(i int) error {
+	if d == nil {
+		return errors.New("can't select the SSND chunk of a nil decoder")
+	}
+	if d.WasPCMAccessed() {
+		return errors.New("the SSND chunk must be selected before the sound data is accessed")
+	}
+	idx, err := d.Index()
+	if idx == nil {
+		return err
+	}
+	if n := len(idx.FindAll(SSNDID)); i < 0 || i >= n {
+		return fmt.Errorf("SSND chunk %d out of range [0, %d)", i, n)
+	}
+	d.ssndIndex = i
+	return nil
+}
@@ -0,0 +1,83 @@
+package aiff
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDecoder_SelectSSND(t *testing.T) {
+	// kick.aif is a 16-bit mono file, a second SSND chunk holding 4
+	// samples is appended
+	data := withChunks(t, "fixtures/kick.aif",
+		testChunk("SSND", []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0xff, 0xff, 0x80, 0}),
+	)
+
+	d := NewDecoder(bytes.NewReader(data))
+	idx, err := d.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found := idx.FindAll(SSNDID); len(found) != 2 {
+		t.Fatalf("expected 2 SSND chunks but got %v", found)
+	}
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf.Data) != 4484 {
+		t.Fatalf("expected the first SSND chunk to be decoded by default but got %d samples", len(buf.Data))
+	}
+	if err := d.SelectSSND(1); err == nil {
+		t.Fatal("expected an error selecting a SSND chunk after accessing the sound data")
+	}
+
+	d = NewDecoder(bytes.NewReader(data))
+	if err := d.SelectSSND(2); err == nil {
+		t.Fatal("expected an error selecting a missing SSND chunk")
+	}
+	if err := d.SelectSSND(1); err != nil {
+		t.Fatal(err)
+	}
+	expected := []int{1, 2, -1, -32768}
+	for i := 0; i < 2; i++ {
+		buf, err := d.FullPCMBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(buf.Data, expected) {
+			t.Fatalf("expected %v but got %v", expected, buf.Data)
+		}
+		// the selection is kept when rewinding
+		if err := d.Rewind(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the selected chunk isn't capped by the number of frames of the COMM
+	// chunk, which describes the first one
+	long := make([]byte, 8+2*5000)
+	for i := 8; i < len(long); i += 2 {
+		long[i+1] = 1
+	}
+	d = NewDecoder(bytes.NewReader(withChunks(t, "fixtures/kick.aif", testChunk("SSND", long))))
+	if err := d.SelectSSND(1); err != nil {
+		t.Fatal(err)
+	}
+	if buf, err = d.FullPCMBuffer(); err != nil {
+		t.Fatal(err)
+	}
+	if len(buf.Data) != 5000 || buf.Data[4999] != 1 {
+		t.Fatalf("expected the 5000 samples of the selected chunk but got %d", len(buf.Data))
+	}
+
+	var reported bool
+	for _, issue := range NewDecoder(bytes.NewReader(data)).Validate() {
+		if issue.Check == "ssnd" && issue.Severity == SeverityWarning {
+			reported = true
+		}
+	}
+	if !reported {
+		t.Fatal("expected Validate to report the multiple SSND chunks")
+	}
+}
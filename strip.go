@@ -0,0 +1,56 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// StripMetadata copies the passed file to w keeping only the chunks needed
+// to play it back (COMM, SSND and FVER) and the chunks listed in keep.
+// Comments, markers, Apple and ID3 tags and any other chunk are dropped, for
+// instance before publishing samples that may embed personal information.
+// The kept chunks are copied as is, in their original order.
+func StripMetadata(r io.ReadSeeker, w io.Writer, keep ...[4]byte) error {
+	d := NewDecoder(r)
+	idx, err := d.Index()
+	if err != nil {
+		return fmt.Errorf("failed to index the chunks - %v", err)
+	}
+	kept := map[[4]byte]bool{COMMID: true, SSNDID: true, fverID: true}
+	for _, id := range keep {
+		kept[id] = true
+	}
+
+	var chunks []ChunkInfo
+	// form type
+	formSize := int64(4)
+	for _, info := range idx.Chunks {
+		if !kept[info.ID] {
+			continue
+		}
+		chunks = append(chunks, info)
+		formSize += 8 + int64(info.Size) + int64(info.Size%2)
+	}
+	if formSize+8 > maxFileSize {
+		return fmt.Errorf("%w - the stripped FORM would be %d bytes", ErrTooLargeForAIFF, formSize)
+	}
+
+	if _, err := w.Write(formID[:]); err != nil {
+		return fmt.Errorf("%v when writing FORM header", err)
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(formSize)); err != nil {
+		return fmt.Errorf("%v when writing FORM size", err)
+	}
+	if _, err := w.Write(d.Form[:]); err != nil {
+		return fmt.Errorf("%v when writing FORM type", err)
+	}
+	for _, info := range chunks {
+		// the header, data and pad byte are copied together
+		size := 8 + int64(info.Size) + int64(info.Size%2)
+		if _, err := io.CopyN(w, io.NewSectionReader(d.ra, info.Offset, size), size); err != nil {
+			return fmt.Errorf("%v when copying the %q chunk", err, info.ID[:])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package aiff
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestStripMetadata(t *testing.T) {
+	data := withChunks(t, "fixtures/kick.aif",
+		testChunk("NAME", []byte("kick")),
+		testChunk("AUTH", []byte("someone")),
+		testChunk("ANNO", []byte("odd")),
+	)
+	testCases := []struct {
+		name     string
+		keep     [][4]byte
+		expected [][4]byte
+	}{
+		{"default", nil, [][4]byte{COMMID, SSNDID}},
+		{"keep name", [][4]byte{nameID}, [][4]byte{COMMID, SSNDID, nameID}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := StripMetadata(bytes.NewReader(data), &out, tc.keep...); err != nil {
+				t.Fatal(err)
+			}
+			d := NewDecoder(bytes.NewReader(out.Bytes()))
+			idx, err := d.Index()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := [][4]byte{}
+			for _, info := range idx.Chunks {
+				ids = append(ids, info.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.expected) {
+				t.Fatalf("expected chunks %q but got %q", tc.expected, ids)
+			}
+			for _, issue := range d.Validate() {
+				if issue.Severity > SeverityInfo {
+					t.Fatalf("unexpected issue: %v", issue)
+				}
+			}
+			buf, err := d.FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf.NumFrames() != 4484 {
+				t.Fatalf("expected 4484 frames but got %d", buf.NumFrames())
+			}
+		})
+	}
+}
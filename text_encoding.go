@@ -0,0 +1,79 @@
+package aiff
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// TextEncoding is the character encoding of the text stored in the chunks
+// (NAME, AUTH, (c) , ANNO, COMT, MARK and the compression name).
+type TextEncoding int
+
+const (
+	// TextEncodingAuto decodes the text as UTF-8 when valid, MacRoman
+	// otherwise. This is the default.
+	TextEncodingAuto TextEncoding = iota
+	// TextEncodingUTF8 decodes the text as UTF-8, invalid bytes are replaced
+	// by the unicode replacement character.
+	TextEncodingUTF8
+	// TextEncodingMacRoman decodes the text as MacRoman, the encoding used by
+	// classic Mac OS applications.
+	TextEncodingMacRoman
+)
+
+// SetTextEncoding sets the character encoding used to decode the text of the
+// chunks parsed after the call. The text is always surfaced as UTF-8.
+func (d *Decoder) SetTextEncoding(enc TextEncoding) {
+	if d == nil {
+		return
+	}
+	d.textEncoding = enc
+}
+
+// decodeText converts text read from the file into a valid UTF-8 string.
+func (d *Decoder) decodeText(b []byte) string {
+	switch d.textEncoding {
+	case TextEncodingUTF8:
+		return strings.ToValidUTF8(string(b), string(utf8.RuneError))
+	case TextEncodingMacRoman:
+		return macRomanToUTF8(b)
+	}
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	return macRomanToUTF8(b)
+}
+
+// macRomanToUTF8 converts MacRoman encoded text into UTF-8.
+func macRomanToUTF8(b []byte) string {
+	var sb strings.Builder
+	sb.Grow(len(b))
+	for _, c := range b {
+		if c < 0x80 {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteRune(macRoman[c-0x80])
+	}
+	return sb.String()
+}
+
+// macRoman maps the MacRoman characters from 0x80 to 0xFF to unicode.
+var macRoman = [128]rune{
+	0x00C4, 0x00C5, 0x00C7, 0x00C9, 0x00D1, 0x00D6, 0x00DC, 0x00E1,
+	0x00E0, 0x00E2, 0x00E4, 0x00E3, 0x00E5, 0x00E7, 0x00E9, 0x00E8,
+	0x00EA, 0x00EB, 0x00ED, 0x00EC, 0x00EE, 0x00EF, 0x00F1, 0x00F3,
+	0x00F2, 0x00F4, 0x00F6, 0x00F5, 0x00FA, 0x00F9, 0x00FB, 0x00FC,
+	0x2020, 0x00B0, 0x00A2, 0x00A3, 0x00A7, 0x2022, 0x00B6, 0x00DF,
+	0x00AE, 0x00A9, 0x2122, 0x00B4, 0x00A8, 0x2260, 0x00C6, 0x00D8,
+	0x221E, 0x00B1, 0x2264, 0x2265, 0x00A5, 0x00B5, 0x2202, 0x2211,
+	0x220F, 0x03C0, 0x222B, 0x00AA, 0x00BA, 0x03A9, 0x00E6, 0x00F8,
+	0x00BF, 0x00A1, 0x00AC, 0x221A, 0x0192, 0x2248, 0x2206, 0x00AB,
+	0x00BB, 0x2026, 0x00A0, 0x00C0, 0x00C3, 0x00D5, 0x0152, 0x0153,
+	0x2013, 0x2014, 0x201C, 0x201D, 0x2018, 0x2019, 0x00F7, 0x25CA,
+	0x00FF, 0x0178, 0x2044, 0x20AC, 0x2039, 0x203A, 0xFB01, 0xFB02,
+	0x2021, 0x00B7, 0x201A, 0x201E, 0x2030, 0x00C2, 0x00CA, 0x00C1,
+	0x00CB, 0x00C8, 0x00CD, 0x00CE, 0x00CF, 0x00CC, 0x00D3, 0x00D4,
+	0xF8FF, 0x00D2, 0x00DA, 0x00DB, 0x00D9, 0x0131, 0x02C6, 0x02DC,
+	0x00AF, 0x02D8, 0x02D9, 0x02DA, 0x00B8, 0x02DD, 0x02DB, 0x02C7,
+}
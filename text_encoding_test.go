@@ -0,0 +1,88 @@
+package aiff
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestDecoder_decodeText(t *testing.T) {
+	testCases := []struct {
+		enc      TextEncoding
+		in       string
+		expected string
+	}{
+		{TextEncodingAuto, "plain ascii", "plain ascii"},
+		{TextEncodingAuto, "Título", "Título"},
+		{TextEncodingAuto, "Caf\x8e \xa9 2001", "Café © 2001"},
+		{TextEncodingAuto, "\xb5Law 2:1", "µLaw 2:1"},
+		{TextEncodingUTF8, "Caf\x8e", "Caf�"},
+		{TextEncodingMacRoman, "Caf\x8e", "Café"},
+		// UTF-8 bytes forced to be decoded as MacRoman
+		{TextEncodingMacRoman, "Caf\xc3\xa9", "Caf√©"},
+	}
+	for _, tc := range testCases {
+		d := &Decoder{}
+		d.SetTextEncoding(tc.enc)
+		if out := d.decodeText([]byte(tc.in)); out != tc.expected {
+			t.Errorf("expected %q to be decoded as %q but got %q", tc.in, tc.expected, out)
+		}
+	}
+}
+
+func TestDecoder_SetTextEncoding(t *testing.T) {
+	data := withChunks(t, "fixtures/kick.aif",
+		testChunk("NAME", []byte("Tr\x8fs bien")),
+		testChunk("ANNO", []byte("d\xc3\xa9j\xc3\xa0 vu")),
+	)
+
+	d := NewDecoder(bytes.NewReader(data))
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Name != "Très bien" || d.Annotations[0] != "déjà vu" {
+		t.Fatalf("unexpected auto detected text: %q, %q", d.Name, d.Annotations[0])
+	}
+
+	d = NewDecoder(bytes.NewReader(data))
+	d.SetTextEncoding(TextEncodingUTF8)
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Name != "Tr�s bien" || d.Annotations[0] != "déjà vu" {
+		t.Fatalf("unexpected UTF-8 text: %q, %q", d.Name, d.Annotations[0])
+	}
+
+	f, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	buf, err := NewDecoder(f).FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/ulaw_name.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	e := NewEncoder(out, 22050, 16, 1)
+	e.Encoding = EncUlaw
+	if err := e.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d = NewDecoder(out)
+	d.ReadInfo()
+	if d.EncodingName != "µLaw 2:1" {
+		t.Fatalf("expected the MacRoman compression name to be decoded, got %q", d.EncodingName)
+	}
+}
@@ -0,0 +1,132 @@
+package aiff
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Timecode is a SMPTE timecode.
+type Timecode struct {
+	Hours   int
+	Minutes int
+	Seconds int
+	Frames  int
+	// FrameRate is the actual frame rate, such as 24, 25, 29.97 or 30.
+	FrameRate float64
+	// DropFrame is set when frame numbers are skipped to keep the timecode
+	// in sync with the wall clock (29.97 and 59.94 fps).
+	DropFrame bool
+}
+
+// String returns the timecode formatted as HH:MM:SS:FF, HH:MM:SS;FF for
+// drop frame timecodes.
+func (tc Timecode) String() string {
+	sep := ":"
+	if tc.DropFrame {
+		sep = ";"
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%02d", tc.Hours, tc.Minutes, tc.Seconds, sep, tc.Frames)
+}
+
+// StartTimecode returns the timecode of the first sample using the passed
+// frame rate. Drop frame is used for the 29.97 and 59.94 rates.
+// The timecode comes from the time reference of the broadcast info (see
+// BroadcastInfo) so the chunks need to be parsed first (see Drain).
+func (d *Decoder) StartTimecode(frameRate float64) (Timecode, error) {
+	if d == nil {
+		return Timecode{}, errors.New("can't read the timecode of a nil pointer")
+	}
+	if d.BroadcastInfo == nil {
+		return Timecode{}, errors.New("no time reference found")
+	}
+	if d.SampleRate < 1 {
+		return Timecode{}, fmt.Errorf("invalid sample rate: %d", d.SampleRate)
+	}
+	return SamplesToTimecode(d.BroadcastInfo.TimeReference, d.SampleRate, frameRate)
+}
+
+// SetStartTimecode sets the timecode of the first sample. It is stored as
+// the time reference of the broadcast info which is created if needed.
+func (e *Encoder) SetStartTimecode(tc Timecode) error {
+	samples, err := tc.Samples(e.SampleRate)
+	if err != nil {
+		return err
+	}
+	if e.BroadcastInfo == nil {
+		e.BroadcastInfo = &BroadcastInfo{}
+	}
+	e.BroadcastInfo.TimeReference = samples
+	return nil
+}
+
+// SamplesToTimecode converts a number of samples since midnight into a
+// timecode.
+func SamplesToTimecode(samples uint64, sampleRate int, frameRate float64) (Timecode, error) {
+	num, den, nominal, err := timecodeRate(frameRate)
+	if err != nil {
+		return Timecode{}, err
+	}
+	if sampleRate < 1 {
+		return Timecode{}, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+	tc := Timecode{FrameRate: frameRate, DropFrame: isDropFrameRate(num, den)}
+	frame := samples * num / (den * uint64(sampleRate))
+	if tc.DropFrame {
+		drop := uint64(nominal / 15)
+		framesPer10Min := uint64(num * 600 / den)
+		framesPerMin := uint64(nominal*60) - drop
+		tens, rem := frame/framesPer10Min, frame%framesPer10Min
+		frame += 9 * drop * tens
+		if rem >= drop {
+			frame += drop * ((rem - drop) / framesPerMin)
+		}
+	}
+	fps := uint64(nominal)
+	tc.Frames = int(frame % fps)
+	tc.Seconds = int(frame / fps % 60)
+	tc.Minutes = int(frame / (fps * 60) % 60)
+	tc.Hours = int(frame / (fps * 3600) % 24)
+	return tc, nil
+}
+
+// Samples converts the timecode into a number of samples since midnight.
+func (tc Timecode) Samples(sampleRate int) (uint64, error) {
+	num, den, nominal, err := timecodeRate(tc.FrameRate)
+	if err != nil {
+		return 0, err
+	}
+	if sampleRate < 1 {
+		return 0, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+	if tc.Hours < 0 || tc.Minutes < 0 || tc.Minutes > 59 || tc.Seconds < 0 || tc.Seconds > 59 || tc.Frames < 0 || tc.Frames >= nominal {
+		return 0, fmt.Errorf("invalid timecode: %s", tc)
+	}
+	frame := uint64(((tc.Hours*60+tc.Minutes)*60+tc.Seconds)*nominal + tc.Frames)
+	if tc.DropFrame && isDropFrameRate(num, den) {
+		minutes := uint64(tc.Hours*60 + tc.Minutes)
+		frame -= uint64(nominal/15) * (minutes - minutes/10)
+	}
+	// first sample of the frame
+	return (frame*den*uint64(sampleRate) + num - 1) / num, nil
+}
+
+// timecodeRate returns the frame rate as a fraction and the nominal number
+// of frames per second used to label the frames.
+func timecodeRate(frameRate float64) (num, den uint64, nominal int, err error) {
+	if frameRate <= 0 || frameRate > 1000 {
+		return 0, 0, 0, fmt.Errorf("invalid frame rate: %v", frameRate)
+	}
+	nominal = int(math.Ceil(frameRate - 0.001))
+	// NTSC rates: 23.976, 29.97, 59.94...
+	if math.Abs(frameRate-float64(nominal)*1000/1001) < 0.001 && nominal%6 == 0 {
+		return uint64(nominal) * 1000, 1001, nominal, nil
+	}
+	return uint64(math.Round(frameRate * 1000)), 1000, nominal, nil
+}
+
+// isDropFrameRate reports whether drop frame timecodes are used with the
+// passed frame rate (29.97 and 59.94).
+func isDropFrameRate(num, den uint64) bool {
+	return den == 1001 && num%30000 == 0
+}
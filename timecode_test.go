@@ -0,0 +1,113 @@
+package aiff
+
+import (
+	"os"
+	"testing"
+)
+
+func TestTimecode(t *testing.T) {
+	testCases := []struct {
+		samples    uint64
+		sampleRate int
+		frameRate  float64
+		expected   string
+	}{
+		{0, 48000, 25, "00:00:00:00"},
+		{48000*3600 + 48000/25*3, 48000, 25, "01:00:00:03"},
+		{44100 * 90, 44100, 24, "00:01:30:00"},
+		// @ 29.97 the first minute holds frames 0 to 1799, the labels ;00
+		// and ;01 are dropped so frame 1800 is 00:01:00;02
+		{(1798*1001*48000 + 29999) / 30000, 48000, 29.97, "00:00:59;28"},
+		{(1799*1001*48000 + 29999) / 30000, 48000, 29.97, "00:00:59;29"},
+		{(1800*1001*48000 + 29999) / 30000, 48000, 29.97, "00:01:00;02"},
+		{(107892*1001*48000 + 29999) / 30000, 48000, 29.97, "01:00:00;00"},
+		{(17982*1001*48000 + 29999) / 30000, 48000, 29.97, "00:10:00;00"},
+		{(35964*1001*48000 + 59999) / 60000, 48000, 59.94, "00:10:00;00"},
+		{48048 * 10, 48000, 23.976, "00:00:10:00"},
+	}
+	for _, tc := range testCases {
+		timecode, err := SamplesToTimecode(tc.samples, tc.sampleRate, tc.frameRate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if timecode.String() != tc.expected {
+			t.Errorf("expected %d samples @ %v fps to be %s but got %s", tc.samples, tc.frameRate, tc.expected, timecode)
+			continue
+		}
+		samples, err := timecode.Samples(tc.sampleRate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the samples are rounded to the start of the frame
+		back, err := SamplesToTimecode(samples, tc.sampleRate, tc.frameRate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if back != timecode || samples > tc.samples {
+			t.Errorf("%s didn't survive a round trip: %d samples, %s", timecode, samples, back)
+		}
+	}
+
+	if _, err := SamplesToTimecode(0, 48000, 0); err == nil {
+		t.Fatal("expected an error using an invalid frame rate")
+	}
+	if _, err := (Timecode{Frames: 30, FrameRate: 30}).Samples(48000); err == nil {
+		t.Fatal("expected an error converting an invalid timecode")
+	}
+}
+
+func TestDecoder_StartTimecode(t *testing.T) {
+	in, err := os.Open("fixtures/kick.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	buf, err := NewDecoder(in).FullPCMBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Mkdir("testOutput", 0777)
+	out, err := os.Create("testOutput/timecode.aif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	e := NewEncoder(out, 22050, 16, 1)
+	start := Timecode{Hours: 10, Minutes: 2, Seconds: 3, Frames: 4, FrameRate: 29.97, DropFrame: true}
+	if err := e.SetStartTimecode(start); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := out.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(out)
+	if _, err := d.StartTimecode(29.97); err == nil {
+		t.Fatal("expected an error before the chunks are parsed")
+	}
+	if err := d.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	tc, err := d.StartTimecode(29.97)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tc != start {
+		t.Fatalf("expected the start timecode to be %s but got %s", start, tc)
+	}
+	tc, err = d.StartTimecode(25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tc.String() != "10:02:03:02" {
+		t.Fatalf("expected the 25 fps start timecode to be 10:02:03:02 but got %s", tc)
+	}
+}
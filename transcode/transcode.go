@@ -0,0 +1,63 @@
+// Package transcode converts AIFF files into other go-audio containers
+// without holding the entire PCM data in memory.
+package transcode
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-audio/aiff"
+	"github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+)
+
+// bufferSize is the number of frames decoded and encoded at once.
+const bufferSize = 4096
+
+// TranscodeToWAV reads the AIFF content of r and writes it as a PCM WAV file
+// to w. The sound data is streamed block by block. 8-bit samples are
+// converted to the offset binary representation used by WAV.
+func TranscodeToWAV(r io.ReadSeeker, w io.WriteSeeker) error {
+	if r == nil || w == nil {
+		return errors.New("can't transcode from or to a nil pointer")
+	}
+	d := aiff.NewDecoder(r)
+	if !d.IsValidFile() {
+		return fmt.Errorf("invalid AIFF file - %v", d.Err())
+	}
+	if err := d.FwdToPCM(); err != nil {
+		return fmt.Errorf("failed to forward to PCM - %v", err)
+	}
+	if d.PCMChunk == nil {
+		return fmt.Errorf("PCM chunk not found - %v", d.Err())
+	}
+
+	numChans := int(d.NumChans)
+	e := wav.NewEncoder(w, d.SampleRate, int(d.BitDepth), numChans, 1)
+	buf := &audio.IntBuffer{
+		Data:   make([]int, bufferSize*numChans),
+		Format: &audio.Format{NumChannels: numChans, SampleRate: d.SampleRate},
+	}
+	for {
+		n, err := d.PCMBuffer(buf)
+		if err != nil {
+			return fmt.Errorf("failed to read the PCM data - %v", err)
+		}
+		if n == 0 {
+			break
+		}
+		block := &audio.IntBuffer{Data: buf.Data[:n], Format: buf.Format, SourceBitDepth: buf.SourceBitDepth}
+		if d.BitDepth == 8 {
+			// the 8-bit AIFF samples are decoded as the unsigned byte of
+			// their two's complement value, WAV stores them as offset binary
+			for i, v := range block.Data {
+				block.Data[i] = int(int8(v)) + 128
+			}
+		}
+		if err := e.Write(block); err != nil {
+			return fmt.Errorf("failed to write the WAV data - %v", err)
+		}
+	}
+	return e.Close()
+}
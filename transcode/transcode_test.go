@@ -0,0 +1,79 @@
+package transcode
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-audio/aiff"
+	"github.com/go-audio/wav"
+)
+
+func TestTranscodeToWAV(t *testing.T) {
+	testCases := []string{
+		"../fixtures/kick.aif",
+		"../fixtures/kick8b.aiff",
+		"../fixtures/kick32b.aiff",
+		"../fixtures/zipper24b.aiff",
+		"../fixtures/sowt.aif",
+		"../fixtures/bloop.aif",
+	}
+
+	os.Mkdir("testOutput", 0777)
+	defer os.Remove("testOutput")
+	for _, path := range testCases {
+		t.Run(path, func(t *testing.T) {
+			in, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer in.Close()
+			out, err := os.Create("testOutput/transcoded.wav")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(out.Name())
+			defer out.Close()
+
+			if err := TranscodeToWAV(in, out); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := in.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			ad := aiff.NewDecoder(in)
+			expected, err := ad.FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := out.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			wd := wav.NewDecoder(out)
+			buf, err := wd.FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int(wd.SampleRate) != ad.SampleRate || wd.NumChans != ad.NumChans || wd.BitDepth != ad.BitDepth {
+				t.Fatalf("expected %d channels @ %d / %d bits but got %d channels @ %d / %d bits",
+					ad.NumChans, ad.SampleRate, ad.BitDepth, wd.NumChans, wd.SampleRate, wd.BitDepth)
+			}
+			if len(buf.Data) != len(expected.Data) {
+				t.Fatalf("expected %d samples but got %d", len(expected.Data), len(buf.Data))
+			}
+			for i, v := range expected.Data {
+				if ad.BitDepth == 8 {
+					// silence is 0 in AIFF and 128 in WAV
+					v = int(int8(v)) + 128
+				}
+				if buf.Data[i] != v {
+					t.Fatalf("sample at position %d didn't match, expected %d, got %d", i, v, buf.Data[i])
+				}
+			}
+		})
+	}
+
+	if err := TranscodeToWAV(nil, nil); err == nil {
+		t.Fatal("expected an error transcoding nil pointers")
+	}
+}
@@ -0,0 +1,289 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Severity indicates how serious a validation issue is.
+type Severity int
+
+const (
+	// SeverityInfo flags something unusual but harmless.
+	SeverityInfo Severity = iota
+	// SeverityWarning flags something other readers might choke on.
+	SeverityWarning
+	// SeverityError flags something preventing the file from being decoded.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// ValidationIssue describes a problem found while validating a file.
+type ValidationIssue struct {
+	// Check is the name of the check that reported the issue.
+	Check string
+	// Severity of the issue.
+	Severity Severity
+	// Offset is the position in the file related to the issue, -1 if none.
+	Offset int64
+	// Message is a human readable description of the issue.
+	Message string
+}
+
+func (i ValidationIssue) String() string {
+	if i.Offset < 0 {
+		return fmt.Sprintf("[%s] %s: %s", i.Severity, i.Check, i.Message)
+	}
+	return fmt.Sprintf("[%s] %s @%d: %s", i.Severity, i.Check, i.Offset, i.Message)
+}
+
+// ValidationPolicy sets the ranges of values accepted by IsValidFile and
+// Validate. A zero bound isn't enforced, the zero policy accepts any
+// positive sample rate and number of channels.
+type ValidationPolicy struct {
+	// MinSampleRate and MaxSampleRate bound the sample rate in Hz.
+	MinSampleRate float64
+	MaxSampleRate float64
+	// MaxChannels is the largest number of channels accepted.
+	MaxChannels int
+	// AllowEmptyAudio accepts files without sound data (0 frames), such as
+	// the templates saved by some DAWs. They are rejected by default.
+	AllowEmptyAudio bool
+}
+
+// allowsEmptyAudio reports whether files without sound data are accepted.
+func (p *ValidationPolicy) allowsEmptyAudio() bool {
+	return p != nil && p.AllowEmptyAudio
+}
+
+// checkSampleRate returns an error if the sample rate isn't accepted by the
+// policy, a nil policy only rejects rates that aren't positive.
+func (p *ValidationPolicy) checkSampleRate(rate float64) error {
+	if !(rate > 0) || math.IsInf(rate, 0) {
+		return fmt.Errorf("invalid sample rate: %v", rate)
+	}
+	if p == nil {
+		return nil
+	}
+	if p.MinSampleRate > 0 && rate < p.MinSampleRate {
+		return fmt.Errorf("sample rate %v below the minimum of %v", rate, p.MinSampleRate)
+	}
+	if p.MaxSampleRate > 0 && rate > p.MaxSampleRate {
+		return fmt.Errorf("sample rate %v above the maximum of %v", rate, p.MaxSampleRate)
+	}
+	return nil
+}
+
+// checkChannels returns an error if the number of channels isn't accepted
+// by the policy.
+func (p *ValidationPolicy) checkChannels(numChans int) error {
+	if numChans < 1 {
+		return fmt.Errorf("invalid number of channels: %d", numChans)
+	}
+	if p != nil && p.MaxChannels > 0 && numChans > p.MaxChannels {
+		return fmt.Errorf("%d channels above the maximum of %d", numChans, p.MaxChannels)
+	}
+	return nil
+}
+
+// Validate inspects the container and returns the list of issues found.
+// An empty list means the file is valid. Unlike IsValidFile, the reasons
+// why a file might not be readable are reported.
+// The file information is parsed if needed (see ReadInfo) but the chunks are
+// inspected without moving the underlying reader so the decoder can still be
+// used afterwards.
+func (d *Decoder) Validate() []ValidationIssue {
+	issues := []ValidationIssue{}
+	report := func(check string, severity Severity, offset int64, format string, args ...interface{}) {
+		issues = append(issues, ValidationIssue{
+			Check:    check,
+			Severity: severity,
+			Offset:   offset,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+	if d == nil || d.ra == nil {
+		report("reader", SeverityError, -1, "nil decoder or reader")
+		return issues
+	}
+
+	fileSize, err := d.fileSize()
+	if err != nil {
+		report("reader", SeverityError, -1, "failed to get the size of the file - %v", err)
+		return issues
+	}
+
+	var header [12]byte
+	if n, _ := d.ra.ReadAt(header[:], 0); n < len(header) {
+		report("header", SeverityError, 0, "file too short to contain a FORM header (%d bytes)", fileSize)
+		return issues
+	}
+	if !bytes.Equal(header[:4], formID[:]) {
+		report("header", SeverityError, 0, "expected a FORM chunk ID but got %q", header[:4])
+		return issues
+	}
+	form := [4]byte{header[8], header[9], header[10], header[11]}
+	if form != aiffID && form != aifcID {
+		report("header", SeverityError, 8, "expected an AIFF or AIFC form type but got %q", form[:])
+		return issues
+	}
+	formSize := int64(binary.BigEndian.Uint32(header[4:8]))
+	if formSize+8 != fileSize {
+		report("form-size", SeverityWarning, 4, "FORM size (%d) doesn't match the file size (%d - 8 bytes)", formSize, fileSize)
+	}
+
+	var (
+		offset   int64 = 12
+		hasComm  bool
+		hasSSND  bool
+		ssndSize int64
+	)
+	for offset+8 <= fileSize {
+		id, size, err := d.iDnSizeAt(offset)
+		if err != nil {
+			report("chunk", SeverityError, offset, "failed to read the chunk header - %v", err)
+			break
+		}
+		if !isChunkID(id) {
+			if next, ok := d.resync(offset); ok {
+				report("garbage", SeverityWarning, offset, "%d stray bytes skipped before the next chunk", next-offset)
+				offset = next
+				continue
+			}
+			report("chunk", SeverityError, offset, "invalid chunk ID %q", id[:])
+			break
+		}
+		end := offset + 8 + int64(size)
+		if end > fileSize {
+			report("chunk-size", SeverityError, offset, "%s chunk size (%d) goes past the end of the file", id[:], size)
+		}
+		if size%2 != 0 {
+			if next, err := d.idAt(end); err == nil && isChunkID(next) && end+8 <= fileSize {
+				report("chunk-size", SeverityWarning, offset, "odd sized %s chunk (%d) isn't followed by a pad byte", id[:], size)
+			} else {
+				report("chunk-size", SeverityInfo, offset, "odd sized %s chunk (%d)", id[:], size)
+				end++
+			}
+		}
+		switch id {
+		case COMMID:
+			if hasComm {
+				report("comm", SeverityWarning, offset, "multiple COMM chunks")
+			}
+			hasComm = true
+		case SSNDID:
+			if hasSSND {
+				report("ssnd", SeverityWarning, offset, "multiple SSND chunks, only the first one is decoded by default")
+				break
+			}
+			hasSSND = true
+			ssndSize = int64(size)
+		}
+		offset = end
+	}
+	if offset < fileSize && offset+8 > fileSize {
+		report("chunk", SeverityWarning, offset, "%d trailing bytes after the last chunk", fileSize-offset)
+	}
+
+	if !hasComm {
+		report("comm", SeverityError, -1, "missing COMM chunk")
+		return issues
+	}
+	d.ReadInfo()
+	if err := d.Err(); err != nil {
+		report("comm", SeverityError, -1, "failed to parse the COMM chunk - %v", err)
+		return issues
+	}
+	if d.Form == aiffID && d.commSize > 18 {
+		report("comm", SeverityInfo, -1, "the AIFF COMM chunk has %d extra bytes (ignored)", d.commSize-18)
+	}
+	if err := d.ValidationPolicy.checkChannels(int(d.NumChans)); err != nil {
+		report("channels", SeverityError, -1, "%v", err)
+	}
+	switch d.BitDepth {
+	case 8, 16, 24, 32:
+	default:
+		report("bit-depth", SeverityError, -1, "unsupported bit depth: %d", d.BitDepth)
+	}
+	if err := d.ValidationPolicy.checkSampleRate(d.ExactSampleRate()); err != nil {
+		report("sample-rate", SeverityError, -1, "%v", err)
+	}
+	if !isSupportedEncoding(d.Encoding) {
+		report("encoding", SeverityError, -1, "unsupported encoding: %q", d.Encoding[:])
+	}
+	if d.NumSampleFrames == 0 {
+		severity := SeverityWarning
+		if d.ValidationPolicy.allowsEmptyAudio() {
+			severity = SeverityInfo
+		}
+		report("frames", severity, -1, "the COMM chunk reports 0 sample frames")
+	}
+	if !hasSSND {
+		if d.NumSampleFrames > 0 {
+			report("ssnd", SeverityError, -1, "missing SSND chunk")
+		}
+	} else if _, compressed := lookupCodec(d.Encoding); !compressed {
+		// the size of compressed frames isn't known
+		if frameSize := int64(bytesPerSample(int(d.BitDepth)) * int(d.NumChans)); frameSize > 0 {
+			// an empty SSND chunk doesn't need the offset and block size fields
+			if dataSize := int64(d.NumSampleFrames) * frameSize; (dataSize > 0 || ssndSize > 0) && dataSize+8 > ssndSize {
+				report("ssnd", SeverityWarning, -1, "the SSND chunk (%d bytes) is too small to contain %d frames", ssndSize, d.NumSampleFrames)
+			}
+		}
+	}
+	if found, err := d.VerifyChecksum(); found && err != nil {
+		report("checksum", SeverityError, -1, "%v", err)
+	}
+
+	return issues
+}
+
+// fileSize returns the size of the underlying reader without moving it.
+func (d *Decoder) fileSize() (int64, error) {
+	pos, err := d.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	size, err := d.r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	_, err = d.r.Seek(pos, io.SeekStart)
+	return size, err
+}
+
+// idAt returns the 4 bytes found at the given offset.
+func (d *Decoder) idAt(offset int64) ([4]byte, error) {
+	var id [4]byte
+	n, err := d.ra.ReadAt(id[:], offset)
+	if n == len(id) {
+		err = nil
+	}
+	return id, err
+}
+
+// isChunkID reports whether the passed ID is made of printable ASCII chars
+// as required by the spec.
+func isChunkID(id [4]byte) bool {
+	for _, c := range id {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
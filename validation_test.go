@@ -0,0 +1,163 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDecoder_Validate(t *testing.T) {
+	testCases := []struct {
+		input  string
+		checks []string
+		// highest severity expected
+		severity Severity
+	}{
+		{"fixtures/kick.aif", nil, SeverityInfo},
+		{"fixtures/ring.aif", nil, SeverityInfo},
+		{"fixtures/sowt2.aif", []string{"chunk-size", "chunk-size", "chunk-size", "chunk-size"}, SeverityInfo},
+		{"fixtures/padded24b.aif", []string{"chunk-size", "chunk-size"}, SeverityWarning},
+		{"fixtures/ableton.aif", []string{"encoding"}, SeverityError},
+		// AIFF COMM chunk with an AIFC encoding and compression name
+		{"fixtures/say.aif", []string{"comm"}, SeverityInfo},
+		{"fixtures/kick.wav", []string{"header"}, SeverityError},
+		// µ-law data written by the encoder
+		{ulawTestFile(t, make([]int, 100)), nil, SeverityInfo},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			f, err := os.Open(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			d := NewDecoder(f)
+			issues := d.Validate()
+			if len(issues) != len(tc.checks) {
+				t.Fatalf("expected %d issues but got %d: %v", len(tc.checks), len(issues), issues)
+			}
+			max := SeverityInfo
+			for i, issue := range issues {
+				if issue.Check != tc.checks[i] {
+					t.Fatalf("expected issue %d to be reported by %s but got %s", i, tc.checks[i], issue)
+				}
+				if issue.Severity > max {
+					max = issue.Severity
+				}
+			}
+			if max != tc.severity {
+				t.Fatalf("expected the max severity to be %s but got %s", tc.severity, max)
+			}
+			if max < SeverityError {
+				if _, err := d.FullPCMBuffer(); err != nil {
+					t.Fatalf("expected the decoder to still be usable after validation - %v", err)
+				}
+			}
+		})
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		data, err := ioutil.ReadFile("fixtures/kick.aif")
+		if err != nil {
+			t.Fatal(err)
+		}
+		issues := NewDecoder(bytes.NewReader(data[:len(data)/2])).Validate()
+		checks := map[string]Severity{}
+		for _, issue := range issues {
+			checks[issue.Check] = issue.Severity
+		}
+		if checks["form-size"] != SeverityWarning || checks["chunk-size"] != SeverityError {
+			t.Fatalf("expected the FORM and SSND sizes to be reported, got %v", issues)
+		}
+	})
+}
+
+func TestValidationPolicy(t *testing.T) {
+	testCases := []struct {
+		input  string
+		policy *ValidationPolicy
+		valid  bool
+	}{
+		{"fixtures/sine192k.aif", nil, true},
+		{"fixtures/sine192k.aif", &ValidationPolicy{}, true},
+		{"fixtures/sine192k.aif", &ValidationPolicy{MinSampleRate: 8000, MaxSampleRate: 192000}, true},
+		{"fixtures/sine192k.aif", &ValidationPolicy{MaxSampleRate: 96000}, false},
+		{"fixtures/sine192k.aif", &ValidationPolicy{MaxChannels: 1}, false},
+		{"fixtures/sine96k24b.aif", &ValidationPolicy{MaxSampleRate: 96000, MaxChannels: 1}, true},
+		{"fixtures/kick.aif", &ValidationPolicy{MinSampleRate: 44100}, false},
+	}
+
+	for i, tc := range testCases {
+		f, err := os.Open(tc.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		d := NewDecoder(f)
+		d.ValidationPolicy = tc.policy
+		if d.IsValidFile() != tc.valid {
+			t.Fatalf("[%d] expected %s to be valid: %t", i, tc.input, tc.valid)
+		}
+		var errs int
+		for _, issue := range d.Validate() {
+			if issue.Severity == SeverityError {
+				errs++
+			}
+		}
+		if (errs == 0) != tc.valid {
+			t.Fatalf("[%d] expected %s to be valid: %t but got %d errors", i, tc.input, tc.valid, errs)
+		}
+	}
+}
+
+func TestEmptyAudio(t *testing.T) {
+	comm := make([]byte, 18)
+	binary.BigEndian.PutUint16(comm[0:], 2)
+	binary.BigEndian.PutUint16(comm[6:], 16)
+	rate := Float80FromFloat64(44100)
+	copy(comm[8:], rate[:])
+	testCases := []struct {
+		desc string
+		ssnd []byte
+	}{
+		{"empty SSND", testChunk("SSND", make([]byte, 8))},
+		{"zero size SSND", testChunk("SSND", nil)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			data := append([]byte("FORM\x00\x00\x00\x00AIFF"), testChunk("COMM", comm)...)
+			data = append(data, testChunk("NAME", nil)...)
+			data = append(data, tc.ssnd...)
+			binary.BigEndian.PutUint32(data[4:], uint32(len(data)-8))
+
+			d := NewDecoder(bytes.NewReader(data))
+			if d.IsValidFile() {
+				t.Fatal("expected a file without sound data to be rejected by default")
+			}
+			if duration, err := d.Duration(); err != nil || duration != 0 {
+				t.Fatalf("expected a 0 duration but got %v, %v", duration, err)
+			}
+			buf, err := d.FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(buf.Data) != 0 {
+				t.Fatalf("expected no samples but got %d", len(buf.Data))
+			}
+
+			d = NewDecoder(bytes.NewReader(data))
+			d.ValidationPolicy = &ValidationPolicy{AllowEmptyAudio: true}
+			if !d.IsValidFile() {
+				t.Fatalf("expected the file to be accepted - %v", d.Err())
+			}
+			for _, issue := range d.Validate() {
+				if issue.Severity > SeverityInfo {
+					t.Fatalf("unexpected issue: %s", issue)
+				}
+			}
+		})
+	}
+}